@@ -0,0 +1,127 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// isRouting reports whether the protocol routes through the cluster, which is needed for more than one router
+func (c *Config) isRouting() bool {
+	return strings.HasPrefix(c.scheme(), "neo4j")
+}
+
+// validateRouters checks Hosts and AddressResolver
+func (c *Config) validateRouters() error {
+	if len(c.Hosts) == 0 && c.AddressResolver == nil {
+		return nil
+	}
+
+	if !c.isRouting() {
+		return fmt.Errorf("hosts and address resolver require a neo4j protocol, got %s", c.scheme())
+	}
+
+	_, err := c.seedAddresses()
+	return err
+}
+
+// hostsHavePorts reports whether Hosts is the only address and every entry in it has its own port, so Port is not used
+func (c *Config) hostsHavePorts() bool {
+	if c.Host != "" || len(c.Hosts) == 0 {
+		return false
+	}
+
+	for _, host := range c.Hosts {
+		if _, _, err := net.SplitHostPort(strings.TrimSpace(host)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// seedAddresses parses Hosts, filling in Port for entries without one
+func (c *Config) seedAddresses() ([]neo4j.ServerAddress, error) {
+	seeds := make([]neo4j.ServerAddress, 0, len(c.Hosts))
+	for _, host := range c.Hosts {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			return nil, errors.New("hosts can not contain an empty host")
+		}
+
+		hostname, port, err := net.SplitHostPort(host)
+		if err != nil {
+			// no port, unless the host is malformed
+			hostname, port = strings.Trim(host, "[]"), strconv.Itoa(c.Port)
+			if strings.ContainsAny(hostname, "[]") || (strings.Contains(hostname, ":") && net.ParseIP(hostname) == nil) {
+				return nil, fmt.Errorf("invalid host `%s`, %w", host, err)
+			}
+		} else if p, err := strconv.Atoi(port); err != nil || p <= 0 {
+			return nil, fmt.Errorf("invalid port in host `%s`", host)
+		}
+
+		seeds = append(seeds, neo4j.NewServerAddress(hostname, port))
+	}
+
+	return seeds, nil
+}
+
+// addressResolver builds the resolver the driver uses to find the routers for the initial address. It returns nil when
+// there is nothing to resolve, so the driver only uses the initial address
+func (c *Config) addressResolver() (neo4j.ServerAddressResolver, error) {
+	if len(c.Hosts) == 0 && c.AddressResolver == nil {
+		return nil, nil
+	}
+
+	seeds, err := c.seedAddresses()
+	if err != nil {
+		return nil, err
+	}
+
+	resolve := c.AddressResolver
+	return func(address neo4j.ServerAddress) []neo4j.ServerAddress {
+		routers := make([]neo4j.ServerAddress, 0, len(seeds)+1)
+		seen := map[string]bool{}
+		for _, router := range append([]neo4j.ServerAddress{address}, seeds...) {
+			resolved := []neo4j.ServerAddress{router}
+			if resolve != nil {
+				resolved = resolve(router)
+			}
+
+			for _, r := range resolved {
+				if key := joinAddress(r); !seen[key] {
+					seen[key] = true
+					routers = append(routers, r)
+				}
+			}
+		}
+		return routers
+	}, nil
+}
+
+// joinAddress formats an address as host:port
+func joinAddress(address neo4j.ServerAddress) string {
+	return net.JoinHostPort(address.Hostname(), address.Port())
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func addressStrings(addresses []neo4j.ServerAddress) []string {
+	strs := make([]string, len(addresses))
+	for i, address := range addresses {
+		strs[i] = joinAddress(address)
+	}
+	return strs
+}
+
+func TestConfig_ValidateRouters(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Config *Config
+		Valid  bool
+	}{
+		{
+			Name:   "No Routers",
+			Config: &Config{Host: "localhost", Port: 7687},
+			Valid:  true,
+		},
+		{
+			Name:   "Hosts",
+			Config: &Config{Port: 7687, Protocol: "neo4j+s", Hosts: []string{"a.local", "b.local:7688", "10.0.0.1", "[::1]", "[::1]:7689"}},
+			Valid:  true,
+		},
+		{
+			Name:   "Hosts IsCluster",
+			Config: &Config{Port: 7687, IsCluster: true, Hosts: []string{"a.local"}},
+			Valid:  true,
+		},
+		{
+			Name:   "Hosts With Ports",
+			Config: &Config{Protocol: "neo4j", Hosts: []string{"a.local:7687", "[::1]:7688"}},
+			Valid:  true,
+		},
+		{
+			Name:   "Hosts Without Port",
+			Config: &Config{Protocol: "neo4j", Hosts: []string{"a.local:7687", "b.local"}},
+		},
+		{
+			Name:   "Host Without Port",
+			Config: &Config{Host: "localhost", Protocol: "neo4j", Hosts: []string{"a.local:7687"}},
+		},
+		{
+			Name:   "Hosts Bolt",
+			Config: &Config{Port: 7687, Protocol: "bolt", Hosts: []string{"a.local"}},
+		},
+		{
+			Name: "Resolver Bolt",
+			Config: &Config{Host: "localhost", Port: 7687, AddressResolver: func(address neo4j.ServerAddress) []neo4j.ServerAddress {
+				return nil
+			}},
+		},
+		{
+			Name:   "Empty Host",
+			Config: &Config{Host: "localhost", Port: 7687, Protocol: "neo4j", Hosts: []string{" "}},
+		},
+		{
+			Name:   "Invalid Port",
+			Config: &Config{Host: "localhost", Port: 7687, Protocol: "neo4j", Hosts: []string{"a.local:http"}},
+		},
+		{
+			Name:   "Malformed Host",
+			Config: &Config{Host: "localhost", Port: 7687, Protocol: "neo4j", Hosts: []string{"a:b:c"}},
+		},
+	}
+
+	for _, _case := range cases {
+		t.Run(_case.Name, func(t *testing.T) {
+			err := _case.Config.validate()
+			if _case.Valid {
+				require.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+			}
+		})
+	}
+}
+
+func TestConfig_AddressResolver(t *testing.T) {
+	req := require.New(t)
+
+	resolver, err := (&Config{Host: "localhost", Port: 7687}).addressResolver()
+	req.Nil(err)
+	req.Nil(resolver)
+
+	initial := neo4j.NewServerAddress("cluster.local", "7687")
+
+	conf := &Config{Host: "cluster.local", Port: 7687, Protocol: "neo4j", Hosts: []string{"b.local", "c.local:7688", "cluster.local"}}
+	resolver, err = conf.addressResolver()
+	req.Nil(err)
+	req.Equal([]string{"cluster.local:7687", "b.local:7687", "c.local:7688"}, addressStrings(resolver(initial)))
+
+	// the resolver is applied to every router, and duplicates are dropped
+	conf.Hosts = []string{"eu.cluster.local"}
+	conf.AddressResolver = func(address neo4j.ServerAddress) []neo4j.ServerAddress {
+		switch address.Hostname() {
+		case "cluster.local":
+			return []neo4j.ServerAddress{neo4j.NewServerAddress("us-1", "7687"), neo4j.NewServerAddress("us-2", "7687")}
+		case "eu.cluster.local":
+			return []neo4j.ServerAddress{neo4j.NewServerAddress("eu-1", "7687"), neo4j.NewServerAddress("us-1", "7687")}
+		default:
+			return nil
+		}
+	}
+	resolver, err = conf.addressResolver()
+	req.Nil(err)
+	req.Equal([]string{"us-1:7687", "us-2:7687", "eu-1:7687"}, addressStrings(resolver(initial)))
+
+	conf.Hosts = []string{"a:b:c"}
+	_, err = conf.addressResolver()
+	req.NotNil(err)
+}
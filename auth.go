@@ -0,0 +1,346 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// authExpiryMargin is how long before a token expires it is replaced
+const authExpiryMargin = 30 * time.Second
+
+// authTokenTimeout bounds how long rotating the driver waits for the AuthProvider
+const authTokenTimeout = 30 * time.Second
+
+// errDriverClosed is returned by sessions opened after the driver is closed
+var errDriverClosed = fmt.Errorf("driver is closed, %w", ErrConnection)
+
+// AuthProvider supplies the credentials gogm connects with. It is asked for a new token once the current one
+// expires or neo4j reports it as expired, so credentials can be rotated without restarting the process
+type AuthProvider interface {
+	// Token returns the token to connect with and when it expires. A zero expiry means the token does not expire
+	Token(ctx context.Context) (neo4j.AuthToken, time.Time, error)
+}
+
+// AuthProviderFunc adapts a function to an AuthProvider
+type AuthProviderFunc func(ctx context.Context) (neo4j.AuthToken, time.Time, error)
+
+// Token implements AuthProvider
+func (a AuthProviderFunc) Token(ctx context.Context) (neo4j.AuthToken, time.Time, error) {
+	return a(ctx)
+}
+
+// StaticAuth returns an AuthProvider that always returns token, for example neo4j.CustomAuth
+func StaticAuth(token neo4j.AuthToken) AuthProvider {
+	return AuthProviderFunc(func(ctx context.Context) (neo4j.AuthToken, time.Time, error) {
+		return token, time.Time{}, nil
+	})
+}
+
+// authToken returns the token configured by BearerToken, KerberosTicket or Username, Password and Realm
+func (c *Config) authToken() neo4j.AuthToken {
+	switch {
+	case c.BearerToken != "":
+		return neo4j.BearerAuth(c.BearerToken)
+	case c.KerberosTicket != "":
+		return neo4j.KerberosAuth(c.KerberosTicket)
+	default:
+		return neo4j.BasicAuth(c.Username, c.Password, c.Realm)
+	}
+}
+
+// driverFactory creates a driver that authenticates with token
+type driverFactory func(token neo4j.AuthToken) (neo4j.Driver, error)
+
+// rotatingDriver is a neo4j.Driver that replaces its underlying driver whenever the AuthProvider token expires.
+// Sessions keep the driver they were opened on, which is closed once it is replaced and all of its sessions are closed
+type rotatingDriver struct {
+	provider AuthProvider
+	factory  driverFactory
+	logger   Logger
+
+	mu      sync.Mutex
+	current *driverHandle
+	// handles are the drivers that are not closed yet, including replaced drivers with open sessions
+	handles map[*driverHandle]struct{}
+	// rotating is closed once the rotation in flight is done, nil when no rotation is running
+	rotating chan struct{}
+	closed   bool
+}
+
+// driverHandle counts the sessions open on a driver so a replaced driver is only closed once they are done
+type driverHandle struct {
+	driver  neo4j.Driver
+	expires time.Time
+	// sessions, retired and closed are guarded by the rotatingDriver mutex
+	sessions int
+	retired  bool
+	closed   bool
+}
+
+func (d *driverHandle) expired() bool {
+	return !d.expires.IsZero() && time.Now().Add(authExpiryMargin).After(d.expires)
+}
+
+func newRotatingDriver(ctx context.Context, provider AuthProvider, factory driverFactory, logger Logger) (*rotatingDriver, error) {
+	r := &rotatingDriver{
+		provider: provider,
+		factory:  factory,
+		logger:   logger,
+		handles:  map[*driverHandle]struct{}{},
+	}
+
+	handle, err := r.newHandle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.current = handle
+	r.handles[handle] = struct{}{}
+	return r, nil
+}
+
+func (r *rotatingDriver) newHandle(ctx context.Context) (*driverHandle, error) {
+	token, expires, err := r.provider.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth token, %w", err)
+	}
+
+	driver, err := r.factory(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &driverHandle{driver: driver, expires: expires}, nil
+}
+
+// acquire returns the driver to open a session on, replacing it first if its token has expired.
+// Only one caller fetches a new token at a time, the others wait for it. The token is fetched without holding
+// the mutex so a slow AuthProvider does not block sessions on other goroutines from closing.
+// If a new token can not be fetched the current driver keeps being used
+func (r *rotatingDriver) acquire() (*driverHandle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, errDriverClosed
+	}
+
+	if r.current.expired() {
+		if r.rotating != nil {
+			// another caller is fetching a token, use whichever driver it leaves behind
+			rotating := r.rotating
+			r.mu.Unlock()
+			<-rotating
+			r.mu.Lock()
+		} else {
+			r.rotate()
+		}
+
+		if r.closed {
+			return nil, errDriverClosed
+		}
+	}
+
+	r.current.sessions++
+	return r.current, nil
+}
+
+// rotate replaces the current driver with one using a new token, must be called with the mutex held.
+// The mutex is released while the token is fetched
+func (r *rotatingDriver) rotate() {
+	rotating := make(chan struct{})
+	r.rotating = rotating
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), authTokenTimeout)
+	handle, err := r.newHandle(ctx)
+	cancel()
+
+	r.mu.Lock()
+	r.rotating = nil
+	close(rotating)
+
+	switch {
+	case err != nil:
+		r.logger.Warnf("failed to rotate auth token, keeping the current driver, %v", err)
+	case r.closed:
+		// the driver was closed while fetching the token
+		r.closeHandle(handle)
+	default:
+		r.logger.Debug("auth token rotated, replacing driver")
+		r.handles[handle] = struct{}{}
+		r.retire(r.current)
+		r.current = handle
+	}
+}
+
+// release marks a session on handle as closed
+func (r *rotatingDriver) release(handle *driverHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	handle.sessions--
+	if handle.retired && handle.sessions == 0 {
+		r.closeHandle(handle)
+	}
+}
+
+// retire closes handle once it has no open sessions, must be called with the mutex held
+func (r *rotatingDriver) retire(handle *driverHandle) {
+	handle.retired = true
+	if handle.sessions == 0 {
+		r.closeHandle(handle)
+	}
+}
+
+// closeHandle closes the driver of handle if it is not closed yet, must be called with the mutex held
+func (r *rotatingDriver) closeHandle(handle *driverHandle) {
+	if err := r.closeDriver(handle); err != nil {
+		r.logger.Warnf("failed to close replaced driver, %v", err)
+	}
+}
+
+func (r *rotatingDriver) closeDriver(handle *driverHandle) error {
+	if handle.closed {
+		return nil
+	}
+
+	handle.closed = true
+	delete(r.handles, handle)
+	return handle.driver.Close()
+}
+
+// expire makes the next session use a new token, it is called when neo4j reports the token as expired
+func (r *rotatingDriver) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current.expires = time.Unix(0, 0)
+}
+
+func (r *rotatingDriver) Target() url.URL {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current.driver.Target()
+}
+
+func (r *rotatingDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	handle, err := r.acquire()
+	if err != nil {
+		return closedSession{err: err}
+	}
+
+	return &rotatingSession{
+		Session: handle.driver.NewSession(config),
+		driver:  r,
+		handle:  handle,
+	}
+}
+
+func (r *rotatingDriver) VerifyConnectivity() error {
+	handle, err := r.acquire()
+	if err != nil {
+		return err
+	}
+	defer r.release(handle)
+
+	return handle.driver.VerifyConnectivity()
+}
+
+// Close closes every driver, including replaced drivers that still have open sessions
+func (r *rotatingDriver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+
+	var closeErr error
+	for handle := range r.handles {
+		if err := r.closeDriver(handle); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
+}
+
+func (r *rotatingDriver) IsEncrypted() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current.driver.IsEncrypted()
+}
+
+// rotatingSession releases its driver when it is closed
+type rotatingSession struct {
+	neo4j.Session
+	driver *rotatingDriver
+	handle *driverHandle
+	once   sync.Once
+}
+
+func (r *rotatingSession) Close() error {
+	err := r.Session.Close()
+	r.once.Do(func() {
+		r.driver.release(r.handle)
+	})
+
+	return err
+}
+
+// closedSession is returned by NewSession once the driver is closed, every call fails with err
+type closedSession struct {
+	err error
+}
+
+func (c closedSession) LastBookmarks() neo4j.Bookmarks {
+	return nil
+}
+
+func (c closedSession) LastBookmark() string {
+	return ""
+}
+
+func (c closedSession) BeginTransaction(configurers ...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	return nil, c.err
+}
+
+func (c closedSession) ReadTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return nil, c.err
+}
+
+func (c closedSession) WriteTransaction(work neo4j.TransactionWork, configurers ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return nil, c.err
+}
+
+func (c closedSession) Run(cypher string, params map[string]interface{}, configurers ...func(*neo4j.TransactionConfig)) (neo4j.Result, error) {
+	return nil, c.err
+}
+
+func (c closedSession) Close() error {
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenDriver remembers the token it was created with and whether it was closed
+type tokenDriver struct {
+	neo4j.Driver
+	token  neo4j.AuthToken
+	closed bool
+}
+
+func (t *tokenDriver) NewSession(neo4j.SessionConfig) neo4j.Session {
+	return &closeSession{}
+}
+
+func (t *tokenDriver) Close() error {
+	t.closed = true
+	return nil
+}
+
+type closeSession struct {
+	neo4j.Session
+}
+
+func (c *closeSession) Close() error {
+	return nil
+}
+
+func TestConfig_AuthToken(t *testing.T) {
+	req := require.New(t)
+
+	conf := &Config{Username: "neo4j", Password: "password"}
+	req.Equal(neo4j.BasicAuth("neo4j", "password", ""), conf.authToken())
+
+	conf.KerberosTicket = "ticket"
+	req.Equal(neo4j.KerberosAuth("ticket"), conf.authToken())
+
+	conf.BearerToken = "token"
+	req.Equal(neo4j.BearerAuth("token"), conf.authToken())
+}
+
+func TestRotatingDriver(t *testing.T) {
+	req := require.New(t)
+
+	var drivers []*tokenDriver
+	factory := func(token neo4j.AuthToken) (neo4j.Driver, error) {
+		driver := &tokenDriver{token: token}
+		drivers = append(drivers, driver)
+		return driver, nil
+	}
+
+	// the first token is about to expire, later ones do not
+	calls := 0
+	var providerErr error
+	provider := AuthProviderFunc(func(ctx context.Context) (neo4j.AuthToken, time.Time, error) {
+		if providerErr != nil {
+			return neo4j.AuthToken{}, time.Time{}, providerErr
+		}
+
+		calls++
+		if calls == 1 {
+			return neo4j.BearerAuth("first"), time.Now().Add(time.Second), nil
+		}
+		return neo4j.BearerAuth("next"), time.Time{}, nil
+	})
+
+	driver, err := newRotatingDriver(context.Background(), provider, factory, GetDefaultLogger())
+	req.Nil(err)
+	req.Len(drivers, 1)
+
+	// the expired token is replaced before opening a session, the old driver has no sessions so it is closed
+	sess := driver.NewSession(neo4j.SessionConfig{})
+	req.Len(drivers, 2)
+	req.True(drivers[0].closed)
+	req.Equal(neo4j.BearerAuth("next"), drivers[1].token)
+
+	// tokens without an expiry are kept
+	other := driver.NewSession(neo4j.SessionConfig{})
+	req.Nil(other.Close())
+	req.Len(drivers, 2)
+
+	// neo4j reporting the token as expired replaces the driver, which stays open until its sessions are closed
+	driver.expire()
+	newSess := driver.NewSession(neo4j.SessionConfig{})
+	req.Len(drivers, 3)
+	req.False(drivers[1].closed)
+	req.Nil(sess.Close())
+	req.True(drivers[1].closed)
+	// closing twice does not release twice
+	req.Nil(sess.Close())
+
+	// failing to get a new token keeps the current driver
+	driver.expire()
+	providerErr = errors.New("token service down")
+	req.Nil(driver.NewSession(neo4j.SessionConfig{}).Close())
+	req.Len(drivers, 3)
+	req.False(drivers[2].closed)
+
+	// closing the driver closes replaced drivers that still have open sessions
+	driver.expire()
+	providerErr = nil
+	last := driver.NewSession(neo4j.SessionConfig{})
+	req.Len(drivers, 4)
+	req.False(drivers[2].closed)
+	req.Nil(driver.Close())
+	req.True(drivers[2].closed)
+	req.True(drivers[3].closed)
+	req.Nil(newSess.Close())
+	req.Nil(last.Close())
+
+	// sessions opened after closing fail instead of using a closed driver
+	closedSess := driver.NewSession(neo4j.SessionConfig{})
+	_, err = closedSess.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, nil
+	})
+	req.ErrorIs(err, ErrConnection)
+	req.Nil(closedSess.Close())
+	req.ErrorIs(driver.VerifyConnectivity(), ErrConnection)
+
+	providerErr = errors.New("token service down")
+	_, err = newRotatingDriver(context.Background(), provider, factory, GetDefaultLogger())
+	req.NotNil(err)
+}
+
+func TestRotatingDriver_SingleFlight(t *testing.T) {
+	req := require.New(t)
+
+	factory := func(token neo4j.AuthToken) (neo4j.Driver, error) {
+		return &tokenDriver{token: token}, nil
+	}
+
+	var calls int32
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	provider := AuthProviderFunc(func(ctx context.Context) (neo4j.AuthToken, time.Time, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return neo4j.BearerAuth("first"), time.Time{}, nil
+		}
+
+		// rotating is bounded
+		if _, ok := ctx.Deadline(); !ok {
+			return neo4j.AuthToken{}, time.Time{}, errors.New("no deadline")
+		}
+		close(fetching)
+		<-release
+		return neo4j.BearerAuth("next"), time.Time{}, nil
+	})
+
+	driver, err := newRotatingDriver(context.Background(), provider, factory, GetDefaultLogger())
+	req.Nil(err)
+
+	held := driver.NewSession(neo4j.SessionConfig{})
+	driver.expire()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- driver.NewSession(neo4j.SessionConfig{}).Close()
+		}()
+	}
+
+	// sessions can be closed while the token is fetched
+	<-fetching
+	req.Nil(held.Close())
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		req.Nil(err)
+	}
+
+	// every session waited for the one token fetch
+	req.Equal(int32(2), atomic.LoadInt32(&calls))
+	req.Nil(driver.Close())
+}
+
+func TestStaticAuth(t *testing.T) {
+	req := require.New(t)
+
+	token, expires, err := StaticAuth(neo4j.CustomAuth("custom", "user", "pass", "", nil)).Token(context.Background())
+	req.Nil(err)
+	req.True(expires.IsZero())
+	req.Equal(neo4j.CustomAuth("custom", "user", "pass", "", nil), token)
+}
@@ -3,8 +3,10 @@ package gogm
 import (
 	"context"
 	"path/filepath"
+	"reflect"
 	"testing"
 
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,3 +61,48 @@ func TestSessionV2Impl_LoadAll(t *testing.T) {
 	req.Nil(sess.Delete(ctx, writeTo[0]))
 	req.Nil(sess.Delete(ctx, writeTo[1]))
 }
+
+func BenchmarkDecode(b *testing.B) {
+	gogm, err := getTestGogm(&reflectNode{}, &codecNode{})
+	require.Nil(b, err)
+
+	for _, label := range []string{"reflectNode", "codecNode"} {
+		node := neo4j.Node{Id: 5, Labels: []string{label}, Props: codecProps()}
+		b.Run(label, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := convertNodeToValue(gogm, node, false, false, ptrToBool(false), nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	gogm, err := getTestGogm(&reflectNode{}, &codecNode{})
+	require.Nil(b, err)
+
+	fields := reflectNode{
+		BaseUUIDNode: BaseUUIDNode{UUID: "uuid"},
+		Name:         "name",
+		Count:        3,
+		Tags:         []string{"a", "b"},
+		Attrs:        map[string]string{"k": "v"},
+	}
+	codec := codecNode(fields)
+
+	for label, node := range map[string]interface{}{"reflectNode": &fields, "codecNode": &codec} {
+		config, err := getStructDecoratorConfig(gogm, node, gogm.mappedRelations)
+		require.Nil(b, err)
+		val := reflect.ValueOf(node)
+		b.Run(label, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := toCypherParamsMap(gogm, val, *config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
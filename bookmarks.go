@@ -0,0 +1,242 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BookmarkHeader is the http header and grpc metadata key gogm uses to pass bookmarks between services
+const BookmarkHeader = "X-Gogm-Bookmarks"
+
+// bookmarkSeparator separates bookmarks in a single header value. Bookmarks never contain commas
+const bookmarkSeparator = ","
+
+// BookmarkManager keeps track of the bookmarks of committed transactions so new sessions can be causally
+// chained to them. Sessions opened without a database name are tracked under the name of the default database of the
+// server. Implementations must be safe for concurrent use
+type BookmarkManager interface {
+	// GetBookmarks returns the bookmarks new sessions against database should start from
+	GetBookmarks(database string) []string
+	// UpdateBookmarks replaces the bookmarks a session started from with the bookmarks it ended with
+	UpdateBookmarks(database string, previous, current []string)
+	// Forget drops the bookmarks of the given databases, or of every database if none are given
+	Forget(databases ...string)
+}
+
+// NewBookmarkManager returns an in memory BookmarkManager
+func NewBookmarkManager() BookmarkManager {
+	return &bookmarkManager{
+		bookmarks: map[string]map[string]struct{}{},
+	}
+}
+
+// bookmarkManager is the default in memory BookmarkManager
+type bookmarkManager struct {
+	mu sync.RWMutex
+	// database -- set of bookmarks
+	bookmarks map[string]map[string]struct{}
+}
+
+func (b *bookmarkManager) GetBookmarks(database string) []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	set := b.bookmarks[database]
+	if len(set) == 0 {
+		return nil
+	}
+
+	bookmarks := make([]string, 0, len(set))
+	for bookmark := range set {
+		bookmarks = append(bookmarks, bookmark)
+	}
+
+	return bookmarks
+}
+
+func (b *bookmarkManager) UpdateBookmarks(database string, previous, current []string) {
+	if len(current) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	set, ok := b.bookmarks[database]
+	if !ok {
+		set = map[string]struct{}{}
+		b.bookmarks[database] = set
+	}
+
+	// the new bookmarks are causally after the ones the session started from
+	for _, bookmark := range previous {
+		delete(set, bookmark)
+	}
+
+	for _, bookmark := range current {
+		if bookmark != "" {
+			set[bookmark] = struct{}{}
+		}
+	}
+}
+
+func (b *bookmarkManager) Forget(databases ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(databases) == 0 {
+		b.bookmarks = map[string]map[string]struct{}{}
+		return
+	}
+
+	for _, database := range databases {
+		delete(b.bookmarks, database)
+	}
+}
+
+// BookmarkManager returns the bookmark manager of this instance of gogm, nil if bookmark management is disabled
+func (g *Gogm) BookmarkManager() BookmarkManager {
+	return g.config.BookmarkManager
+}
+
+// combineBookmarks merges bookmark lists and drops duplicates
+func combineBookmarks(lists ...[]string) []string {
+	var combined []string
+	seen := map[string]struct{}{}
+	for _, list := range lists {
+		for _, bookmark := range list {
+			if _, ok := seen[bookmark]; ok || bookmark == "" {
+				continue
+			}
+			seen[bookmark] = struct{}{}
+			combined = append(combined, bookmark)
+		}
+	}
+
+	return combined
+}
+
+// SetBookmarksHeader writes bookmarks to the gogm bookmark header so another service can read its own writes
+func SetBookmarksHeader(header http.Header, bookmarks []string) {
+	if header == nil {
+		return
+	}
+
+	if len(bookmarks) == 0 {
+		header.Del(BookmarkHeader)
+		return
+	}
+
+	header.Set(BookmarkHeader, strings.Join(bookmarks, bookmarkSeparator))
+}
+
+// BookmarksFromHeader reads bookmarks written by SetBookmarksHeader
+func BookmarksFromHeader(header http.Header) []string {
+	if header == nil {
+		return nil
+	}
+
+	return parseBookmarkValues(header.Values(BookmarkHeader))
+}
+
+// SetBookmarksMetadata writes bookmarks to grpc metadata. metadata.MD can be passed directly
+func SetBookmarksMetadata(md map[string][]string, bookmarks []string) {
+	if md == nil {
+		return
+	}
+
+	// grpc metadata keys are lower case
+	key := strings.ToLower(BookmarkHeader)
+	if len(bookmarks) == 0 {
+		delete(md, key)
+		return
+	}
+
+	md[key] = []string{strings.Join(bookmarks, bookmarkSeparator)}
+}
+
+// BookmarksFromMetadata reads bookmarks written by SetBookmarksMetadata. metadata.MD can be passed directly
+func BookmarksFromMetadata(md map[string][]string) []string {
+	if md == nil {
+		return nil
+	}
+
+	return parseBookmarkValues(md[strings.ToLower(BookmarkHeader)])
+}
+
+func parseBookmarkValues(values []string) []string {
+	var bookmarks []string
+	for _, value := range values {
+		for _, bookmark := range strings.Split(value, bookmarkSeparator) {
+			if bookmark = strings.TrimSpace(bookmark); bookmark != "" {
+				bookmarks = append(bookmarks, bookmark)
+			}
+		}
+	}
+
+	return combineBookmarks(bookmarks)
+}
+
+type bookmarksContextKey struct{}
+
+// ContextWithBookmarks returns a copy of ctx carrying bookmarks that sessions created by Gogm.Do must start from.
+// Sessions opened with NewSessionV2 do not read them from a context, pass them in SessionConfig.Bookmarks instead
+func ContextWithBookmarks(ctx context.Context, bookmarks []string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, bookmarksContextKey{}, bookmarks)
+}
+
+// BookmarksFromContext returns the bookmarks carried by ctx
+func BookmarksFromContext(ctx context.Context) []string {
+	if ctx == nil {
+		return nil
+	}
+
+	bookmarks, _ := ctx.Value(bookmarksContextKey{}).([]string)
+	return bookmarks
+}
+
+// LastBookmarks returns the bookmarks of the last transaction this session committed,
+// or the bookmarks it started from if it has not committed anything yet
+func (s *SessionV2Impl) LastBookmarks() []string {
+	return s.bookmarks
+}
+
+// updateBookmarks records the bookmarks of a committed transaction with the session and the bookmark manager
+func (s *SessionV2Impl) updateBookmarks() {
+	current := s.neoSess.LastBookmarks()
+	if len(current) == 0 {
+		return
+	}
+
+	if manager := s.gogm.BookmarkManager(); manager != nil {
+		manager.UpdateBookmarks(s.gogm.sessionDatabase(s.conf.DatabaseName), s.bookmarks, current)
+	}
+
+	s.lastBookmark = s.neoSess.LastBookmark()
+	s.bookmarks = current
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookmarkManager(t *testing.T) {
+	req := require.New(t)
+
+	manager := NewBookmarkManager()
+	req.Empty(manager.GetBookmarks("neo4j"))
+
+	manager.UpdateBookmarks("neo4j", nil, []string{"bm1"})
+	manager.UpdateBookmarks("other", nil, []string{"bm2"})
+	req.Equal([]string{"bm1"}, manager.GetBookmarks("neo4j"))
+
+	// newer bookmarks replace the ones the session started from
+	manager.UpdateBookmarks("neo4j", []string{"bm1"}, []string{"bm3"})
+	req.Equal([]string{"bm3"}, manager.GetBookmarks("neo4j"))
+
+	manager.Forget("neo4j")
+	req.Empty(manager.GetBookmarks("neo4j"))
+	req.Equal([]string{"bm2"}, manager.GetBookmarks("other"))
+
+	manager.Forget()
+	req.Empty(manager.GetBookmarks("other"))
+}
+
+func TestBookmarkPropagation(t *testing.T) {
+	req := require.New(t)
+
+	header := http.Header{}
+	SetBookmarksHeader(header, []string{"bm1", "bm2"})
+	req.Equal([]string{"bm1", "bm2"}, BookmarksFromHeader(header))
+	SetBookmarksHeader(header, nil)
+	req.Empty(BookmarksFromHeader(header))
+
+	md := map[string][]string{}
+	SetBookmarksMetadata(md, []string{"bm1", "bm1", "bm3"})
+	req.Equal([]string{"bm1", "bm3"}, BookmarksFromMetadata(md))
+
+	ctx := ContextWithBookmarks(context.Background(), []string{"bm4"})
+	req.Equal([]string{"bm4"}, BookmarksFromContext(ctx))
+	req.Empty(BookmarksFromContext(context.Background()))
+}
+
+func TestSessionV2Impl_updateBookmarks(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	gogm.config.BookmarkManager = NewBookmarkManager()
+	gogm.config.BookmarkManager.UpdateBookmarks("", nil, []string{"start"})
+
+	neoSess := &fakeNeoSession{}
+	sess := &SessionV2Impl{gogm: gogm, neoSess: neoSess, bookmarks: []string{"start"}}
+
+	// nothing committed yet
+	sess.updateBookmarks()
+	req.Equal([]string{"start"}, sess.LastBookmarks())
+
+	neoSess.lastBookmarks = []string{"committed"}
+	sess.updateBookmarks()
+	req.Equal([]string{"committed"}, sess.LastBookmarks())
+	req.Equal("committed", sess.lastBookmark)
+	req.Equal([]string{"committed"}, gogm.BookmarkManager().GetBookmarks(""))
+
+	// sessions report their bookmarks through the optional interface
+	var session SessionV2 = sess
+	bookmarked, ok := session.(BookmarkedSession)
+	req.True(ok)
+	req.Equal([]string{"committed"}, bookmarked.LastBookmarks())
+
+	// sessions without a database name share the bookmarks of the default database
+	gogm.defaultDatabase = "neo4j"
+	sess = &SessionV2Impl{gogm: gogm, neoSess: &fakeNeoSession{lastBookmarks: []string{"default"}}}
+	sess.updateBookmarks()
+	req.Equal([]string{"default"}, gogm.BookmarkManager().GetBookmarks("neo4j"))
+
+	gogm.driver = &databaseDriver{}
+	named, err := newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeRead, DatabaseName: "neo4j"})
+	req.Nil(err)
+	req.Equal([]string{"default"}, named.bookmarks)
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// codecFile is the file GenerateCodecs writes to
+const codecFile = "codecs.go"
+
+// rawTypes maps the go types codecs can convert to the type the neo4j driver returns for them
+var rawTypes = map[string]string{
+	"string":    "string",
+	"bool":      "bool",
+	"int":       "int64",
+	"int8":      "int64",
+	"int16":     "int64",
+	"int32":     "int64",
+	"int64":     "int64",
+	"uint":      "int64",
+	"uint8":     "int64",
+	"uint16":    "int64",
+	"uint32":    "int64",
+	"uint64":    "int64",
+	"float32":   "float64",
+	"float64":   "float64",
+	"time.Time": "time.Time",
+}
+
+// GenerateCodecs writes GogmEncode and GogmDecode methods for the gogm structs in directory to codecs.go, so gogm
+// can save and load them without reflection. Structs with a field the generator can not convert, such as a type
+// alias or an embedded struct other than the gogm base nodes, are skipped and keep using reflection
+// note: GenerateCodecs is not recursive, it only looks in the target directory
+func GenerateCodecs(directory string, debug bool, generator string) error {
+	var structs []*codecStruct
+	packageName := ""
+
+	err := filepath.Walk(directory, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() && filePath != directory {
+			return filepath.SkipDir
+		}
+
+		if path.Ext(filePath) != ".go" || strings.HasSuffix(filePath, "_test.go") || filepath.Base(filePath) == codecFile {
+			return nil
+		}
+
+		if debug {
+			log.Printf("parsing go file [%s] for codecs\n", filePath)
+		}
+
+		found, err := parseCodecFile(filePath, &packageName, debug)
+		if err != nil {
+			return fmt.Errorf("failed to parse [%s], %w", filePath, err)
+		}
+
+		structs = append(structs, found...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(structs) == 0 {
+		log.Printf("no codecs to write, exiting")
+		return nil
+	}
+
+	sort.Slice(structs, func(i, j int) bool {
+		return structs[i].Name < structs[j].Name
+	})
+
+	tpl, err := template.New("codecFile").Parse(codecTpl)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	err = tpl.Execute(buf, codecTemplateConfig{
+		Generator:   generator,
+		PackageName: packageName,
+		Imports:     codecImports(structs),
+		Structs:     structs,
+	})
+	if err != nil {
+		return err
+	}
+
+	// format generated code
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path.Join(directory, codecFile), formatted, 0644)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("wrote codecs to file [%s/%s]", directory, codecFile)
+
+	return nil
+}
+
+// codecImports returns the packages the generated codecs use
+func codecImports(structs []*codecStruct) []string {
+	var usesFmt, usesStrings, usesTime bool
+	for _, str := range structs {
+		for _, field := range str.Fields {
+			usesFmt = true
+			if field.Kind == "map" {
+				usesStrings = true
+			}
+			if field.Type == "time.Time" {
+				usesTime = true
+			}
+		}
+	}
+
+	var imports []string
+	if usesFmt {
+		imports = append(imports, "fmt")
+	}
+	if usesStrings {
+		imports = append(imports, "strings")
+	}
+	if usesTime {
+		imports = append(imports, "time")
+	}
+
+	return imports
+}
+
+// parseCodecFile finds the gogm structs in a file that codecs can be generated for
+func parseCodecFile(filePath string, packageName *string, debug bool) ([]*codecStruct, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	*packageName = file.Name.Name
+
+	var structs []*codecStruct
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			tSpec, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+
+			strType, ok := tSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			str, err := parseCodecStruct(tSpec.Name.Name, strType, fset)
+			if err != nil {
+				if debug {
+					log.Printf("skipping codec for [%s], %s", tSpec.Name.Name, err.Error())
+				}
+				continue
+			}
+
+			if str != nil {
+				structs = append(structs, str)
+			}
+		}
+	}
+
+	return structs, nil
+}
+
+// parseCodecStruct returns the codec configuration of a struct, nil if it is not a gogm struct and an error
+// if it has a field codecs can not convert
+func parseCodecStruct(name string, strType *ast.StructType, fset *token.FileSet) (*codecStruct, error) {
+	str := &codecStruct{Name: name}
+	isGogm := false
+
+	for _, field := range strType.Fields.List {
+		var typeNameBuf bytes.Buffer
+		if err := printer.Fprint(&typeNameBuf, fset, field.Type); err != nil {
+			return nil, err
+		}
+		typeName := typeNameBuf.String()
+
+		if len(field.Names) == 0 {
+			// only the base nodes may be embedded, their primary key and graph id are handled by gogm
+			if strings.HasSuffix(typeName, ".BaseNode") || strings.HasSuffix(typeName, ".BaseUUIDNode") {
+				isGogm = true
+				continue
+			}
+			return nil, fmt.Errorf("embedded field %s is not supported", typeName)
+		}
+
+		if field.Tag == nil {
+			continue
+		}
+
+		tag, ok := reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Lookup("gogm")
+		if !ok {
+			continue
+		}
+		isGogm = true
+
+		if tag == "-" {
+			continue
+		}
+
+		propName := ""
+		properties, skip := false, false
+		for _, part := range strings.Split(tag, ";") {
+			switch {
+			case strings.HasPrefix(part, "name="):
+				propName = strings.TrimPrefix(part, "name=")
+			case part == "properties":
+				properties = true
+			case strings.HasPrefix(part, "relationship="), strings.HasPrefix(part, "pk="):
+				skip = true
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		for _, fieldName := range field.Names {
+			codecField := &codecField{
+				Field: fieldName.Name,
+				Name:  propName,
+			}
+			if codecField.Name == "" {
+				codecField.Name = fieldName.Name
+			}
+
+			if err := codecField.setType(typeName, properties); err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldName.Name, err)
+			}
+
+			str.Fields = append(str.Fields, codecField)
+		}
+	}
+
+	if !isGogm {
+		return nil, nil
+	}
+
+	return str, nil
+}
+
+// setType sets the kind and types of the field from the go type of the struct field
+func (c *codecField) setType(typeName string, properties bool) error {
+	c.Kind, c.Type = "scalar", typeName
+	if properties {
+		switch {
+		case strings.HasPrefix(typeName, "[]"):
+			c.Kind, c.Type = "slice", strings.TrimPrefix(typeName, "[]")
+		case strings.HasPrefix(typeName, "map[string]"):
+			c.Kind, c.Type = "map", strings.TrimPrefix(typeName, "map[string]")
+		default:
+			return fmt.Errorf("properties type %s is not supported", typeName)
+		}
+	}
+
+	if c.Kind == "map" && c.Type == "interface{}" {
+		c.Raw = c.Type
+		return nil
+	}
+
+	raw, ok := rawTypes[c.Type]
+	if !ok || (c.Kind == "slice" && c.Type == "time.Time") {
+		return fmt.Errorf("type %s is not supported", typeName)
+	}
+
+	c.Raw = raw
+	return nil
+}
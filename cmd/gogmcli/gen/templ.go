@@ -300,3 +300,95 @@ type tplRelConf struct {
 	// StructName = Start if true
 	SpecialEdgeDirection bool
 }
+
+//expect .Generator .PackageName .Imports .Structs, parsed with text/template since the output is go code
+var codecTpl = `{{ define "codecValue" }}{{ if eq .Type .Raw }}v{{ else }}{{ .Type }}(v){{ end }}{{ end }}// Code generated by {{ .Generator }}. DO NOT EDIT
+package {{ .PackageName }}
+{{ if .Imports }}
+import (
+{{ range .Imports }}	"{{ . }}"
+{{ end }})
+{{ end }}{{ range .Structs }}{{ $struct := .Name }}
+// GogmEncode returns the properties of {{ $struct }} without reflection, see gogm.NodeEncoder
+func (n *{{ $struct }}) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{ {{ range .Fields }}{{ if ne .Kind "map" }}
+		"{{ .Name }}": n.{{ .Field }},{{ end }}{{ end }}
+	}
+{{ range .Fields }}{{ if eq .Kind "map" }}
+	for key, v := range n.{{ .Field }} {
+		props["{{ .Name }}."+key] = v
+	}
+{{ end }}{{ end }}
+	return props
+}
+
+// GogmDecode reads the properties of {{ $struct }} without reflection, see gogm.NodeDecoder
+func (n *{{ $struct }}) GogmDecode(props map[string]interface{}) error { {{ range .Fields }}{{ if eq .Kind "scalar" }}
+	if raw, ok := props["{{ .Name }}"]; ok && raw != nil {
+		v, ok := raw.({{ .Raw }})
+		if !ok {
+			return fmt.Errorf("property {{ .Name }} of {{ $struct }} holds %T, not {{ .Raw }}", raw)
+		}
+		n.{{ .Field }} = {{ template "codecValue" . }}
+	}
+{{ else if eq .Kind "slice" }}
+	if raw, ok := props["{{ .Name }}"]; ok && raw != nil {
+		switch list := raw.(type) {
+		case []{{ .Type }}:
+			n.{{ .Field }} = list
+		case []interface{}:
+			n.{{ .Field }} = make([]{{ .Type }}, len(list))
+			for i, elem := range list {
+				v, ok := elem.({{ .Raw }})
+				if !ok {
+					return fmt.Errorf("property {{ .Name }} of {{ $struct }} holds %T, not {{ .Raw }}", elem)
+				}
+				n.{{ .Field }}[i] = {{ template "codecValue" . }}
+			}
+		default:
+			return fmt.Errorf("property {{ .Name }} of {{ $struct }} holds %T, not a list", raw)
+		}
+	}
+{{ else }}
+	n.{{ .Field }} = map[string]{{ .Type }}{}
+	for key, raw := range props {
+		if !strings.HasPrefix(key, "{{ .Name }}.") {
+			continue
+		}
+{{ if eq .Raw "interface{}" }}
+		n.{{ .Field }}[strings.TrimPrefix(key, "{{ .Name }}.")] = raw{{ else }}
+		v, ok := raw.({{ .Raw }})
+		if !ok {
+			return fmt.Errorf("property %s of {{ $struct }} holds %T, not {{ .Raw }}", key, raw)
+		}
+		n.{{ .Field }}[strings.TrimPrefix(key, "{{ .Name }}.")] = {{ template "codecValue" . }}{{ end }}
+	}
+{{ end }}{{ end }}
+	return nil
+}
+{{ end }}`
+
+type codecTemplateConfig struct {
+	Generator   string
+	PackageName string
+	Imports     []string
+	Structs     []*codecStruct
+}
+
+type codecStruct struct {
+	Name   string
+	Fields []*codecField
+}
+
+type codecField struct {
+	// Field is the name of the struct field
+	Field string
+	// Name is the name of the property
+	Name string
+	// Kind is scalar, slice or map
+	Kind string
+	// Type is the go type of the value, or of the elements of a slice or map
+	Type string
+	// Raw is the type the neo4j driver returns for Type
+	Raw string
+}
@@ -49,6 +49,12 @@ func main() {
 				},
 				ArgsUsage: "directory to search and write to",
 				Usage:     "to generate link and unlink functions for nodes",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "codecs",
+						Usage: "also generate GogmEncode and GogmDecode methods so nodes are saved and loaded without reflection",
+					},
+				},
 				Action: func(c *cli.Context) error {
 					directory := c.Args().Get(0)
 
@@ -60,7 +66,13 @@ func main() {
 						log.Printf("generating link and unlink from directory [%s]", directory)
 					}
 
-					return gen.Generate(directory, debug, fmt.Sprintf("GoGM %s", c.App.Version))
+					generator := fmt.Sprintf("GoGM %s", c.App.Version)
+					err := gen.Generate(directory, debug, generator)
+					if err != nil || !c.Bool("codecs") {
+						return err
+					}
+
+					return gen.GenerateCodecs(directory, debug, generator)
 				},
 			},
 		},
@@ -0,0 +1,34 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+// NodeEncoder is implemented by mapped types that write their own properties, so saving them skips the reflection
+// over their fields. GogmEncode returns a new map with every property of the node except its graph id and primary
+// key, which gogm sets itself. Map properties are flattened the same way gogm does, as name.key entries.
+// gogmcli generate --codecs writes GogmEncode for the types it can handle
+type NodeEncoder interface {
+	GogmEncode() map[string]interface{}
+}
+
+// NodeDecoder is implemented by mapped types that read their own properties, so loading them skips the reflection
+// over their fields. GogmDecode is called on a new node with the properties neo4j returned for it, the graph id and
+// primary key are set by gogm. gogmcli generate --codecs writes GogmDecode for the types it can handle
+type NodeDecoder interface {
+	GogmDecode(props map[string]interface{}) error
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// reflectNode is saved and loaded with reflection
+type reflectNode struct {
+	BaseUUIDNode
+	Name  string            `gogm:"name=name"`
+	Count int               `gogm:"name=count"`
+	Tags  []string          `gogm:"name=tags;properties"`
+	Attrs map[string]string `gogm:"name=attrs;properties"`
+}
+
+// codecNode has the same fields as reflectNode and codecs like the ones gogmcli generates
+type codecNode struct {
+	BaseUUIDNode
+	Name  string            `gogm:"name=name"`
+	Count int               `gogm:"name=count"`
+	Tags  []string          `gogm:"name=tags;properties"`
+	Attrs map[string]string `gogm:"name=attrs;properties"`
+}
+
+func (n *codecNode) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{
+		"name":  n.Name,
+		"count": n.Count,
+		"tags":  n.Tags,
+	}
+
+	for key, v := range n.Attrs {
+		props["attrs."+key] = v
+	}
+
+	return props
+}
+
+func (n *codecNode) GogmDecode(props map[string]interface{}) error {
+	if raw, ok := props["name"]; ok && raw != nil {
+		v, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("property name of codecNode holds %T, not string", raw)
+		}
+		n.Name = v
+	}
+
+	if raw, ok := props["count"]; ok && raw != nil {
+		v, ok := raw.(int64)
+		if !ok {
+			return fmt.Errorf("property count of codecNode holds %T, not int64", raw)
+		}
+		n.Count = int(v)
+	}
+
+	if raw, ok := props["tags"]; ok && raw != nil {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return fmt.Errorf("property tags of codecNode holds %T, not a list", raw)
+		}
+		n.Tags = make([]string, len(list))
+		for i, elem := range list {
+			v, ok := elem.(string)
+			if !ok {
+				return fmt.Errorf("property tags of codecNode holds %T, not string", elem)
+			}
+			n.Tags[i] = v
+		}
+	}
+
+	n.Attrs = map[string]string{}
+	for key, raw := range props {
+		if !strings.HasPrefix(key, "attrs.") {
+			continue
+		}
+
+		v, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("property %s of codecNode holds %T, not string", key, raw)
+		}
+		n.Attrs[strings.TrimPrefix(key, "attrs.")] = v
+	}
+
+	return nil
+}
+
+func codecProps() map[string]interface{} {
+	return map[string]interface{}{
+		"uuid":    "uuid",
+		"name":    "name",
+		"count":   int64(3),
+		"tags":    []interface{}{"a", "b"},
+		"attrs.k": "v",
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogm(&reflectNode{}, &codecNode{})
+	req.Nil(err)
+
+	node := &codecNode{
+		BaseUUIDNode: BaseUUIDNode{UUID: "uuid"},
+		Name:         "name",
+		Count:        3,
+		Tags:         []string{"a", "b"},
+		Attrs:        map[string]string{"k": "v"},
+	}
+
+	config, err := getStructDecoratorConfig(gogm, node, gogm.mappedRelations)
+	req.Nil(err)
+
+	// the primary key is added to what the codec writes
+	params, err := toCypherParamsMap(gogm, reflect.ValueOf(node), *config)
+	req.Nil(err)
+	req.Equal(map[string]interface{}{
+		"uuid":    "uuid",
+		"name":    "name",
+		"count":   3,
+		"tags":    []string{"a", "b"},
+		"attrs.k": "v",
+	}, params)
+
+	// and read back from the props
+	val, err := convertNodeToValue(gogm, neo4j.Node{Id: 5, Labels: []string{"codecNode"}, Props: codecProps()}, false, false, ptrToBool(false), nil)
+	req.Nil(err)
+	node.Id = int64Ptr(5)
+	req.Equal(*node, val.Interface())
+
+	// both paths read the same node
+	val, err = convertNodeToValue(gogm, neo4j.Node{Id: 5, Labels: []string{"reflectNode"}, Props: codecProps()}, false, false, ptrToBool(false), nil)
+	req.Nil(err)
+	req.Equal(reflectNode(*node), val.Interface())
+
+	// codec errors are returned
+	props := codecProps()
+	props["count"] = "3"
+	_, err = convertNodeToValue(gogm, neo4j.Node{Id: 5, Labels: []string{"codecNode"}, Props: props}, false, false, ptrToBool(false), nil)
+	req.NotNil(err)
+	req.Contains(err.Error(), "property count of codecNode")
+}
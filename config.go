@@ -20,10 +20,17 @@
 package gogm
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 const (
@@ -37,6 +44,14 @@ type Config struct {
 	// Port is the neo4j port
 	Port int `yaml:"port" json:"port" mapstructure:"port"`
 
+	// Hosts are more routers gogm can reach the cluster through when Host is down, written as host or host:port.
+	// Entries without a port use Port. When Host is empty the first entry is used in its place. Requires a neo4j protocol
+	Hosts []string `yaml:"hosts" json:"hosts" mapstructure:"hosts"`
+
+	// AddressResolver resolves each router address, Host and every entry of Hosts, into the addresses to connect to.
+	// This lets one logical cluster name stand for several servers. Requires a neo4j protocol
+	AddressResolver neo4j.ServerAddressResolver `yaml:"-" json:"-" mapstructure:"-"`
+
 	// deprecated in favor of Protocol
 	// IsCluster specifies whether GoGM is connecting to a casual cluster or not, will determine whether to use bolt or neo4j protocols
 	IsCluster bool `yaml:"is_cluster" json:"is_cluster" mapstructure:"is_cluster"`
@@ -51,26 +66,75 @@ type Config struct {
 	// Password is the GoGM password
 	Password string `yaml:"password" json:"password" mapstructure:"password"`
 
+	// PasswordFile is read into Password when Password is empty, so the password can be mounted as a secret
+	PasswordFile string `yaml:"password_file" json:"password_file" mapstructure:"password_file"`
+
 	// PoolSize is the size of the connection pool for GoGM
 	PoolSize int `yaml:"pool_size" json:"pool_size" mapstructure:"pool_size"`
 
+	// MaxConnectionLifetime closes pooled connections older than this. Zero uses the driver default of one hour
+	MaxConnectionLifetime time.Duration `yaml:"max_connection_lifetime" json:"max_connection_lifetime" mapstructure:"max_connection_lifetime"`
+
+	// ConnectionAcquisitionTimeout bounds the wait for a connection from the pool. Zero uses the driver default of one minute
+	ConnectionAcquisitionTimeout time.Duration `yaml:"connection_acquisition_timeout" json:"connection_acquisition_timeout" mapstructure:"connection_acquisition_timeout"`
+
+	// SocketConnectTimeout bounds opening a new connection. Zero uses the driver default of five seconds
+	SocketConnectTimeout time.Duration `yaml:"socket_connect_timeout" json:"socket_connect_timeout" mapstructure:"socket_connect_timeout"`
+
+	// DisableSocketKeepalive turns off tcp keepalive on connections to neo4j
+	DisableSocketKeepalive bool `yaml:"disable_socket_keepalive" json:"disable_socket_keepalive" mapstructure:"disable_socket_keepalive"`
+
+	// MaxTransactionRetryTime bounds how long the driver retries managed transactions. Zero uses the driver default of 30 seconds
+	MaxTransactionRetryTime time.Duration `yaml:"max_transaction_retry_time" json:"max_transaction_retry_time" mapstructure:"max_transaction_retry_time"`
+
+	// FetchSize is the number of records pulled from neo4j at a time. Zero uses the driver default and -1 pulls everything at once
+	FetchSize int `yaml:"fetch_size" json:"fetch_size" mapstructure:"fetch_size"`
+
+	// UserAgent identifies the application to neo4j. Defaults to the driver user agent
+	UserAgent string `yaml:"user_agent" json:"user_agent" mapstructure:"user_agent"`
+
 	// DefaultTransactionTimeout defines the default time a transaction will wait before timing out
 	DefaultTransactionTimeout time.Duration `json:"default_transaction_timeout" yaml:"default_transaction_timeout" mapstructure:"default_transaction_timeout"`
 
 	// Realm defines the realm passed into neo4j
 	Realm string `yaml:"realm" json:"realm" mapstructure:"realm"`
 
-	// deprecated: in favor of TLSConfig
-	//these security configurations will be ignored if the protocol does not contain +s
-	UseSystemCertPool bool `yaml:"use_system_cert_pool" mapstructure:"use_system_cert_pool"`
+	// BearerToken authenticates with a bearer token, such as one from an SSO provider, instead of the username and password
+	BearerToken string `yaml:"bearer_token" json:"bearer_token" mapstructure:"bearer_token"`
+
+	// KerberosTicket authenticates with a base64 encoded kerberos ticket instead of the username and password
+	KerberosTicket string `yaml:"kerberos_ticket" json:"kerberos_ticket" mapstructure:"kerberos_ticket"`
+
+	// BearerTokenFile and KerberosTicketFile are read into BearerToken and KerberosTicket when those are empty
+	BearerTokenFile    string `yaml:"bearer_token_file" json:"bearer_token_file" mapstructure:"bearer_token_file"`
+	KerberosTicketFile string `yaml:"kerberos_ticket_file" json:"kerberos_ticket_file" mapstructure:"kerberos_ticket_file"`
+
+	// AuthProvider supplies credentials that can change while gogm is running, such as short lived tokens.
+	// It takes precedence over every other auth option, see AuthProvider and StaticAuth
+	AuthProvider AuthProvider `yaml:"-" json:"-" mapstructure:"-"`
 
-	// deprecated: in favor of TLSConfig
-	// CAFileLocation defines the location of the CA file for authenticating with tls
-	CAFileLocation string `yaml:"ca_file_location" mapstructure:"ca_file_location"`
+	// UseSystemCertPool adds the CA file to the system cert pool instead of a new one.
+	// These security configurations will be ignored if the protocol does not contain +s
+	UseSystemCertPool bool `yaml:"use_system_cert_pool" json:"use_system_cert_pool" mapstructure:"use_system_cert_pool"`
 
-	// TLSConfig defines the configuration for connecting to a neo4j cluster over tls
+	// CAFileLocation defines the location of the CA file for authenticating with tls. It sets TLSConfig.RootCAs
+	CAFileLocation string `yaml:"ca_file_location" json:"ca_file_location" mapstructure:"ca_file_location"`
+
+	// TLSConfig defines the configuration for connecting to a neo4j cluster over tls.
+	// CAFileLocation, ClientCertFile and ClientKeyFile are loaded into it
 	TLSConfig *tls.Config `yaml:"tls_config" mapstructure:"tls_config"`
 
+	// tlsFilesLoaded is set once the tls files have been loaded into TLSConfig
+	tlsFilesLoaded bool
+
+	// ClientCertFile and ClientKeyFile are the PEM encoded certificate and key gogm presents to neo4j for mutual tls
+	ClientCertFile string `yaml:"client_cert_file" json:"client_cert_file" mapstructure:"client_cert_file"`
+	ClientKeyFile  string `yaml:"client_key_file" json:"client_key_file" mapstructure:"client_key_file"`
+
+	// InsecureSkipVerify accepts any server certificate, the same as connecting with the bolt+ssc or neo4j+ssc protocols.
+	// The driver derives this from the protocol, so setting TLSConfig.InsecureSkipVerify has the same effect
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify" json:"insecure_skip_verify" mapstructure:"insecure_skip_verify"`
+
 	// Index Strategy defines the index strategy for GoGM
 	// Options for index strategy are:
 	// IGNORE_INDEX - which does no index/constraint operations
@@ -78,37 +142,130 @@ type Config struct {
 	// ASSERT_INDEX - which deletes existing indexes/constraints for the given nodes then creates them
 	IndexStrategy IndexStrategy `yaml:"index_strategy" json:"index_strategy" mapstructure:"index_strategy"`
 
-	// TargetDbs tells gogm which databases to expect and is also what index operations use to know which dbs to execute against
+	// TargetDbs tells gogm which databases to expect and is also what index operations use to know which dbs to execute against.
+	// Databases mapped types live in are added to it for index operations, see DatabaseNamer
 	TargetDbs []string `yaml:"target_dbs" json:"target_dbs" mapstructure:"target_dbs"`
 
 	// Logger specifies log interfaces that gogm will use to log
 	Logger Logger `yaml:"-" json:"-" mapstructure:"-"`
 
-	// LogLevel defines the log level that the logger will use
-	// if logger is not nil log level will be ignored
+	// LogLevel defines the log level that the default logger will use, one of DEBUG, INFO, WARN, ERROR or FATAL.
+	// Defaults to DEBUG. If logger is not nil log level will be ignored
 	LogLevel string `json:"log_level" yaml:"log_level" mapstructure:"log_level"`
 
 	// EnableDriverLogs tells the gogm whether to log logs coming out of the neo4j go driver
 	EnableDriverLogs bool `json:"enable_driver_logs" yaml:"enable_driver_logs" mapstructure:"enable_driver_logs"`
 
+	// deprecated: in favor of ParamRedactor
 	// EnableLogParams tells gogm whether to log params going into queries when on debug/trace log level
 	// WARNING THIS IS A SECURITY RISK -- ONLY ENABLE THIS FOR DEBUG
 	EnableLogParams bool `json:"enable_log_properties" yaml:"enable_log_properties" mapstructure:"enable_log_properties"`
 
+	// ParamRedactor decides how query params appear in debug logs and traces.
+	// Defaults to RedactNone when EnableLogParams is set and RedactAll otherwise
+	ParamRedactor ParamRedactor `yaml:"-" json:"-" mapstructure:"-"`
+
+	// deprecated: in favor of Tracer
 	// OpentracingEnabled tells gogm whether to use open tracing
 	OpentracingEnabled bool `json:"opentracing_enabled" yaml:"opentracing_enabled" mapstructure:"opentracing_enabled"`
 
+	// Tracer receives a span for every gogm operation. When nil and OpentracingEnabled is set, the global opentracing tracer is used
+	Tracer Tracer `yaml:"-" json:"-" mapstructure:"-"`
+
+	// Metrics receives query latency, rows returned, transaction retries and session usage
+	Metrics MetricsRecorder `yaml:"-" json:"-" mapstructure:"-"`
+
 	// LoadStrategy tells gogm how to generate load queries
 	// The options are:
 	// PATH_LOAD_STRATEGY - this generates queries based on path `match p=...`. The queries are less verbose than schema but generally slower
 	// SCHEMA_LOAD_STRATEGY - this generates queries based on the gogm schema. The queries are a lot more verbose but will generally execute faster
+	// SUBQUERY_LOAD_STRATEGY - this generates a CALL subquery per relationship from the gogm schema and paginates the root nodes before
+	// expanding them. It avoids the row blowup of path queries on wide graphs and requires neo4j 4 or newer
 	LoadStrategy LoadStrategy `json:"load_strategy" yaml:"load_strategy" mapstructure:"load_strategy"`
+
+	// RetryPolicy controls how managed transactions are retried. It can be overridden per call with WithRetryPolicy.
+	// When nil, the driver's default retries are used. It only applies to ManagedTransaction, the implicit
+	// transactions of Load, Save, Delete, Query and the other session methods always use the driver's retries
+	RetryPolicy *RetryPolicy `json:"retry_policy" yaml:"retry_policy" mapstructure:"retry_policy"`
+
+	// StartupRetry retries creating the driver, checking connectivity, detecting the server version and initializing
+	// indices until neo4j is ready. Waits never go past the deadline of the context passed to NewContext.
+	// When nil, startup fails on the first error. See DefaultStartupRetryPolicy
+	StartupRetry *RetryPolicy `json:"startup_retry" yaml:"startup_retry" mapstructure:"startup_retry"`
+
+	// LazyConnect makes New succeed without reaching out to neo4j. The connection is made when the instance is first
+	// used, and tried again on the next use if it fails
+	LazyConnect bool `json:"lazy_connect" yaml:"lazy_connect" mapstructure:"lazy_connect"`
+
+	// EnableBookmarkManager tells gogm to chain every session to the transactions committed before it,
+	// so reads always see earlier writes even when they are served by another cluster member
+	EnableBookmarkManager bool `json:"enable_bookmark_manager" yaml:"enable_bookmark_manager" mapstructure:"enable_bookmark_manager"`
+
+	// BookmarkManager overrides the in memory bookmark manager used when EnableBookmarkManager is set
+	BookmarkManager BookmarkManager `yaml:"-" json:"-" mapstructure:"-"`
+
+	// SlowQueryThreshold makes gogm log statements that take at least this long at warn level. Zero disables the slow query log
+	SlowQueryThreshold time.Duration `json:"slow_query_threshold" yaml:"slow_query_threshold" mapstructure:"slow_query_threshold"`
+
+	// QueryObserver receives every statement gogm runs with its duration, row count and server timings
+	QueryObserver QueryObserver `yaml:"-" json:"-" mapstructure:"-"`
+
+	// EnableQueryStats tells gogm to aggregate the statements it runs, see Gogm.QueryStats
+	EnableQueryStats bool `json:"enable_query_stats" yaml:"enable_query_stats" mapstructure:"enable_query_stats"`
+
+	// EnableQueryValidation tells gogm to run the load queries of every mapped type under EXPLAIN on startup, so typos
+	// in struct tags are found before the load that uses them runs. See Gogm.QueryValidationReport
+	EnableQueryValidation bool `json:"enable_query_validation" yaml:"enable_query_validation" mapstructure:"enable_query_validation"`
+
+	// QueryValidationDepth is the deepest load checked by query validation, every depth from 0 up to it is checked. Defaults to 1
+	QueryValidationDepth int `json:"query_validation_depth" yaml:"query_validation_depth" mapstructure:"query_validation_depth"`
+
+	// FailOnQueryValidation makes startup fail when query validation finds a query that does not compile
+	FailOnQueryValidation bool `json:"fail_on_query_validation" yaml:"fail_on_query_validation" mapstructure:"fail_on_query_validation"`
+
+	// LoadQueryCacheSize is the number of generated load queries kept for reuse. Defaults to 1000, a negative size disables the cache.
+	// Queries filtered with inline literals are not cached, use params in filters so they can be reused
+	LoadQueryCacheSize int `json:"load_query_cache_size" yaml:"load_query_cache_size" mapstructure:"load_query_cache_size"`
+
+	// MaxInfiniteDepthNodes is the number of nodes each root of a DepthInfinite load can reach, itself included, before the
+	// load fails with ErrDepthLimit. Defaults to 1000
+	MaxInfiniteDepthNodes int `json:"max_infinite_depth_nodes" yaml:"max_infinite_depth_nodes" mapstructure:"max_infinite_depth_nodes"`
+
+	// TenancyMode scopes every load, save and delete to the tenant set on the context with WithTenant.
+	// Sessions fail without a tenant while it is set. Query and QueryRaw are not scoped
+	TenancyMode TenancyMode `json:"tenancy_mode" yaml:"tenancy_mode" mapstructure:"tenancy_mode"`
+
+	// TenantProperty is the property TenancyProperty stores the tenant in. Defaults to tenant_id
+	TenantProperty string `json:"tenant_property" yaml:"tenant_property" mapstructure:"tenant_property"`
+
+	// TenantLabelPrefix is put in front of the tenant id to make the label TenancyLabel adds. Defaults to Tenant_
+	TenantLabelPrefix string `json:"tenant_label_prefix" yaml:"tenant_label_prefix" mapstructure:"tenant_label_prefix"`
+
+	// EventListeners receive NodeCreated, NodeUpdated, NodeDeleted, RelationshipCreated and RelationshipRemoved events
+	// once the transaction that made the change has been committed. Only changes made through SessionV2 are published.
+	// More listeners can be added with Gogm.Subscribe
+	EventListeners []EventListener `yaml:"-" json:"-" mapstructure:"-"`
 }
 
-// validate checks whether config object params are valid
+// validate checks whether config object params are valid. Every problem found is returned in a ConfigValidationError
 func (c *Config) validate() error {
+	var errs []error
+
 	if c.Logger == nil {
-		c.Logger = GetDefaultLogger()
+		level, err := ParseLogLevel(c.LogLevel)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			c.Logger = NewDefaultLogger(level)
+		}
+	}
+
+	if c.ParamRedactor == nil {
+		if c.EnableLogParams {
+			c.ParamRedactor = RedactNone
+		} else {
+			c.ParamRedactor = RedactAll
+		}
 	}
 
 	if c.DefaultTransactionTimeout <= 0 {
@@ -116,12 +273,28 @@ func (c *Config) validate() error {
 		c.DefaultTransactionTimeout = defaultRetryWait
 	}
 
-	if c.Host == "" {
-		return errors.New("hostname not defined")
+	if c.Host == "" && len(c.Hosts) == 0 {
+		errs = append(errs, errors.New("hostname not defined"))
 	}
 
-	if c.Port <= 0 {
-		return errors.New("port either not specified or invalid")
+	if c.Port <= 0 && !c.hostsHavePorts() {
+		errs = append(errs, errors.New("port either not specified or invalid"))
+	}
+
+	if err := c.validateRouters(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := c.readSecrets(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		errs = append(errs, errors.New("client cert file and client key file must be set together"))
+	}
+
+	if c.MaxConnectionLifetime < 0 || c.ConnectionAcquisitionTimeout < 0 || c.SocketConnectTimeout < 0 || c.MaxTransactionRetryTime < 0 {
+		errs = append(errs, errors.New("driver timeouts can not be less than 0"))
 	}
 
 	if c.TargetDbs == nil || len(c.TargetDbs) == 0 {
@@ -129,33 +302,179 @@ func (c *Config) validate() error {
 	}
 
 	if err := c.IndexStrategy.validate(); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
 	if err := c.LoadStrategy.validate(); err != nil {
-		return err
+		errs = append(errs, err)
+	}
+
+	if err := c.TenancyMode.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.TenantProperty == "" {
+		c.TenantProperty = defaultTenantProperty
+	}
+
+	if c.TenantLabelPrefix == "" {
+		c.TenantLabelPrefix = defaultTenantLabelPrefix
+	}
+
+	if c.LoadQueryCacheSize == 0 {
+		c.LoadQueryCacheSize = defaultLoadQueryCacheSize
+	}
+
+	if c.MaxInfiniteDepthNodes < 0 {
+		errs = append(errs, errors.New("max infinite depth nodes can not be less than 0"))
+	} else if c.MaxInfiniteDepthNodes == 0 {
+		c.MaxInfiniteDepthNodes = defaultMaxInfiniteDepthNodes
+	}
+
+	if c.EnableBookmarkManager && c.BookmarkManager == nil {
+		c.BookmarkManager = NewBookmarkManager()
+	}
+
+	if c.QueryValidationDepth < 0 {
+		errs = append(errs, errors.New("query validation depth can not be less than 0"))
+	} else if c.QueryValidationDepth == 0 {
+		c.QueryValidationDepth = defaultQueryValidationDepth
+	}
+
+	if c.RetryPolicy != nil {
+		if err := c.RetryPolicy.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.StartupRetry != nil {
+		if c.StartupRetry.Classifier == nil {
+			c.StartupRetry.Classifier = DefaultStartupRetryClassifier
+		}
+
+		if err := c.StartupRetry.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid startup retry, %w", err))
+		}
+	}
+
+	if len(errs) != 0 {
+		return &ConfigValidationError{Errors: errs}
 	}
 
 	return nil
 }
 
-// ConnectionString builds the neo4j connection string
-func (c *Config) ConnectionString() string {
-	var protocol string
+// isEncrypted reports whether the protocol connects over tls
+func (c *Config) isEncrypted() bool {
+	return strings.Contains(c.Protocol, "+s")
+}
 
-	if c.Protocol != "" {
-		protocol = c.Protocol
-	} else {
-		if c.IsCluster {
-			protocol = "neo4j"
+// loadTLSFiles loads the CA file and client certificate into TLSConfig, creating it if needed
+func (c *Config) loadTLSFiles() error {
+	if c.tlsFilesLoaded {
+		return nil
+	}
+
+	if c.TLSConfig == nil {
+		c.TLSConfig = &tls.Config{}
+	}
+
+	if c.CAFileLocation != "" {
+		c.Logger.Debugf("loading ca file at location `%s`", c.CAFileLocation)
+		ca, err := ioutil.ReadFile(c.CAFileLocation)
+		if err != nil {
+			return fmt.Errorf("failed to open ca file, %w", err)
+		}
+		c.Logger.Debugf("successfully loaded ca file")
+
+		var certPool *x509.CertPool
+		if c.UseSystemCertPool {
+			c.Logger.Debug("loading system cert pool")
+			var err error
+			certPool, err = x509.SystemCertPool()
+			if err != nil {
+				return fmt.Errorf("failed to get system cert pool")
+			}
+			c.Logger.Debug("successfully loaded system cert pool")
 		} else {
-			protocol = "bolt"
+			certPool = x509.NewCertPool()
+		}
+
+		if !certPool.AppendCertsFromPEM(ca) {
+			return errors.New("failed to load CA into cert pool")
 		}
+		c.TLSConfig.RootCAs = certPool
+	}
+
+	if c.ClientCertFile != "" {
+		c.Logger.Debugf("loading client certificate at location `%s`", c.ClientCertFile)
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate, %w", err)
+		}
+		c.TLSConfig.Certificates = append(c.TLSConfig.Certificates, cert)
+	}
+
+	c.tlsFilesLoaded = true
+	return nil
+}
+
+// scheme returns the protocol gogm connects with
+func (c *Config) scheme() string {
+	if c.Protocol != "" {
+		return c.Protocol
+	}
+
+	if c.IsCluster {
+		return "neo4j"
 	}
 
+	return "bolt"
+}
+
+// ConnectionString builds the neo4j connection string. Hosts that can not be parsed fail validation, ConnectionString
+// uses the first of them as written so connecting with it fails as well
+func (c *Config) ConnectionString() string {
+	connStr, err := c.connectionString()
+	if err != nil {
+		return fmt.Sprintf("%s://%s", c.protocol(), strings.TrimSpace(c.Hosts[0]))
+	}
+
+	return connStr
+}
+
+// connectionString builds the neo4j connection string, failing when the first of Hosts is used and can not be parsed
+func (c *Config) connectionString() (string, error) {
+	if c.Host == "" && len(c.Hosts) != 0 {
+		seeds, err := c.seedAddresses()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s://%s", c.protocol(), joinAddress(seeds[0])), nil
+	}
+
+	return c.hostConnectionString(), nil
+}
+
+func (c *Config) hostConnectionString() string {
 	// In case of special characters in password string
 	//password := url.QueryEscape(c.Password)
-	return fmt.Sprintf("%s://%s:%v", protocol, c.Host, c.Port)
+	return fmt.Sprintf("%s://%s:%v", c.protocol(), c.Host, c.Port)
+}
+
+// protocol is the scheme of the connection string
+func (c *Config) protocol() string {
+	protocol := c.scheme()
+
+	// the driver only skips verifying certificates for the self signed protocols
+	if c.InsecureSkipVerify || (c.TLSConfig != nil && c.TLSConfig.InsecureSkipVerify) {
+		if strings.HasSuffix(protocol, "+s") {
+			protocol += "sc"
+		}
+	}
+
+	return protocol
 }
 
 // IndexStrategy defines the different index approaches
@@ -170,6 +489,9 @@ const (
 	IGNORE_INDEX IndexStrategy = 2
 )
 
+// indexStrategyNames is indexed by IndexStrategy
+var indexStrategyNames = []string{"ASSERT_INDEX", "VALIDATE_INDEX", "IGNORE_INDEX"}
+
 func (is IndexStrategy) validate() error {
 	switch is {
 	case ASSERT_INDEX, VALIDATE_INDEX, IGNORE_INDEX:
@@ -178,3 +500,56 @@ func (is IndexStrategy) validate() error {
 		return fmt.Errorf("invalid index strategy %d", is)
 	}
 }
+
+// String returns the name of the strategy, such as ASSERT_INDEX
+func (is IndexStrategy) String() string {
+	if is.validate() == nil {
+		return indexStrategyNames[is]
+	}
+	return fmt.Sprintf("IndexStrategy(%d)", int(is))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (is IndexStrategy) MarshalText() ([]byte, error) {
+	if err := is.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(is.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of the strategy or its number
+func (is *IndexStrategy) UnmarshalText(text []byte) error {
+	value, err := parseStrategy(string(text), "index", indexStrategyNames)
+	if err != nil {
+		return err
+	}
+	*is = IndexStrategy(value)
+	return nil
+}
+
+// UnmarshalJSON accepts the name of the strategy or its number
+func (is *IndexStrategy) UnmarshalJSON(data []byte) error {
+	return unmarshalStrategyJSON(data, is.UnmarshalText)
+}
+
+// unmarshalStrategyJSON reads a strategy written as a string holding its name or as a number, so configs written
+// before strategies had names still load. null leaves the strategy unchanged
+func unmarshalStrategyJSON(data []byte, unmarshalText func(text []byte) error) error {
+	var raw interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch value := raw.(type) {
+	case nil:
+		return nil
+	case string:
+		return unmarshalText([]byte(value))
+	case json.Number:
+		return unmarshalText([]byte(value.String()))
+	default:
+		return fmt.Errorf("expected a name or a number, got %s", data)
+	}
+}
@@ -20,8 +20,10 @@
 package gogm
 
 import (
-	"github.com/stretchr/testify/require"
+	"crypto/tls"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_ConnectionString(t *testing.T) {
@@ -67,6 +69,55 @@ func TestConfig_ConnectionString(t *testing.T) {
 			},
 			Expected: "neo4j://localhost:7687",
 		},
+		{
+			Name: "InsecureSkipVerify",
+			Config: &Config{
+				Host:               "localhost",
+				Port:               7687,
+				Protocol:           "bolt+s",
+				InsecureSkipVerify: true,
+			},
+			Expected: "bolt+ssc://localhost:7687",
+		},
+		{
+			Name: "TLSConfig InsecureSkipVerify",
+			Config: &Config{
+				Host:      "localhost",
+				Port:      7687,
+				Protocol:  "neo4j+s",
+				TLSConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+			Expected: "neo4j+ssc://localhost:7687",
+		},
+		{
+			Name: "InsecureSkipVerify without tls",
+			Config: &Config{
+				Host:               "localhost",
+				Port:               7687,
+				Protocol:           "bolt",
+				InsecureSkipVerify: true,
+			},
+			Expected: "bolt://localhost:7687",
+		},
+		{
+			Name: "Hosts without Host",
+			Config: &Config{
+				Port:     7687,
+				Protocol: "neo4j",
+				Hosts:    []string{"[::1]:7688", "b.local"},
+			},
+			Expected: "neo4j://[::1]:7688",
+		},
+		{
+			Name: "Host with Hosts",
+			Config: &Config{
+				Host:     "a.local",
+				Port:     7687,
+				Protocol: "neo4j",
+				Hosts:    []string{"b.local"},
+			},
+			Expected: "neo4j://a.local:7687",
+		},
 	}
 	req := require.New(t)
 	for _, _case := range cases {
@@ -74,4 +125,11 @@ func TestConfig_ConnectionString(t *testing.T) {
 			req.Equal(_case.Expected, _case.Config.ConnectionString(), "Connection strings should be equal")
 		})
 	}
+
+	// hosts that can not be parsed fail validation and the driver instead of connecting somewhere else
+	conf := &Config{Port: 7687, Protocol: "neo4j", Hosts: []string{" a:b:c"}, PoolSize: 1}
+	_, err := conf.connectionString()
+	req.NotNil(err)
+	req.ErrorContains(conf.validate(), "invalid host")
+	req.Equal("neo4j://a:b:c", conf.ConnectionString())
 }
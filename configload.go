@@ -0,0 +1,222 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envSeparator joins the prefix and the names of nested fields in environment variable names
+const envSeparator = "_"
+
+// LoadConfig reads a Config from a YAML or JSON file, using the yaml keys of its fields. Strategies can be written by
+// name, such as ASSERT_INDEX or SCHEMA_LOAD_STRATEGY, durations as strings such as "10s", and unknown keys are an error.
+// The returned config has been validated, has its secret files read and, when the protocol uses tls, has TLSConfig
+// built from CAFileLocation, ClientCertFile and ClientKeyFile
+func LoadConfig(path string) (*Config, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return nil, fmt.Errorf("%w: unsupported config file extension `%s`, expected .yaml, .yml or .json", ErrConfiguration, ext)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file, %w", err)
+	}
+
+	conf := &Config{}
+
+	// json is a subset of yaml, so both are decoded the same way and accept the same values
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(conf); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("%w: failed to parse config file `%s`, %v", ErrConfiguration, path, err)
+	}
+
+	return conf.load()
+}
+
+// ConfigFromEnv reads a Config from environment variables named after the yaml keys of its fields in upper case,
+// joined to prefix with an underscore, such as GOGM_HOST or GOGM_RETRY_POLICY_MAX_ATTEMPTS. Lists are comma separated.
+// Secrets can be read from files named by GOGM_PASSWORD_FILE, GOGM_BEARER_TOKEN_FILE and GOGM_KERBEROS_TICKET_FILE.
+// The returned config is prepared the same way as with LoadConfig
+func ConfigFromEnv(prefix string) (*Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, envSeparator) {
+		prefix += envSeparator
+	}
+
+	conf := &Config{}
+	if _, errs := readEnv(reflect.ValueOf(conf).Elem(), prefix); len(errs) != 0 {
+		return nil, &ConfigValidationError{Errors: errs}
+	}
+
+	return conf.load()
+}
+
+// load validates a config read from a file or the environment and builds its tls config
+func (c *Config) load() (*Config, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	if c.isEncrypted() {
+		if err := c.loadTLSFiles(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrConfiguration, err)
+		}
+	}
+
+	return c, nil
+}
+
+// readSecrets reads the secret files into the fields they back, unless those fields are already set
+func (c *Config) readSecrets() error {
+	secrets := []struct {
+		name  string
+		file  string
+		value *string
+	}{
+		{name: "password", file: c.PasswordFile, value: &c.Password},
+		{name: "bearer token", file: c.BearerTokenFile, value: &c.BearerToken},
+		{name: "kerberos ticket", file: c.KerberosTicketFile, value: &c.KerberosTicket},
+	}
+
+	for _, secret := range secrets {
+		if secret.file == "" || *secret.value != "" {
+			continue
+		}
+
+		data, err := ioutil.ReadFile(secret.file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s file, %w", secret.name, err)
+		}
+
+		// secret mounts and editors usually end the file with a newline
+		*secret.value = strings.TrimRight(string(data), "\r\n")
+	}
+
+	return nil
+}
+
+// readEnv sets the fields of v from the environment and reports whether any were set. Nested structs such as
+// RetryPolicy are only allocated when one of their fields is set
+func readEnv(v reflect.Value, prefix string) (bool, []error) {
+	var set bool
+	var errs []error
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := prefix + strings.ToUpper(name)
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			nested := reflect.New(field.Type.Elem())
+			nestedSet, nestedErrs := readEnv(nested.Elem(), key+envSeparator)
+			errs = append(errs, nestedErrs...)
+			if nestedSet {
+				v.Field(i).Set(nested)
+				set = true
+			}
+			continue
+		}
+
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		if err := setEnvValue(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value for %s, %w", key, err))
+			continue
+		}
+		set = true
+	}
+
+	return set, errs
+}
+
+// setEnvValue parses value into v
+func setEnvValue(v reflect.Value, value string) error {
+	if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}
+
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	req := require.New(t)
+
+	passwordFile := writeConfigFile(t, "password", "secret\n")
+	conf, err := LoadConfig(writeConfigFile(t, "gogm.yaml", `
+host: localhost
+port: 7687
+protocol: bolt
+username: neo4j
+password_file: `+passwordFile+`
+index_strategy: ignore_index
+load_strategy: SCHEMA_LOAD_STRATEGY
+target_dbs: [neo4j, other]
+default_transaction_timeout: 30s
+retry_policy:
+  max_attempts: 3
+  initial_backoff: 50ms
+`))
+	req.Nil(err)
+	req.Equal("localhost", conf.Host)
+	req.Equal("secret", conf.Password)
+	req.Equal(IGNORE_INDEX, conf.IndexStrategy)
+	req.Equal(SCHEMA_LOAD_STRATEGY, conf.LoadStrategy)
+	req.Equal([]string{"neo4j", "other"}, conf.TargetDbs)
+	req.Equal(30*time.Second, conf.DefaultTransactionTimeout)
+	req.Equal(3, conf.RetryPolicy.MaxAttempts)
+	req.Equal(50*time.Millisecond, conf.RetryPolicy.InitialBackoff)
+	req.NotNil(conf.Logger)
+	req.Nil(conf.TLSConfig)
+
+	// numbers are still accepted for strategies
+	conf, err = LoadConfig(writeConfigFile(t, "gogm.json", "{\n\t\"host\": \"localhost\",\n\t\"port\": 7687,\n\t\"index_strategy\": 1,\n\t\"load_strategy\": \"PATH_LOAD_STRATEGY\"\n}"))
+	req.Nil(err)
+	req.Equal(VALIDATE_INDEX, conf.IndexStrategy)
+	req.Equal(PATH_LOAD_STRATEGY, conf.LoadStrategy)
+
+	_, err = LoadConfig(writeConfigFile(t, "gogm.yaml", "host: localhost\nport: 7687\nhots: typo\n"))
+	req.True(errors.Is(err, ErrConfiguration))
+	req.Contains(err.Error(), "hots")
+
+	_, err = LoadConfig(writeConfigFile(t, "gogm.toml", "host = 'localhost'"))
+	req.True(errors.Is(err, ErrConfiguration))
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	req.NotNil(err)
+
+	_, err = LoadConfig(writeConfigFile(t, "gogm.yaml", "port: 0\nindex_strategy: 7\n"))
+	req.True(errors.Is(err, ErrConfiguration))
+}
+
+func TestLoadConfig_TLS(t *testing.T) {
+	req := require.New(t)
+
+	dir, err := createTempNeoKeypair()
+	req.Nil(err)
+	defer cleanupTempNeoKeypair(dir)
+
+	conf, err := LoadConfig(writeConfigFile(t, "gogm.yaml", `
+host: localhost
+port: 7687
+protocol: neo4j+s
+ca_file_location: `+filepath.Join(dir, "ca-public.crt")+`
+client_cert_file: `+filepath.Join(dir, "public.crt")+`
+client_key_file: `+filepath.Join(dir, "private.key")+`
+`))
+	req.Nil(err)
+	req.NotNil(conf.TLSConfig)
+	req.NotNil(conf.TLSConfig.RootCAs)
+	req.Len(conf.TLSConfig.Certificates, 1)
+
+	// loading again when the driver is created does not add the certificate twice
+	req.Nil(conf.loadTLSFiles())
+	req.Len(conf.TLSConfig.Certificates, 1)
+
+	_, err = LoadConfig(writeConfigFile(t, "gogm.yaml", "host: localhost\nport: 7687\nprotocol: bolt+s\nca_file_location: "+filepath.Join(dir, "missing.crt")+"\n"))
+	req.True(errors.Is(err, ErrConfiguration))
+
+	// the tls files are added to the tls config of the file
+	conf, err = LoadConfig(writeConfigFile(t, "gogm.yaml", `
+host: localhost
+port: 7687
+protocol: neo4j+s
+ca_file_location: `+filepath.Join(dir, "ca-public.crt")+`
+tls_config:
+  servername: neo4j.internal
+`))
+	req.Nil(err)
+	req.Equal("neo4j.internal", conf.TLSConfig.ServerName)
+	req.NotNil(conf.TLSConfig.RootCAs)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	req := require.New(t)
+
+	t.Setenv("GOGM_HOST", "neo4j.local")
+	t.Setenv("GOGM_PORT", "7688")
+	t.Setenv("GOGM_IS_CLUSTER", "true")
+	t.Setenv("GOGM_BEARER_TOKEN_FILE", writeConfigFile(t, "token", "abc\r\n"))
+	t.Setenv("GOGM_TARGET_DBS", "a, b,")
+	t.Setenv("GOGM_INDEX_STRATEGY", "VALIDATE_INDEX")
+	t.Setenv("GOGM_SLOW_QUERY_THRESHOLD", "250ms")
+	t.Setenv("GOGM_STARTUP_RETRY_MAX_ATTEMPTS", "10")
+	t.Setenv("GOGM_STARTUP_RETRY_JITTER", "0.5")
+
+	conf, err := ConfigFromEnv("GOGM")
+	req.Nil(err)
+	req.Equal("neo4j.local", conf.Host)
+	req.Equal(7688, conf.Port)
+	req.True(conf.IsCluster)
+	req.Equal("abc", conf.BearerToken)
+	req.Equal([]string{"a", "b"}, conf.TargetDbs)
+	req.Equal(VALIDATE_INDEX, conf.IndexStrategy)
+	req.Equal(250*time.Millisecond, conf.SlowQueryThreshold)
+	req.Nil(conf.RetryPolicy)
+	req.Equal(10, conf.StartupRetry.MaxAttempts)
+	req.Equal(0.5, conf.StartupRetry.Jitter)
+	req.NotNil(conf.StartupRetry.Classifier)
+
+	t.Setenv("GOGM_PORT", "high")
+	t.Setenv("GOGM_LOAD_STRATEGY", "FAST")
+	_, err = ConfigFromEnv("GOGM_")
+	req.True(errors.Is(err, ErrConfiguration))
+	var validationErr *ConfigValidationError
+	req.True(errors.As(err, &validationErr))
+	req.Len(validationErr.Errors, 2)
+	req.Contains(err.Error(), "GOGM_PORT")
+	req.Contains(err.Error(), "GOGM_LOAD_STRATEGY")
+}
+
+func TestConfig_ValidateAggregatesErrors(t *testing.T) {
+	req := require.New(t)
+
+	conf := &Config{
+		LogLevel:      "loud",
+		IndexStrategy: IndexStrategy(9),
+		PasswordFile:  filepath.Join(t.TempDir(), "missing"),
+		RetryPolicy:   &RetryPolicy{Jitter: 2},
+	}
+	err := conf.validate()
+	req.True(errors.Is(err, ErrConfiguration))
+
+	var validationErr *ConfigValidationError
+	req.True(errors.As(err, &validationErr))
+	req.Len(validationErr.Errors, 6)
+	req.Contains(err.Error(), "hostname not defined")
+	req.Contains(err.Error(), "invalid index strategy 9")
+
+	// a password that is already set is not replaced by the file
+	conf = &Config{Host: "localhost", Port: 7687, Password: "set", PasswordFile: filepath.Join(t.TempDir(), "missing")}
+	req.Nil(conf.validate())
+	req.Equal("set", conf.Password)
+}
+
+func TestStrategy_Text(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal("ASSERT_INDEX", ASSERT_INDEX.String())
+	req.Equal("IndexStrategy(5)", IndexStrategy(5).String())
+	req.Equal("SCHEMA_LOAD_STRATEGY", SCHEMA_LOAD_STRATEGY.String())
+
+	data, err := json.Marshal(struct {
+		Index IndexStrategy
+		Load  LoadStrategy
+	}{IGNORE_INDEX, SCHEMA_LOAD_STRATEGY})
+	req.Nil(err)
+	req.JSONEq(`{"Index":"IGNORE_INDEX","Load":"SCHEMA_LOAD_STRATEGY"}`, string(data))
+
+	var is IndexStrategy
+	req.Nil(json.Unmarshal([]byte(`2`), &is))
+	req.Equal(IGNORE_INDEX, is)
+	req.Nil(json.Unmarshal([]byte(`"validate_index"`), &is))
+	req.Equal(VALIDATE_INDEX, is)
+	req.NotNil(json.Unmarshal([]byte(`3`), &is))
+	req.NotNil(json.Unmarshal([]byte(`2.5`), &is))
+	req.NotNil(json.Unmarshal([]byte(`true`), &is))
+	req.NotNil(is.UnmarshalJSON([]byte(`"IGNORE_INDEX`)))
+	req.NotNil(is.UnmarshalJSON([]byte(`IGNORE_INDEX"`)))
+
+	// null leaves the strategy as it was
+	req.Nil(json.Unmarshal([]byte(`null`), &is))
+	req.Equal(VALIDATE_INDEX, is)
+
+	var ls LoadStrategy
+	req.Nil(json.Unmarshal([]byte(`"1"`), &ls))
+	req.Equal(SCHEMA_LOAD_STRATEGY, ls)
+	req.Nil(ls.UnmarshalText([]byte("schema_load_strategy")))
+	req.Equal(SCHEMA_LOAD_STRATEGY, ls)
+	req.NotNil(ls.UnmarshalText([]byte("PATH")))
+
+	_, err = LoadStrategy(4).MarshalText()
+	req.NotNil(err)
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"fmt"
+)
+
+type txContextKey struct{}
+
+// ContextWithTx returns a copy of ctx that carries tx. SessionV2 functions called with the returned context
+// run inside tx instead of starting their own transaction
+func ContextWithTx(ctx context.Context, tx TransactionV2) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction carried by ctx, if there is one
+func TxFromContext(ctx context.Context) (TransactionV2, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	tx, ok := ctx.Value(txContextKey{}).(TransactionV2)
+	return tx, ok && tx != nil
+}
+
+// Do runs work inside the transaction carried by ctx. If ctx does not carry a transaction, a managed
+// write transaction is started and added to the context passed to work, so every gogm call made with
+// that context shares it. The managed transaction is committed if work returns nil
+func (g *Gogm) Do(ctx context.Context, work func(ctx context.Context) error) error {
+	if work == nil {
+		return fmt.Errorf("work can not be nil, %w", ErrInvalidParams)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// join the existing transaction
+	if _, ok := TxFromContext(ctx); ok {
+		return work(ctx)
+	}
+
+	sess, err := g.NewSessionV2(SessionConfig{
+		AccessMode: AccessModeWrite,
+		Bookmarks:  BookmarksFromContext(ctx),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session, %w", err)
+	}
+
+	err = sess.ManagedTransaction(ctx, func(tx TransactionV2) error {
+		return work(ContextWithTx(ctx, tx))
+	})
+
+	closeErr := sess.Close()
+	if err != nil {
+		return err
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("failed to close session, %w", closeErr)
+	}
+
+	return nil
+}
+
+// transactionSession returns the session that owns the transaction s should run in. That is s if it has an
+// open transaction, otherwise the session behind the transaction carried by ctx. nil means there is no open transaction
+func (s *SessionV2Impl) transactionSession(ctx context.Context) (*SessionV2Impl, error) {
+	if s.tx != nil {
+		return s, nil
+	}
+
+	tx, ok := TxFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	owner, ok := tx.(*SessionV2Impl)
+	if !ok {
+		return nil, fmt.Errorf("transaction in context is %T, not a gogm session: %w", tx, ErrTransaction)
+	}
+
+	if owner.tx == nil {
+		return nil, fmt.Errorf("transaction in context has already completed: %w", ErrTransaction)
+	}
+
+	return owner, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// foreignTx is a TransactionV2 that is not backed by a gogm session
+type foreignTx struct {
+	TransactionV2
+}
+
+// openNeoTx stands in for a driver transaction that has been started
+type openNeoTx struct {
+	neo4j.Transaction
+}
+
+func TestTxFromContext(t *testing.T) {
+	req := require.New(t)
+
+	tx, ok := TxFromContext(context.Background())
+	req.False(ok)
+	req.Nil(tx)
+
+	sess := &SessionV2Impl{}
+	tx, ok = TxFromContext(ContextWithTx(context.Background(), sess))
+	req.True(ok)
+	req.Equal(sess, tx)
+}
+
+func TestSessionV2Impl_transactionSession(t *testing.T) {
+	req := require.New(t)
+
+	sess := &SessionV2Impl{}
+
+	// no transaction anywhere
+	owner, err := sess.transactionSession(context.Background())
+	req.Nil(err)
+	req.Nil(owner)
+
+	// transaction from the context
+	other := &SessionV2Impl{tx: &openNeoTx{}}
+	owner, err = sess.transactionSession(ContextWithTx(context.Background(), other))
+	req.Nil(err)
+	req.Equal(other, owner)
+
+	// the session's own transaction wins
+	sess.tx = &openNeoTx{}
+	owner, err = sess.transactionSession(ContextWithTx(context.Background(), other))
+	req.Nil(err)
+	req.Equal(sess, owner)
+	sess.tx = nil
+
+	// completed transaction
+	_, err = sess.transactionSession(ContextWithTx(context.Background(), &SessionV2Impl{}))
+	req.True(errors.Is(err, ErrTransaction))
+
+	// transaction that gogm can not run queries in
+	_, err = sess.transactionSession(ContextWithTx(context.Background(), foreignTx{}))
+	req.True(errors.Is(err, ErrTransaction))
+}
+
+func TestGogm_DoJoinsTransaction(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	ctx := ContextWithTx(context.Background(), &SessionV2Impl{tx: &openNeoTx{}})
+
+	called := false
+	req.Nil(gogm.Do(ctx, func(workCtx context.Context) error {
+		called = true
+		req.Equal(ctx, workCtx)
+		return nil
+	}))
+	req.True(called)
+
+	workErr := errors.New("work failed")
+	req.Equal(workErr, gogm.Do(ctx, func(ctx context.Context) error {
+		return workErr
+	}))
+
+	req.True(errors.Is(gogm.Do(ctx, nil), ErrInvalidParams))
+}
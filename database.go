@@ -0,0 +1,227 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DatabaseNamer is implemented by mapped types that live in a database other than the one their session is opened on.
+// DatabaseName is called on the zero value of the type when it is mapped, so it has to return the same name every time.
+// A type can also set its database with a tag on an embedded struct, such as gogm.BaseNode `gogm:"database=analytics"`
+type DatabaseNamer interface {
+	DatabaseName() string
+}
+
+// typeDatabase finds the database a type is declared to live in, empty if it does not declare one
+func typeDatabase(t reflect.Type) (string, error) {
+	var tagDatabase string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.Anonymous {
+			continue
+		}
+
+		for _, part := range strings.Split(field.Tag.Get(decoratorName), deliminator) {
+			if strings.HasPrefix(part, databaseField+assignmentOperator) {
+				tagDatabase = strings.TrimPrefix(part, databaseField+assignmentOperator)
+				if tagDatabase == "" {
+					return "", NewInvalidDecoratorConfigError("database can not be empty", field.Name)
+				}
+			}
+		}
+	}
+
+	namer, ok := reflect.New(t).Interface().(DatabaseNamer)
+	if !ok {
+		return tagDatabase, nil
+	}
+
+	methodDatabase := namer.DatabaseName()
+	if tagDatabase != "" && tagDatabase != methodDatabase {
+		return "", NewInvalidStructConfigError(fmt.Sprintf("%s is tagged with database %s but DatabaseName returns %s", t.Name(), tagDatabase, methodDatabase))
+	}
+
+	return methodDatabase, nil
+}
+
+// inDatabase returns true if the type belongs in db. Types without a database belong in the target databases
+func (s structDecoratorConfig) inDatabase(db string, targets []string) bool {
+	if s.Database != "" {
+		return s.Database == db
+	}
+
+	for _, target := range targets {
+		if target == db {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateDatabases checks that types related to each other do not live in different databases
+func (g *Gogm) validateDatabases() error {
+	databaseOf := func(t reflect.Type) string {
+		name, err := getTypeName(t)
+		if err != nil {
+			return ""
+		}
+
+		raw, ok := g.mappedTypes.Get(name)
+		if !ok {
+			return ""
+		}
+
+		return raw.(structDecoratorConfig).Database
+	}
+
+	for entry := range g.mappedTypes.Iter() {
+		conf := entry.Value.(structDecoratorConfig)
+		if conf.Database == "" {
+			continue
+		}
+
+		var related []reflect.Type
+		if conf.IsVertex {
+			for _, field := range conf.Fields {
+				if field.Relationship != "" {
+					related = append(related, field.Type)
+				}
+			}
+		} else if edge, ok := reflect.New(conf.Type).Interface().(Edge); ok {
+			related = append(related, edge.GetStartNodeType(), edge.GetEndNodeType())
+		}
+
+		for _, t := range related {
+			if db := databaseOf(t); db != "" && db != conf.Database {
+				name, _ := getTypeName(t)
+				return NewInvalidStructConfigError(fmt.Sprintf("%s lives in database %s but is related to %s in database %s", conf.Label, conf.Database, name, db))
+			}
+		}
+	}
+
+	return nil
+}
+
+// databases returns Config.TargetDbs followed by any other database a mapped type lives in
+func (g *Gogm) databases() []string {
+	dbs := append([]string{}, g.config.TargetDbs...)
+	seen := map[string]bool{}
+	for _, db := range dbs {
+		seen[db] = true
+	}
+
+	for _, t := range g.ogmTypes {
+		raw, ok := g.mappedTypes.Get(reflect.TypeOf(t).Elem().Name())
+		if !ok {
+			continue
+		}
+
+		if db := raw.(structDecoratorConfig).Database; db != "" && !seen[db] {
+			seen[db] = true
+			dbs = append(dbs, db)
+		}
+	}
+
+	return dbs
+}
+
+// databaseOf returns the database the type of obj lives in, empty if it does not declare one
+func (g *Gogm) databaseOf(obj interface{}) string {
+	raw, ok := g.mappedTypes.Get(labelOf(obj))
+	if !ok {
+		return ""
+	}
+
+	return raw.(structDecoratorConfig).Database
+}
+
+// sessionDatabase returns the database a session opened with name runs against. Sessions without a name run against
+// the default database of the server, which is empty when the server does not report it
+func (g *Gogm) sessionDatabase(name string) string {
+	if name == "" {
+		return g.defaultDatabase
+	}
+
+	return name
+}
+
+// routeDatabase returns the database work on obj has to run in when it is not the database of s, empty otherwise.
+// Transactions can not span databases, so work in a transaction on another database is an error
+func (s *SessionV2Impl) routeDatabase(ctx context.Context, obj interface{}) (string, error) {
+	db := s.gogm.databaseOf(obj)
+	if db == "" {
+		return "", nil
+	}
+
+	txSess, err := s.transactionSession(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if txSess != nil {
+		if txDb := s.gogm.sessionDatabase(txSess.conf.DatabaseName); txDb != db {
+			return "", fmt.Errorf("%s lives in database %s but the transaction is on database %s, %w", labelOf(obj), db, txDb, ErrInvalidParams)
+		}
+		return "", nil
+	}
+
+	if s.gogm.sessionDatabase(s.conf.DatabaseName) == db {
+		return "", nil
+	}
+
+	return db, nil
+}
+
+// routedSession returns the session that work on obj has to run in. This is s unless the type of obj lives in
+// another database, in which case a session to that database is opened and kept until s is closed
+func (s *SessionV2Impl) routedSession(ctx context.Context, obj interface{}) (*SessionV2Impl, error) {
+	db, err := s.routeDatabase(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if db == "" {
+		return s, nil
+	}
+
+	if sess, ok := s.databaseSessions[db]; ok {
+		return sess, nil
+	}
+
+	conf := s.conf
+	conf.DatabaseName = db
+	sess, err := newSessionWithConfigV2(s.gogm, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session to database %s, %w", db, err)
+	}
+	sess.DefaultDepth = s.DefaultDepth
+	sess.autoRoute = s.autoRoute
+
+	if s.databaseSessions == nil {
+		s.databaseSessions = map[string]*SessionV2Impl{}
+	}
+	s.databaseSessions[db] = sess
+
+	return sess, nil
+}
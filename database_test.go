@@ -0,0 +1,250 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+type analyticsNode struct {
+	BaseUUIDNode `gogm:"database=analytics"`
+	Name         string `gogm:"name=name"`
+}
+
+type reportNode struct {
+	BaseUUIDNode
+	Name string `gogm:"name=name"`
+}
+
+func (reportNode) DatabaseName() string {
+	return "reports"
+}
+
+type conflictNode struct {
+	BaseUUIDNode `gogm:"database=analytics"`
+	Name         string `gogm:"name=name"`
+}
+
+func (conflictNode) DatabaseName() string {
+	return "reports"
+}
+
+type crossA struct {
+	BaseUUIDNode `gogm:"database=analytics"`
+	B            *crossB `gogm:"direction=outgoing;relationship=cross"`
+}
+
+type crossB struct {
+	BaseUUIDNode `gogm:"database=reports"`
+	A            *crossA `gogm:"direction=incoming;relationship=cross"`
+}
+
+// databaseDriver records the database of every transaction run through its sessions
+type databaseDriver struct {
+	neo4j.Driver
+	opened []string
+	closed []string
+	reads  []string
+	writes []string
+}
+
+func (d *databaseDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	d.opened = append(d.opened, config.DatabaseName)
+	return &databaseSession{driver: d, db: config.DatabaseName}
+}
+
+type databaseSession struct {
+	neo4j.Session
+	driver *databaseDriver
+	db     string
+}
+
+func (d *databaseSession) ReadTransaction(neo4j.TransactionWork, ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	d.driver.reads = append(d.driver.reads, d.db)
+	return nil, nil
+}
+
+func (d *databaseSession) WriteTransaction(neo4j.TransactionWork, ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	d.driver.writes = append(d.driver.writes, d.db)
+	return nil, nil
+}
+
+func (d *databaseSession) BeginTransaction(...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	return &databaseTx{}, nil
+}
+
+// databaseTx finds nothing
+type databaseTx struct {
+	fakeNeoTx
+}
+
+func (d *databaseTx) Run(string, map[string]interface{}) (neo4j.Result, error) {
+	return &recordsResult{}, nil
+}
+
+func (d *databaseSession) LastBookmarks() neo4j.Bookmarks {
+	return nil
+}
+
+func (d *databaseSession) Close() error {
+	d.driver.closed = append(d.driver.closed, d.db)
+	return nil
+}
+
+func TestTypeDatabase(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogm(&a{}, &b{}, &c{}, &analyticsNode{}, &reportNode{})
+	req.Nil(err)
+
+	req.Equal("analytics", gogm.databaseOf(&analyticsNode{}))
+	req.Equal("analytics", gogm.databaseOf(&[]*analyticsNode{}))
+	req.Equal("reports", gogm.databaseOf(&reportNode{}))
+	req.Equal("", gogm.databaseOf(&a{}))
+
+	gogm.config.TargetDbs = []string{"neo4j", "reports"}
+	req.Equal([]string{"neo4j", "reports", "analytics"}, gogm.databases())
+
+	_, err = getTestGogm(&conflictNode{})
+	req.NotNil(err)
+	req.Contains(err.Error(), "DatabaseName returns reports")
+
+	_, err = getTestGogm(&crossA{}, &crossB{})
+	req.NotNil(err)
+	req.Contains(err.Error(), "database")
+}
+
+func TestSessionV2Impl_RoutedSession(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogm(&a{}, &b{}, &c{}, &analyticsNode{}, &reportNode{})
+	req.Nil(err)
+	gogm.config.ParamRedactor = RedactAll
+	driver := &databaseDriver{}
+	gogm.driver = driver
+	ctx := context.Background()
+
+	sess, err := newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeWrite, DatabaseName: "neo4j"})
+	req.Nil(err)
+
+	req.Nil(sess.Load(ctx, &analyticsNode{}, "id"))
+	req.Nil(sess.LoadAll(ctx, &[]*reportNode{}))
+	req.Nil(sess.Load(ctx, &a{}, "id"))
+	req.Nil(sess.Save(ctx, &analyticsNode{Name: "n"}))
+	id := int64(1)
+	req.Nil(sess.Delete(ctx, &reportNode{BaseUUIDNode: BaseUUIDNode{BaseNode: BaseNode{Id: &id}, UUID: "id"}}))
+
+	req.Equal([]string{"analytics", "reports", "neo4j"}, driver.reads)
+	req.Equal([]string{"analytics", "reports"}, driver.writes)
+	// one session per database, reused across calls
+	req.Equal([]string{"neo4j", "analytics", "reports"}, driver.opened)
+
+	req.Nil(sess.Close())
+	req.ElementsMatch([]string{"neo4j", "analytics", "reports"}, driver.closed)
+	req.Empty(sess.databaseSessions)
+
+	// transactions are bound to the database of their session
+	sess, err = newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeWrite, DatabaseName: "neo4j"})
+	req.Nil(err)
+	req.Nil(sess.Begin(ctx))
+	err = sess.Save(ctx, &analyticsNode{Name: "n"})
+	req.True(errors.Is(err, ErrInvalidParams))
+	req.Contains(err.Error(), "analytics")
+	req.Nil(sess.Rollback(ctx))
+
+	// plans fail where the save would
+	req.Nil(sess.Begin(ctx))
+	_, err = sess.PlanSave(ctx, &analyticsNode{Name: "n"}, 1)
+	req.True(errors.Is(err, ErrInvalidParams))
+	req.Nil(sess.Rollback(ctx))
+
+	// explains run against the database of the type
+	sess, err = newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeWrite, DatabaseName: "neo4j"})
+	req.Nil(err)
+	driver.opened = nil
+	_, _ = sess.ExplainLoadAll(ctx, &[]*reportNode{}, 1, nil, nil, nil)
+	_, _ = sess.ExplainLoad(ctx, &analyticsNode{}, "id", 1, nil, nil, nil)
+	req.Equal([]string{"reports", "analytics"}, driver.opened)
+	req.Nil(sess.Close())
+
+	// sessions without a database name are on the default database the server reported
+	gogm.defaultDatabase = "analytics"
+	driver = &databaseDriver{}
+	gogm.driver = driver
+	sess, err = newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeWrite})
+	req.Nil(err)
+	req.Nil(sess.Load(ctx, &analyticsNode{}, "id"))
+	req.Equal([]string{""}, driver.opened)
+	req.Nil(sess.Begin(ctx))
+	err = sess.Load(ctx, &analyticsNode{}, "id")
+	req.False(errors.Is(err, ErrInvalidParams))
+	err = sess.Save(ctx, &reportNode{Name: "n"})
+	req.True(errors.Is(err, ErrInvalidParams))
+	req.Contains(err.Error(), "transaction is on database analytics")
+	req.Nil(sess.Rollback(ctx))
+
+	// when the server did not report it, types with a database get their own session
+	gogm.defaultDatabase = ""
+	driver = &databaseDriver{}
+	gogm.driver = driver
+	sess, err = newSessionWithConfigV2(gogm, SessionConfig{AccessMode: AccessModeWrite})
+	req.Nil(err)
+	req.Nil(sess.Load(ctx, &analyticsNode{}, "id"))
+	req.Equal([]string{"", "analytics"}, driver.opened)
+	req.Nil(sess.Close())
+}
+
+func TestValidateQueries_Databases(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogm(&a{}, &b{}, &c{}, &analyticsNode{})
+	req.Nil(err)
+	gogm.config.TargetDbs = []string{"neo4j"}
+
+	explained := map[string]map[string]int{}
+	explain := func(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+		if explained[db] == nil {
+			explained[db] = map[string]int{}
+		}
+		explained[db][cyp]++
+		return &cleanSummary{}, nil
+	}
+
+	report, err := validateQueries(context.Background(), gogm, 0, explain)
+	req.Nil(err)
+	// a and b only in the target database neo4j, analyticsNode only in analytics, 2 strategies, load one and all
+	req.Equal(12, report.Queries)
+	for cyp := range explained["neo4j"] {
+		req.NotContains(cyp, "analyticsNode")
+	}
+	count := func(db string) int {
+		total := 0
+		for _, n := range explained[db] {
+			total += n
+		}
+		return total
+	}
+	req.Equal(8, count("neo4j"))
+	req.Equal(4, count("analytics"))
+}
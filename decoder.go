@@ -28,6 +28,10 @@ import (
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
+// rootColumn is the column load queries return their root nodes in. When a result has it, nodes in other columns are
+// only related nodes, even when they have the label of the root
+const rootColumn = "gogm_root"
+
 func traverseResultRecordValues(values []interface{}) ([]neo4j.Path, []neo4j.Relationship, []neo4j.Node) {
 	var paths []neo4j.Path
 	var strictRels []neo4j.Relationship
@@ -60,6 +64,14 @@ func traverseResultRecordValues(values []interface{}) ([]neo4j.Path, []neo4j.Rel
 //decodes raw path response from driver
 //example query `match p=(n)-[*0..5]-() return p`
 func decode(gogm *Gogm, result neo4j.Result, respObj interface{}) (err error) {
+	return decodeLimited(gogm, result, respObj, 0)
+}
+
+// decodeLimited is decode failing with ErrDepthLimit when a record has more than maxNodes distinct nodes. Each record
+// holds one root and the nodes it reached, so the limit applies per root and the load fails as soon as such a record
+// is read instead of after the whole result is fetched. A maxNodes of 0 does not limit the result. Nodes are mapped once by their id no matter how many times they are
+// returned, so cycles in the graph are wired back to the same value instead of being followed again
+func decodeLimited(gogm *Gogm, result neo4j.Result, respObj interface{}, maxNodes int) (err error) {
 	//check nil params
 	if result == nil {
 		return fmt.Errorf("result can not be nil, %w", ErrInvalidParams)
@@ -96,9 +108,32 @@ func decode(gogm *Gogm, result neo4j.Result, respObj interface{}) (err error) {
 	var paths []neo4j.Path
 	var strictRels []neo4j.Relationship
 	var isolatedNodes []neo4j.Node
+	var roots map[int64]bool
+	var rootIds []int64
 
 	for result.Next() {
-		p, r, n := traverseResultRecordValues(result.Record().Values)
+		record := result.Record()
+		for i, key := range record.Keys {
+			if key != rootColumn || i >= len(record.Values) {
+				continue
+			}
+
+			if root, ok := record.Values[i].(neo4j.Node); ok {
+				if roots == nil {
+					roots = map[int64]bool{}
+				}
+				if !roots[root.Id] {
+					roots[root.Id] = true
+					rootIds = append(rootIds, root.Id)
+				}
+			}
+		}
+
+		p, r, n := traverseResultRecordValues(record.Values)
+		if maxNodes > 0 && countNodes(p, n) > maxNodes {
+			return fmt.Errorf("load reached more than %d nodes, %w", maxNodes, ErrDepthLimit)
+		}
+
 		paths = append(paths, p...)
 		strictRels = append(strictRels, r...)
 		isolatedNodes = append(isolatedNodes, n...)
@@ -120,12 +155,17 @@ func decode(gogm *Gogm, result neo4j.Result, respObj interface{}) (err error) {
 	}
 
 	if len(isolatedNodes) != 0 {
-		err = sortIsolatedNodes(gogm, isolatedNodes, labelLookup, nodeLookup, &pks, returnIsSingle, returnUsed, &returnValue, primaryLabel, relMaps)
+		err = sortIsolatedNodes(gogm, isolatedNodes, labelLookup, nodeLookup, &pks, returnIsSingle, returnUsed, &returnValue, primaryLabel, relMaps, roots)
 		if err != nil {
 			return err
 		}
 	}
 
+	// return the roots in the order the query returned them
+	if roots != nil {
+		pks = rootIds
+	}
+
 	if len(strictRels) != 0 {
 		err = sortStrictRels(strictRels, labelLookup, rels)
 		if err != nil {
@@ -359,6 +399,22 @@ func decode(gogm *Gogm, result neo4j.Result, respObj interface{}) (err error) {
 	return err
 }
 
+// countNodes returns the number of distinct nodes in paths and isolatedNodes
+func countNodes(paths []neo4j.Path, isolatedNodes []neo4j.Node) int {
+	ids := map[int64]struct{}{}
+	for _, path := range paths {
+		for _, node := range path.Nodes {
+			ids[node.Id] = struct{}{}
+		}
+	}
+
+	for _, node := range isolatedNodes {
+		ids[node.Id] = struct{}{}
+	}
+
+	return len(ids)
+}
+
 // getPrimaryLabel gets the label from a reflect type
 func getPrimaryLabel(rt reflect.Type) (string, error) {
 	//assume its already a pointer
@@ -377,7 +433,8 @@ func getPrimaryLabel(rt reflect.Type) (string, error) {
 }
 
 // sortIsolatedNodes process nodes that are returned individually from bolt driver
-func sortIsolatedNodes(gogm *Gogm, isolatedNodes []neo4j.Node, labelLookup map[int64]string, nodeLookup map[int64]*reflect.Value, pks *[]int64, pkSingle bool, passTypeUsed *bool, passValue *reflect.Value, pkLabel string, relMaps map[int64]map[string]*RelationConfig) error {
+// sortIsolatedNodes maps nodes returned on their own. When roots is not nil only the nodes in it are primary nodes
+func sortIsolatedNodes(gogm *Gogm, isolatedNodes []neo4j.Node, labelLookup map[int64]string, nodeLookup map[int64]*reflect.Value, pks *[]int64, pkSingle bool, passTypeUsed *bool, passValue *reflect.Value, pkLabel string, relMaps map[int64]map[string]*RelationConfig, roots map[int64]bool) error {
 	if isolatedNodes == nil {
 		return fmt.Errorf("isolatedNodes can not be nil, %w", ErrInternal)
 	}
@@ -387,7 +444,7 @@ func sortIsolatedNodes(gogm *Gogm, isolatedNodes []neo4j.Node, labelLookup map[i
 		if _, ok := nodeLookup[node.Id]; !ok {
 			//primary to return
 			isPk := false
-			if node.Labels != nil && len(node.Labels) != 0 && node.Labels[0] == pkLabel {
+			if node.Labels != nil && len(node.Labels) != 0 && node.Labels[0] == pkLabel && (roots == nil || roots[node.Id]) {
 				*pks = append(*pks, node.Id)
 				isPk = true
 			}
@@ -556,11 +613,24 @@ func convertToValue(gogm *Gogm, graphId int64, conf structDecoratorConfig, props
 		reflect.Indirect(val).FieldByName("Id").Set(reflect.ValueOf(&graphId))
 	}
 
+	// generated decoders set everything but the primary key
+	decoded := false
+	if decoder, ok := val.Interface().(NodeDecoder); ok {
+		if err := decoder.GogmDecode(props); err != nil {
+			return nil, fmt.Errorf("failed to decode %s, %w", conf.Label, err)
+		}
+		decoded = true
+	}
+
 	for field, fieldConfig := range conf.Fields {
 		if fieldConfig.Name == "id" {
 			continue //id is handled above
 		}
 
+		if decoded && fieldConfig.PrimaryKey == "" {
+			continue
+		}
+
 		//skip if its a relation field
 		if fieldConfig.Relationship != "" {
 			continue
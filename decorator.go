@@ -64,6 +64,9 @@ const (
 	//specifies if the field is to be ignored
 	ignoreField = "-"
 
+	//specifies the database the node lives in, set on an embedded struct
+	databaseField = "database"
+
 	//specifies deliminator between GoGM tags
 	deliminator = ";"
 
@@ -129,6 +132,8 @@ type structDecoratorConfig struct {
 	IsVertex bool `json:"is_vertex"`
 	// holds the reflect type of the struct
 	Type reflect.Type `json:"-"`
+	// holds the database the node lives in, empty if it lives in the database of the session
+	Database string `json:"database"`
 }
 
 // validate checks if the configuration is valid
@@ -490,6 +495,12 @@ func getStructDecoratorConfig(gogm *Gogm, i interface{}, mappedRelations *relati
 		return nil, errors.New("struct has no fields") //todo make error more thorough
 	}
 
+	database, err := typeDatabase(t)
+	if err != nil {
+		return nil, err
+	}
+	toReturn.Database = database
+
 	toReturn.Fields = map[string]decoratorConfig{}
 
 	fields := getFields(t)
@@ -544,7 +555,7 @@ func getStructDecoratorConfig(gogm *Gogm, i interface{}, mappedRelations *relati
 		}
 	}
 
-	err := toReturn.validate()
+	err = toReturn.validate()
 	if err != nil {
 		return nil, err
 	}
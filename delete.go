@@ -27,14 +27,22 @@ import (
 )
 
 // deleteNode is used to remove nodes from the database
-func deleteNode(deleteObj interface{}) (neo4j.TransactionWork, error) {
+// events for the deleted nodes are added to events, which may be nil. Nodes of a tenant other than the one of
+// scope are not deleted, scope may be nil
+func deleteNode(scope *tenantScope, deleteObj interface{}, events *eventBuffer) (neo4j.TransactionWork, error) {
 	rawType := reflect.TypeOf(deleteObj)
 
 	if rawType.Kind() != reflect.Ptr && rawType.Kind() != reflect.Slice {
 		return nil, errors.New("delete obj can only be ptr or slice")
 	}
 
+	label, err := getTypeName(rawType)
+	if err != nil {
+		return nil, err
+	}
+
 	var ids []int64
+	var deleted []Event
 
 	if rawType.Kind() == reflect.Ptr {
 		delValue := reflect.ValueOf(deleteObj).Elem()
@@ -44,6 +52,12 @@ func deleteNode(deleteObj interface{}) (neo4j.TransactionWork, error) {
 		}
 
 		ids = append(ids, *idPtr)
+		deleted = append(deleted, Event{
+			Type:    NodeDeleted,
+			Label:   label,
+			Object:  deleteObj,
+			GraphId: *idPtr,
+		})
 	} else {
 		slType := rawType.Elem()
 
@@ -69,13 +83,39 @@ func deleteNode(deleteObj interface{}) (neo4j.TransactionWork, error) {
 			}
 
 			ids = append(ids, id)
+			deleted = append(deleted, Event{
+				Type:    NodeDeleted,
+				Label:   label,
+				Object:  valueToObject(&val),
+				GraphId: id,
+			})
 		}
 	}
 
-	return deleteByIds(ids...), nil
+	work := deleteByIds(ids...)
+	return func(tx neo4j.Transaction) (interface{}, error) {
+		if err := scope.checkNodes(tx, ids); err != nil {
+			return nil, err
+		}
+
+		res, err := work(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		// only nodes that were deleted are reported
+		removed, _ := res.([]int64)
+		for _, event := range deleted {
+			if int64SliceContains(removed, event.GraphId) {
+				events.add(event)
+			}
+		}
+
+		return res, nil
+	}, nil
 }
 
-// deleteByIds deletes node by graph ids
+// deleteByIds deletes node by graph ids, returning the ids of the nodes that were deleted
 func deleteByIds(ids ...int64) neo4j.TransactionWork {
 	return func(tx neo4j.Transaction) (interface{}, error) {
 		cyp, err := dsl.QB().
@@ -87,47 +127,104 @@ func deleteByIds(ids ...int64) neo4j.TransactionWork {
 				ConditionOperator:         dsl.EqualToOperator,
 				Check:                     dsl.ParamString("row"),
 			})).
+			Cypher("WITH n, ID(n) AS id").
 			Delete(true, "n").
+			Cypher("RETURN id").
 			ToCypher()
 		if err != nil {
 			return nil, err
 		}
 
-		_, err = tx.Run(cyp, map[string]interface{}{
+		res, err := tx.Run(cyp, map[string]interface{}{
 			"rows": ids,
 		})
 		if err != nil {
 			return nil, err
 		}
 
-		return nil, nil
+		var deleted []int64
+		for res.Next() {
+			if id, ok := res.Record().Values[0].(int64); ok {
+				deleted = append(deleted, id)
+			}
+		}
+
+		return deleted, res.Err()
 	}
 }
 
 // deleteByUuids deletes nodes by uuids
-func deleteByUuids(ids ...string) neo4j.TransactionWork {
+// events for the deleted nodes are added to events, which may be nil. Only nodes of the tenant of scope are
+// deleted, scope may be nil
+func deleteByUuids(gogm *Gogm, scope *tenantScope, events *eventBuffer, ids ...string) neo4j.TransactionWork {
 	return func(tx neo4j.Transaction) (interface{}, error) {
+		// the node is returned as it was before the delete so the events can describe it
 		cyp, err := dsl.QB().
 			Cypher("UNWIND {rows} as row").
 			Match(dsl.Path().V(dsl.V{Name: "n"}).Build()).
-			Where(dsl.C(&dsl.ConditionConfig{
+			Where(scope.filter("n", dsl.C(&dsl.ConditionConfig{
 				Name:              "n",
 				Field:             "uuid",
 				ConditionOperator: dsl.EqualToOperator,
 				Check:             dsl.ParamString("row"),
-			})).
+			}), false)).
+			Cypher("WITH n, ID(n) AS id, labels(n) AS labels, properties(n) AS props").
 			Delete(true, "n").
+			Cypher("RETURN id, labels, props").
 			ToCypher()
 		if err != nil {
 			return nil, err
 		}
-		_, err = tx.Run(cyp, map[string]interface{}{
+		res, err := tx.Run(cyp, scope.params(map[string]interface{}{
 			"rows": ids,
-		})
+		}))
 		if err != nil {
 			return nil, err
 		}
 
-		return nil, nil
+		for res.Next() {
+			if events == nil {
+				continue
+			}
+
+			node, ok := deletedNode(res.Record())
+			if !ok {
+				continue
+			}
+
+			label, obj := nodeObject(gogm, node)
+			events.add(Event{
+				Type:    NodeDeleted,
+				Label:   label,
+				Object:  obj,
+				GraphId: node.Id,
+			})
+		}
+
+		return nil, res.Err()
+	}
+}
+
+// deletedNode reads the id, labels and props of a node returned by deleteByUuids
+func deletedNode(record *neo4j.Record) (neo4j.Node, bool) {
+	if record == nil || len(record.Values) != 3 {
+		return neo4j.Node{}, false
 	}
+
+	id, ok := record.Values[0].(int64)
+	if !ok {
+		return neo4j.Node{}, false
+	}
+
+	node := neo4j.Node{Id: id}
+	if labels, ok := record.Values[1].([]interface{}); ok {
+		for _, label := range labels {
+			if str, ok := label.(string); ok {
+				node.Labels = append(node.Labels, str)
+			}
+		}
+	}
+
+	node.Props, _ = record.Values[2].(map[string]interface{})
+	return node, true
 }
@@ -1 +1,103 @@
 package gogm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	req := require.New(t)
+
+	cases := []struct {
+		Name     string
+		Code     string
+		Expected []error
+	}{
+		{Name: "deadlock", Code: deadlockErrorCode, Expected: []error{ErrDeadlock, ErrTransient}},
+		{Name: "transient", Code: "Neo.TransientError.Transaction.LockAcquisitionTimeout", Expected: []error{ErrTransient}},
+		{Name: "auth", Code: "Neo.ClientError.Security.Unauthorized", Expected: []error{ErrAuth}},
+		{Name: "syntax", Code: "Neo.ClientError.Statement.SyntaxError", Expected: []error{ErrSyntax}},
+		{Name: "db not found", Code: "Neo.ClientError.Database.DatabaseNotFound", Expected: []error{ErrDatabaseUnavailable}},
+		{Name: "db unavailable", Code: "Neo.TransientError.General.DatabaseUnavailable", Expected: []error{ErrDatabaseUnavailable, ErrTransient}},
+	}
+
+	for _, _case := range cases {
+		neoErr := &neo4j.Neo4jError{Code: _case.Code, Msg: "test"}
+		err := classifyError(nil, fmt.Errorf("failed to run query, %w", neoErr))
+		for _, expected := range _case.Expected {
+			req.True(errors.Is(err, expected), "%s should be %v", _case.Name, expected)
+		}
+
+		// the driver error is still reachable
+		var unwrapped *neo4j.Neo4jError
+		req.True(errors.As(err, &unwrapped), _case.Name)
+		req.Equal(_case.Code, unwrapped.Code)
+	}
+
+	req.False(errors.Is(classifyError(nil, &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError"}), ErrTransient))
+
+	// errors that are not from neo4j are left alone
+	plain := errors.New("plain")
+	req.Equal(plain, classifyError(nil, plain))
+	req.Nil(classifyError(nil, nil))
+
+	// classified errors are not wrapped twice
+	classified := classifyError(nil, &neo4j.Neo4jError{Code: deadlockErrorCode})
+	req.Equal(classified, classifyError(nil, classified))
+}
+
+func TestClassifyError_ConstraintViolation(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	neoErr := &neo4j.Neo4jError{
+		Code: "Neo.ClientError.Schema.ConstraintValidationFailed",
+		Msg:  "Node(12) already exists with label `a` and property `test_field` = 'test'",
+	}
+
+	err = classifyError(gogm, fmt.Errorf("failed to save in auto transaction, %w", neoErr))
+	req.True(errors.Is(err, ErrConstraintViolation))
+	req.False(errors.Is(err, ErrTransient))
+
+	var cvErr *ConstraintViolationError
+	req.True(errors.As(err, &cvErr))
+	req.Equal("a", cvErr.Label)
+	req.Equal("test_field", cvErr.Property)
+	req.Equal("TestField", cvErr.Field)
+
+	// unmapped labels still report what neo4j told us
+	err = classifyError(gogm, &neo4j.Neo4jError{
+		Code: "Neo.ClientError.Schema.ConstraintValidationFailed",
+		Msg:  "Node(1) already exists with label `Unknown` and properties `x` = 1, `y` = 2",
+	})
+	req.True(errors.As(err, &cvErr))
+	req.Equal("Unknown", cvErr.Label)
+	req.Equal("x", cvErr.Property)
+	req.Empty(cvErr.Field)
+}
+
+// failingTx fails every query with err
+type failingTx struct {
+	neo4j.Transaction
+	err error
+}
+
+func (f *failingTx) Run(string, map[string]interface{}) (neo4j.Result, error) {
+	return nil, f.err
+}
+
+func TestRemoveRelations_ErrorClassified(t *testing.T) {
+	req := require.New(t)
+
+	// driver errors from a save keep the neo4j error so they can be classified
+	tx := &failingTx{err: &neo4j.Neo4jError{Code: deadlockErrorCode, Msg: "test"}}
+	_, err := removeRelations(tx, map[int64][]int64{1: {2}})
+	req.NotNil(err)
+	req.True(errors.Is(classifyError(nil, err), ErrDeadlock))
+}
@@ -22,6 +22,10 @@ package gogm
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
 // InvalidDecoratorConfigError defines an error for a malformed struct tag
@@ -82,3 +86,228 @@ var (
 	// ErrConnection is returned for connection related errors
 	ErrConnection = errors.New("gogm: connection error")
 )
+
+// ConfigValidationError holds every problem found while validating a Config. It matches ErrConfiguration with errors.Is
+type ConfigValidationError struct {
+	Errors []error
+}
+
+// Error() implements builtin Error() interface
+func (c *ConfigValidationError) Error() string {
+	msgs := make([]string, len(c.Errors))
+	for i, err := range c.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrConfiguration.Error(), strings.Join(msgs, "; "))
+}
+
+// Is allows errors.Is to match ErrConfiguration and any of the underlying errors
+func (c *ConfigValidationError) Is(target error) bool {
+	if target == ErrConfiguration {
+		return true
+	}
+
+	for _, err := range c.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	// ErrConstraintViolation is returned when a write violates a uniqueness or existence constraint.
+	// Use errors.As with *ConstraintViolationError to get the label, property and field involved
+	ErrConstraintViolation = errors.New("gogm: constraint violation")
+
+	// ErrDeadlock is returned when neo4j detected a deadlock between transactions. It is also an ErrTransient
+	ErrDeadlock = errors.New("gogm: deadlock detected")
+
+	// ErrTransient is returned for errors that may succeed if the transaction is retried
+	ErrTransient = errors.New("gogm: transient database error")
+
+	// ErrAuth is returned when authentication or authorization with neo4j fails
+	ErrAuth = errors.New("gogm: authentication error")
+
+	// ErrSyntax is returned when neo4j is unable to compile a query
+	ErrSyntax = errors.New("gogm: cypher syntax error")
+
+	// ErrDatabaseUnavailable is returned when the target database does not exist or can not serve requests
+	ErrDatabaseUnavailable = errors.New("gogm: database unavailable")
+
+	// ErrTenant is returned when tenancy is enabled and the context has no tenant, or a call touches another tenant's nodes
+	ErrTenant = errors.New("gogm: tenant error")
+
+	// ErrDepthLimit is returned when a DepthInfinite load reaches more nodes than it is allowed to
+	ErrDepthLimit = errors.New("gogm: depth limit exceeded")
+)
+
+// DatabaseError wraps an error returned by neo4j with the gogm error it maps to
+type DatabaseError struct {
+	// Kind is the gogm sentinel error this error maps to
+	Kind error
+	// Code is the neo4j status code, if there is one
+	Code string
+	// Err is the original driver error
+	Err error
+}
+
+// Error() implements builtin Error() interface
+func (d *DatabaseError) Error() string {
+	return fmt.Sprintf("%s: %s", d.Kind.Error(), d.Err.Error())
+}
+
+// Unwrap returns the original driver error
+func (d *DatabaseError) Unwrap() error {
+	return d.Err
+}
+
+// Is allows errors.Is to match the gogm sentinel error
+func (d *DatabaseError) Is(target error) bool {
+	if target == d.Kind {
+		return true
+	}
+
+	// deadlocks and unavailable databases are transient in neo4j
+	return target == ErrTransient && strings.HasPrefix(d.Code, transientErrorPrefix)
+}
+
+// ConstraintViolationError is returned when a write violates a constraint
+type ConstraintViolationError struct {
+	// Label is the label of the node that violated the constraint
+	Label string
+	// Property is the neo4j property the constraint is on
+	Property string
+	// Field is the go struct field that maps to Property, empty if the label or property are not mapped
+	Field string
+	// Code is the neo4j status code
+	Code string
+	// Err is the original driver error
+	Err error
+}
+
+// Error() implements builtin Error() interface
+func (c *ConstraintViolationError) Error() string {
+	if c.Field != "" {
+		return fmt.Sprintf("%s on label '%s' property '%s' (field '%s'): %s", ErrConstraintViolation.Error(), c.Label, c.Property, c.Field, c.Err.Error())
+	}
+
+	return fmt.Sprintf("%s on label '%s' property '%s': %s", ErrConstraintViolation.Error(), c.Label, c.Property, c.Err.Error())
+}
+
+// Unwrap returns the original driver error
+func (c *ConstraintViolationError) Unwrap() error {
+	return c.Err
+}
+
+// Is allows errors.Is to match ErrConstraintViolation
+func (c *ConstraintViolationError) Is(target error) bool {
+	return target == ErrConstraintViolation
+}
+
+var (
+	// matches the label in neo4j constraint messages, i.e "Node(0) already exists with label `Person` and property `email` = 'a'"
+	constraintLabelRegex = regexp.MustCompile("label `([^`]+)`")
+	// matches the first property in neo4j constraint messages
+	constraintPropertyRegex = regexp.MustCompile("propert(?:y|ies) `([^`]+)`")
+)
+
+// classifyError maps driver errors into the gogm error taxonomy. Errors that are not from neo4j,
+// or have already been classified, are returned as is
+func classifyError(gogm *Gogm, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var dbErr *DatabaseError
+	var constraintErr *ConstraintViolationError
+	if errors.As(err, &dbErr) || errors.As(err, &constraintErr) {
+		return err
+	}
+
+	var tokenErr *neo4j.TokenExpiredError
+	if errors.As(err, &tokenErr) {
+		// the next session asks the auth provider for a new token
+		if gogm != nil {
+			if driver, ok := gogm.driver.(*rotatingDriver); ok {
+				driver.expire()
+			}
+		}
+		return &DatabaseError{Kind: ErrAuth, Code: tokenErr.Code, Err: err}
+	}
+
+	var connErr *neo4j.ConnectivityError
+	if errors.As(err, &connErr) {
+		return &DatabaseError{Kind: ErrConnection, Err: err}
+	}
+
+	var neoErr *neo4j.Neo4jError
+	if !errors.As(err, &neoErr) {
+		return err
+	}
+
+	var kind error
+	switch {
+	case neoErr.Code == "Neo.ClientError.Schema.ConstraintValidationFailed":
+		return newConstraintViolationError(gogm, neoErr.Code, neoErr.Msg, err)
+	case neoErr.Code == deadlockErrorCode:
+		kind = ErrDeadlock
+	case neoErr.Code == "Neo.TransientError.General.DatabaseUnavailable",
+		neoErr.Code == "Neo.ClientError.Database.DatabaseNotFound",
+		neoErr.Code == "Neo.TransientError.Database.DatabaseUnavailable":
+		kind = ErrDatabaseUnavailable
+	case strings.HasPrefix(neoErr.Code, "Neo.ClientError.Security."):
+		kind = ErrAuth
+	case neoErr.Code == "Neo.ClientError.Statement.SyntaxError",
+		neoErr.Code == "Neo.ClientError.Statement.SemanticError":
+		kind = ErrSyntax
+	case neoErr.Code == terminatedErrorCode, neoErr.Code == lockClientStoppedErrorCode:
+		kind = ErrTransaction
+	case strings.HasPrefix(neoErr.Code, transientErrorPrefix):
+		kind = ErrTransient
+	default:
+		return err
+	}
+
+	return &DatabaseError{Kind: kind, Code: neoErr.Code, Err: err}
+}
+
+// newConstraintViolationError parses the label and property out of the neo4j message and finds the go field they map to
+func newConstraintViolationError(gogm *Gogm, code, msg string, err error) *ConstraintViolationError {
+	cvErr := &ConstraintViolationError{
+		Code: code,
+		Err:  err,
+	}
+
+	if match := constraintLabelRegex.FindStringSubmatch(msg); len(match) == 2 {
+		cvErr.Label = match[1]
+	}
+
+	if match := constraintPropertyRegex.FindStringSubmatch(msg); len(match) == 2 {
+		cvErr.Property = match[1]
+	}
+
+	if gogm == nil || gogm.mappedTypes == nil || cvErr.Label == "" || cvErr.Property == "" {
+		return cvErr
+	}
+
+	raw, ok := gogm.mappedTypes.Get(cvErr.Label)
+	if !ok {
+		return cvErr
+	}
+
+	conf, ok := raw.(structDecoratorConfig)
+	if !ok {
+		return cvErr
+	}
+
+	for _, field := range conf.Fields {
+		if field.Name == cvErr.Property && field.Relationship == "" {
+			cvErr.Field = field.FieldName
+			break
+		}
+	}
+
+	return cvErr
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// EventType defines the kind of change an Event describes
+type EventType int
+
+const (
+	// NodeCreated is published when a new node is created
+	NodeCreated EventType = iota
+	// NodeUpdated is published when an existing node is saved
+	NodeUpdated
+	// NodeDeleted is published when a node is deleted
+	NodeDeleted
+	// RelationshipCreated is published when a new relationship is created between two nodes
+	RelationshipCreated
+	// RelationshipRemoved is published when a relationship between two nodes is removed
+	RelationshipRemoved
+)
+
+// String implements fmt.Stringer
+func (e EventType) String() string {
+	switch e {
+	case NodeCreated:
+		return "NodeCreated"
+	case NodeUpdated:
+		return "NodeUpdated"
+	case NodeDeleted:
+		return "NodeDeleted"
+	case RelationshipCreated:
+		return "RelationshipCreated"
+	case RelationshipRemoved:
+		return "RelationshipRemoved"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(e))
+	}
+}
+
+// Event describes a change gogm persisted to neo4j
+type Event struct {
+	// Type is the kind of change
+	Type EventType
+	// Label is the label of the node, or the type of the relationship for relationship events
+	Label string
+	// Object is the go object the event refers to. For relationship events it is the start node.
+	// Nodes gogm does not hold an object for, like nodes deleted by uuid, are decoded from what neo4j returned
+	Object interface{}
+	// GraphId is the graph id of Object
+	GraphId int64
+	// Related is the end node of a relationship event
+	Related interface{}
+	// RelatedGraphId is the graph id of Related
+	RelatedGraphId int64
+}
+
+// EventListener receives events after the transaction that produced them has been committed
+type EventListener interface {
+	OnEvent(ctx context.Context, event Event)
+}
+
+// EventListenerFunc allows a plain function to be used as an EventListener
+type EventListenerFunc func(ctx context.Context, event Event)
+
+// OnEvent implements EventListener
+func (f EventListenerFunc) OnEvent(ctx context.Context, event Event) {
+	f(ctx, event)
+}
+
+// Subscribe registers a listener that receives events for every transaction committed through a SessionV2 of this
+// instance of gogm. Changes made through the deprecated Session do not publish events
+func (g *Gogm) Subscribe(listener EventListener) {
+	if listener == nil {
+		return
+	}
+
+	g.eventMu.Lock()
+	defer g.eventMu.Unlock()
+	g.eventListeners = append(g.eventListeners, listener)
+}
+
+// publishEvents delivers committed events to every listener
+func (g *Gogm) publishEvents(ctx context.Context, events []Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	g.eventMu.RLock()
+	listeners := make([]EventListener, len(g.eventListeners))
+	copy(listeners, g.eventListeners)
+	g.eventMu.RUnlock()
+
+	for _, listener := range listeners {
+		for _, event := range events {
+			g.deliverEvent(ctx, listener, event)
+		}
+	}
+}
+
+// deliverEvent calls a single listener and keeps a panicking listener from taking down the caller
+func (g *Gogm) deliverEvent(ctx context.Context, listener EventListener, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			g.logger.Errorf("event listener panicked handling %s event for %s, %v", event.Type, event.Label, r)
+		}
+	}()
+
+	listener.OnEvent(ctx, event)
+}
+
+// eventBuffer holds events until the transaction they belong to completes
+type eventBuffer struct {
+	events []Event
+}
+
+func (b *eventBuffer) add(events ...Event) {
+	if b == nil {
+		return
+	}
+
+	b.events = append(b.events, events...)
+}
+
+func (b *eventBuffer) reset() {
+	if b == nil {
+		return
+	}
+
+	b.events = nil
+}
+
+// valueToObject returns the pointer held by a reflect value so listeners get the same object the caller saved
+func valueToObject(val *reflect.Value) interface{} {
+	if val == nil || !val.IsValid() {
+		return nil
+	}
+
+	if val.Kind() == reflect.Ptr || !val.CanAddr() {
+		return val.Interface()
+	}
+
+	return val.Addr().Interface()
+}
+
+// nodeObject decodes a node gogm does not hold an object for into its mapped type, returning the label it is
+// mapped by. Both are empty when none of the labels of node are mapped
+func nodeObject(gogm *Gogm, node neo4j.Node) (string, interface{}) {
+	for _, label := range node.Labels {
+		raw, ok := gogm.mappedTypes.Get(label)
+		if !ok {
+			continue
+		}
+
+		config, ok := raw.(structDecoratorConfig)
+		if !ok || !config.IsVertex {
+			continue
+		}
+
+		val, err := convertToValue(gogm, node.Id, config, node.Props, config.Type, false, false, ptrToBool(false), nil)
+		if err != nil {
+			return label, nil
+		}
+
+		return label, valueToObject(val)
+	}
+
+	return "", nil
+}
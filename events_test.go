@@ -0,0 +1,236 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSaveEvents(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	existing := &a{BaseUUIDNode: BaseUUIDNode{BaseNode: BaseNode{Id: int64Ptr(1)}}}
+	related := &b{BaseUUIDNode: BaseUUIDNode{BaseNode: BaseNode{Id: int64Ptr(2)}}}
+	created := &b{}
+
+	existingVal, relatedVal, createdVal := reflect.ValueOf(existing), reflect.ValueOf(related), reflect.ValueOf(created)
+	existingPtr, relatedPtr, createdPtr := existingVal.Pointer(), relatedVal.Pointer(), createdVal.Pointer()
+
+	nodes := map[string]map[uintptr]*nodeCreate{
+		"a": {existingPtr: {Pointer: existingPtr, Id: 1}},
+		"b": {
+			relatedPtr: {Pointer: relatedPtr, Id: 2},
+			createdPtr: {Pointer: createdPtr, IsNew: true},
+		},
+	}
+	relations := map[string][]*relCreate{
+		"test_rel": {
+			{StartNodePtr: existingPtr, EndNodePtr: relatedPtr},
+			{StartNodePtr: existingPtr, EndNodePtr: createdPtr},
+		},
+		// a new relationship to a node that was already related by another type
+		"multib": {
+			{StartNodePtr: existingPtr, EndNodePtr: relatedPtr},
+		},
+	}
+	oldRels := map[uintptr]map[string]*RelationConfig{
+		existingPtr: {"SingleA": {Ids: []int64{2, 4}, RelationType: Multi}},
+	}
+	nodeRef := map[uintptr]*reflect.Value{existingPtr: &existingVal, relatedPtr: &relatedVal, createdPtr: &createdVal}
+	nodeIdRef := map[uintptr]int64{existingPtr: 1, relatedPtr: 2, createdPtr: 3}
+	removed := []removedRelation{{
+		StartId: 1,
+		Type:    "test_rel",
+		End:     neo4j.Node{Id: 4, Labels: []string{"b"}, Props: map[string]interface{}{"uuid": "b4"}},
+	}}
+
+	events := generateSaveEvents(gogm, nodes, relations, removed, oldRels, nodeRef, nodeIdRef)
+
+	counts := map[EventType]int{}
+	for _, event := range events {
+		counts[event.Type]++
+		switch event.Type {
+		case NodeCreated:
+			req.Equal("b", event.Label)
+			req.Equal(created, event.Object)
+			req.Equal(int64(3), event.GraphId)
+		case NodeUpdated:
+			if event.Label == "a" {
+				req.Equal(existing, event.Object)
+			} else {
+				req.Equal("b", event.Label)
+				req.Equal(related, event.Object)
+			}
+		case RelationshipCreated:
+			// the test_rel relationship to 2 was already loaded
+			req.Equal(existing, event.Object)
+			if event.Label == "test_rel" {
+				req.Equal(created, event.Related)
+			} else {
+				req.Equal("multib", event.Label)
+				req.Equal(related, event.Related)
+			}
+		case RelationshipRemoved:
+			req.Equal("test_rel", event.Label)
+			req.Equal(existing, event.Object)
+			req.Equal(int64(1), event.GraphId)
+			req.Equal(int64(4), event.RelatedGraphId)
+
+			// the removed node was not loaded so it is decoded
+			removedNode, ok := event.Related.(*b)
+			req.True(ok)
+			req.Equal("b4", removedNode.UUID)
+		}
+	}
+
+	req.Equal(map[EventType]int{
+		NodeCreated:         1,
+		NodeUpdated:         2,
+		RelationshipCreated: 2,
+		RelationshipRemoved: 1,
+	}, counts)
+}
+
+func TestDeleteByUuids_Events(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	tx := &recordsTx{records: []*neo4j.Record{{
+		Keys:   []string{"id", "labels", "props"},
+		Values: []interface{}{int64(7), []interface{}{"b"}, map[string]interface{}{"uuid": "b7", "test_field": "gone"}},
+	}}}
+	events := &eventBuffer{}
+
+	_, err = deleteByUuids(gogm, nil, events, "b7", "missing")(tx)
+	req.Nil(err)
+	req.Len(tx.statements, 1)
+	req.Contains(tx.statements[0], "DETACH DELETE n RETURN id, labels, props")
+
+	// only nodes that were deleted are reported, as the objects they were
+	req.Len(events.events, 1)
+	event := events.events[0]
+	req.Equal(NodeDeleted, event.Type)
+	req.Equal("b", event.Label)
+	req.Equal(int64(7), event.GraphId)
+	deleted, ok := event.Object.(*b)
+	req.True(ok)
+	req.Equal("b7", deleted.UUID)
+	req.Equal("gone", deleted.TestField)
+}
+
+// deleteTx answers every query with the graph ids in deleted
+type deleteTx struct {
+	neo4j.Transaction
+	statements []string
+	deleted    []int64
+}
+
+func (d *deleteTx) Run(cypher string, _ map[string]interface{}) (neo4j.Result, error) {
+	d.statements = append(d.statements, cypher)
+	return &deleteResult{ids: d.deleted}, nil
+}
+
+type deleteResult struct {
+	neo4j.Result
+	ids    []int64
+	record *neo4j.Record
+}
+
+func (d *deleteResult) Next() bool {
+	if len(d.ids) == 0 {
+		return false
+	}
+	d.record = &neo4j.Record{Keys: []string{"id"}, Values: []interface{}{d.ids[0]}}
+	d.ids = d.ids[1:]
+	return true
+}
+
+func (d *deleteResult) Record() *neo4j.Record {
+	return d.record
+}
+
+func (d *deleteResult) Err() error {
+	return nil
+}
+
+func TestDeleteNode_Events(t *testing.T) {
+	req := require.New(t)
+
+	id := int64(1)
+	node := &a{BaseUUIDNode: BaseUUIDNode{BaseNode: BaseNode{Id: &id}}}
+	for _, deleted := range []bool{true, false} {
+		events := &eventBuffer{}
+		work, err := deleteNode(nil, node, events)
+		req.Nil(err)
+
+		tx := &deleteTx{}
+		if deleted {
+			tx.deleted = []int64{1}
+		}
+		_, err = work(tx)
+		req.Nil(err)
+		req.Contains(tx.statements[0], "DETACH DELETE n RETURN id")
+
+		// a node that was already gone is not reported
+		if !deleted {
+			req.Empty(events.events)
+			continue
+		}
+		req.Len(events.events, 1)
+		req.Equal(NodeDeleted, events.events[0].Type)
+		req.Equal(int64(1), events.events[0].GraphId)
+		req.Same(node, events.events[0].Object)
+	}
+}
+
+func TestGogm_Subscribe(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	var received []Event
+	gogm.Subscribe(EventListenerFunc(func(ctx context.Context, event Event) {
+		received = append(received, event)
+	}))
+	gogm.Subscribe(EventListenerFunc(func(ctx context.Context, event Event) {
+		panic("listener failures should not reach the caller")
+	}))
+
+	sess := &SessionV2Impl{gogm: gogm}
+	sess.pendingEvents.add(Event{Type: NodeCreated, Label: "a"})
+	sess.pendingEvents.reset()
+	req.Empty(sess.pendingEvents.events, "rolled back events are dropped")
+
+	sess.pendingEvents.add(Event{Type: NodeDeleted, Label: "b"})
+	sess.publishPendingEvents(context.Background())
+	req.Equal([]Event{{Type: NodeDeleted, Label: "b"}}, received)
+	req.Empty(sess.pendingEvents.events)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/mindstand/gogm/v2"
+	"github.com/mindstand/gogm/v2/examples/movies/domain"
+)
+
+// BenchmarkLoadStrategies compares the load strategies on the movies dataset. It needs a neo4j 4 or newer server
+// with the dataset loaded (`:play movies` in the neo4j browser), configured with GOGM_HOST, GOGM_USERNAME,
+// GOGM_PASSWORD and the other GOGM_ variables, for example
+//
+//	GOGM_HOST=localhost GOGM_PORT=7687 GOGM_USERNAME=neo4j GOGM_PASSWORD=changeme go test -bench LoadStrategies ./examples/movies
+func BenchmarkLoadStrategies(b *testing.B) {
+	if os.Getenv("GOGM_HOST") == "" && os.Getenv("GOGM_HOSTS") == "" {
+		b.Skip("GOGM_HOST is not set")
+	}
+
+	ctx := context.Background()
+	for _, strategy := range []gogm.LoadStrategy{gogm.PATH_LOAD_STRATEGY, gogm.SCHEMA_LOAD_STRATEGY, gogm.SUBQUERY_LOAD_STRATEGY} {
+		config, err := gogm.ConfigFromEnv("GOGM")
+		if err != nil {
+			b.Fatal(err)
+		}
+		config.IndexStrategy = gogm.IGNORE_INDEX
+		config.LoadStrategy = strategy
+
+		_gogm, err := gogm.New(config, gogm.DefaultPrimaryKeyStrategy, &domain.Movie{}, &domain.Person{}, &domain.ActedInEdge{})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		sess, err := _gogm.NewSessionV2(gogm.SessionConfig{AccessMode: gogm.AccessModeRead})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		var movies []*domain.Movie
+		err = sess.LoadAllDepthFilterPagination(ctx, &movies, 0, nil, nil, &gogm.Pagination{LimitPerPage: 1})
+		if err != nil || len(movies) == 0 {
+			b.Skipf("the movies dataset is not loaded, %v", err)
+		}
+		id := *movies[0].Id
+
+		for _, depth := range []int{1, 2} {
+			b.Run(fmt.Sprintf("%s/LoadDepth/%d", strategy, depth), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					var movie domain.Movie
+					if err := sess.LoadDepth(ctx, &movie, id, depth); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/LoadAllPage/%d", strategy, depth), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					var page []*domain.Movie
+					err := sess.LoadAllDepthFilterPagination(ctx, &page, depth, nil, nil, &gogm.Pagination{
+						LimitPerPage:   10,
+						OrderByVarName: "n",
+						OrderByField:   "title",
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+
+		if err = sess.Close(); err != nil {
+			b.Fatal(err)
+		}
+		if err = _gogm.Close(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
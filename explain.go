@@ -0,0 +1,283 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// QueryPlan is an operator of the execution plan neo4j reports for EXPLAIN and PROFILE
+type QueryPlan struct {
+	Operator      string
+	Arguments     map[string]interface{}
+	Identifiers   []string
+	EstimatedRows float64
+	// Rows, DbHits, PageCacheHits, PageCacheMisses and Time are only set for profiled plans
+	Rows            int64
+	DbHits          int64
+	PageCacheHits   int64
+	PageCacheMisses int64
+	// Time is the time spent in the operator as reported by the server
+	Time     int64
+	Children []*QueryPlan
+}
+
+// QueryNotification is a warning or hint the server attached to a query
+type QueryNotification struct {
+	Code        string
+	Title       string
+	Description string
+	Severity    string
+}
+
+// QueryExplanation holds a generated query together with its execution plan
+type QueryExplanation struct {
+	Cypher string
+	Params map[string]interface{}
+	// Profiled is true if the query was executed with PROFILE, false for EXPLAIN
+	Profiled      bool
+	Plan          *QueryPlan
+	Notifications []QueryNotification
+}
+
+// profiler collects the explanations of queries run with a WithProfile context
+type profiler struct {
+	mu           sync.Mutex
+	explanations []*QueryExplanation
+}
+
+type profilerContextKey struct{}
+
+// WithProfile returns a copy of ctx that runs every gogm load made with it under PROFILE.
+// The loads still return their results, the plans are available from Profiles
+func WithProfile(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, profilerContextKey{}, &profiler{})
+}
+
+// Profiles returns the explanations of the queries run with a context created by WithProfile, in the order they ran
+func Profiles(ctx context.Context) []*QueryExplanation {
+	p := profilerFromContext(ctx)
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*QueryExplanation{}, p.explanations...)
+}
+
+func profilerFromContext(ctx context.Context) *profiler {
+	if ctx == nil {
+		return nil
+	}
+
+	p, _ := ctx.Value(profilerContextKey{}).(*profiler)
+	return p
+}
+
+// profileStatement prefixes cyp with PROFILE when ctx asks for it
+func profileStatement(ctx context.Context, cyp string) string {
+	if profilerFromContext(ctx) == nil {
+		return cyp
+	}
+
+	return "PROFILE " + cyp
+}
+
+// recordProfile adds the plan in sum to the profiler of ctx
+func recordProfile(ctx context.Context, cyp string, params map[string]interface{}, sum neo4j.ResultSummary) {
+	p := profilerFromContext(ctx)
+	if p == nil || sum == nil {
+		return
+	}
+
+	explanation := newQueryExplanation(cyp, params, sum)
+	p.mu.Lock()
+	p.explanations = append(p.explanations, explanation)
+	p.mu.Unlock()
+}
+
+func newQueryExplanation(cyp string, params map[string]interface{}, sum neo4j.ResultSummary) *QueryExplanation {
+	explanation := &QueryExplanation{
+		Cypher: cyp,
+		Params: params,
+	}
+
+	if profile := sum.Profile(); profile != nil {
+		explanation.Profiled = true
+		explanation.Plan = convertProfiledPlan(profile)
+	} else if plan := sum.Plan(); plan != nil {
+		explanation.Plan = convertPlan(plan)
+	}
+
+	for _, notification := range sum.Notifications() {
+		explanation.Notifications = append(explanation.Notifications, QueryNotification{
+			Code:        notification.Code(),
+			Title:       notification.Title(),
+			Description: notification.Description(),
+			Severity:    notification.Severity(),
+		})
+	}
+
+	return explanation
+}
+
+func estimatedRows(args map[string]interface{}) float64 {
+	switch rows := args["EstimatedRows"].(type) {
+	case float64:
+		return rows
+	case int64:
+		return float64(rows)
+	default:
+		return 0
+	}
+}
+
+func convertPlan(plan neo4j.Plan) *QueryPlan {
+	converted := &QueryPlan{
+		Operator:      plan.Operator(),
+		Arguments:     plan.Arguments(),
+		Identifiers:   plan.Identifiers(),
+		EstimatedRows: estimatedRows(plan.Arguments()),
+	}
+
+	for _, child := range plan.Children() {
+		converted.Children = append(converted.Children, convertPlan(child))
+	}
+
+	return converted
+}
+
+func convertProfiledPlan(plan neo4j.ProfiledPlan) *QueryPlan {
+	converted := &QueryPlan{
+		Operator:        plan.Operator(),
+		Arguments:       plan.Arguments(),
+		Identifiers:     plan.Identifiers(),
+		EstimatedRows:   estimatedRows(plan.Arguments()),
+		Rows:            plan.Records(),
+		DbHits:          plan.DbHits(),
+		PageCacheHits:   plan.PageCacheHits(),
+		PageCacheMisses: plan.PageCacheMisses(),
+		Time:            plan.Time(),
+	}
+
+	for _, child := range plan.Children() {
+		converted.Children = append(converted.Children, convertProfiledPlan(child))
+	}
+
+	return converted
+}
+
+// TotalDbHits returns the db hits of the plan and all of its children
+func (q *QueryPlan) TotalDbHits() int64 {
+	if q == nil {
+		return 0
+	}
+
+	total := q.DbHits
+	for _, child := range q.Children {
+		total += child.TotalDbHits()
+	}
+
+	return total
+}
+
+// ExplainLoad returns the query LoadDepthFilterPagination would run and its plan from EXPLAIN. The query is not executed
+func (s *SessionV2Impl) ExplainLoad(ctx context.Context, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (*QueryExplanation, error) {
+	ctx, span := s.startSpan(ctx, "ExplainLoad", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	cyp, params, err := sess.loadQuery(scope, limits, respObj, id, depth, filter, params, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	return sess.explain(ctx, cyp, params)
+}
+
+// ExplainLoadAll returns the query LoadAllDepthFilterPagination would run and its plan from EXPLAIN. The query is not executed
+func (s *SessionV2Impl) ExplainLoadAll(ctx context.Context, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (*QueryExplanation, error) {
+	ctx, span := s.startSpan(ctx, "ExplainLoadAll", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	cyp, params, err := sess.loadAllQuery(scope, limits, respObj, depth, filter, params, pagination)
+	if err != nil {
+		return nil, err
+	}
+
+	return sess.explain(ctx, cyp, params)
+}
+
+// explain runs cyp under EXPLAIN, which plans the query without executing it
+func (s *SessionV2Impl) explain(ctx context.Context, cyp string, params map[string]interface{}) (*QueryExplanation, error) {
+	if s.neoSess == nil {
+		return nil, errors.New("neo4j connection not initialized")
+	}
+
+	_, sum, err := s.QueryRaw(ctx, "EXPLAIN "+cyp, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if sum == nil {
+		return nil, errors.New("no result summary returned for explain")
+	}
+
+	return newQueryExplanation(cyp, params, sum), nil
+}
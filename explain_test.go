@@ -0,0 +1,141 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePlan struct {
+	operator string
+	args     map[string]interface{}
+	children []neo4j.ProfiledPlan
+}
+
+func (f *fakePlan) Operator() string                  { return f.operator }
+func (f *fakePlan) Arguments() map[string]interface{} { return f.args }
+func (f *fakePlan) Identifiers() []string             { return []string{"n"} }
+func (f *fakePlan) DbHits() int64                     { return 10 }
+func (f *fakePlan) Records() int64                    { return 2 }
+func (f *fakePlan) Children() []neo4j.ProfiledPlan    { return f.children }
+func (f *fakePlan) PageCacheMisses() int64            { return 1 }
+func (f *fakePlan) PageCacheHits() int64              { return 3 }
+func (f *fakePlan) PageCacheHitRatio() float64        { return 0.75 }
+func (f *fakePlan) Time() int64                       { return 5 }
+
+type fakeNotification struct {
+	neo4j.Notification
+}
+
+func (fakeNotification) Code() string        { return "Neo.ClientNotification.Statement.UnknownLabelWarning" }
+func (fakeNotification) Title() string       { return "The provided label is not in the database." }
+func (fakeNotification) Description() string { return "missing label Persn" }
+func (fakeNotification) Severity() string    { return "WARNING" }
+
+type fakeProfileSummary struct {
+	neo4j.ResultSummary
+	profile neo4j.ProfiledPlan
+}
+
+func (f *fakeProfileSummary) Profile() neo4j.ProfiledPlan { return f.profile }
+func (f *fakeProfileSummary) Plan() neo4j.Plan            { return nil }
+func (f *fakeProfileSummary) Notifications() []neo4j.Notification {
+	return []neo4j.Notification{fakeNotification{}}
+}
+
+func TestNewQueryExplanation(t *testing.T) {
+	req := require.New(t)
+
+	sum := &fakeProfileSummary{profile: &fakePlan{
+		operator: "ProduceResults",
+		args:     map[string]interface{}{"EstimatedRows": 4.0},
+		children: []neo4j.ProfiledPlan{&fakePlan{operator: "NodeByLabelScan", args: map[string]interface{}{}}},
+	}}
+
+	explanation := newQueryExplanation("MATCH (n) RETURN n", map[string]interface{}{"a": 1}, sum)
+	req.True(explanation.Profiled)
+	req.Equal("MATCH (n) RETURN n", explanation.Cypher)
+	req.Equal(map[string]interface{}{"a": 1}, explanation.Params)
+	req.Equal("ProduceResults", explanation.Plan.Operator)
+	req.Equal(4.0, explanation.Plan.EstimatedRows)
+	req.EqualValues(2, explanation.Plan.Rows)
+	req.EqualValues(10, explanation.Plan.DbHits)
+	req.Len(explanation.Plan.Children, 1)
+	req.Equal("NodeByLabelScan", explanation.Plan.Children[0].Operator)
+	req.EqualValues(20, explanation.Plan.TotalDbHits())
+	req.Equal([]QueryNotification{{
+		Code:        "Neo.ClientNotification.Statement.UnknownLabelWarning",
+		Title:       "The provided label is not in the database.",
+		Description: "missing label Persn",
+		Severity:    "WARNING",
+	}}, explanation.Notifications)
+}
+
+func TestWithProfile(t *testing.T) {
+	req := require.New(t)
+
+	req.Nil(Profiles(context.Background()))
+	req.Equal("MATCH (n) RETURN n", profileStatement(context.Background(), "MATCH (n) RETURN n"))
+
+	ctx := WithProfile(context.Background())
+	req.Equal("PROFILE MATCH (n) RETURN n", profileStatement(ctx, "MATCH (n) RETURN n"))
+
+	recordProfile(ctx, "MATCH (n) RETURN n", nil, &fakeProfileSummary{profile: &fakePlan{operator: "ProduceResults"}})
+	profiles := Profiles(ctx)
+	req.Len(profiles, 1)
+	req.Equal("MATCH (n) RETURN n", profiles[0].Cypher)
+
+	// loads run under PROFILE
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	tx := &fakeResultTx{}
+	sess := &SessionV2Impl{gogm: g, tx: tx}
+	err = sess.LoadDepth(ctx, &a{}, "uuid", 1)
+	req.True(errors.Is(err, ErrNotFound))
+	req.Len(tx.statements, 1)
+	req.Regexp("^PROFILE MATCH", tx.statements[0])
+}
+
+func TestSessionV2Impl_LoadQuery(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	sess := &SessionV2Impl{gogm: g}
+
+	cyp, params, err := sess.loadQuery(nil, nil, &a{}, "uuid", 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() WHERE n.uuid = $idprm RETURN p", cyp)
+	req.Equal(map[string]interface{}{"idprm": "uuid"}, params)
+
+	cyp, params, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() RETURN p", cyp)
+	req.Nil(params)
+
+	_, _, err = sess.loadQuery(nil, nil, a{}, "uuid", 1, nil, nil, nil)
+	req.NotNil(err)
+	_, _, err = sess.loadAllQuery(nil, nil, &a{}, 1, nil, nil, nil)
+	req.NotNil(err)
+}
@@ -21,13 +21,10 @@ package gogm
 
 import (
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"reflect"
-	"strings"
+	"sync"
 
 	"github.com/cornelk/hashmap"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -55,6 +52,24 @@ type Gogm struct {
 	driver           neo4j.Driver
 	mappedRelations  *relationConfigs
 	ogmTypes         []interface{}
+	// defaultDatabase is the database sessions without a database name run against, as reported by the server
+	defaultDatabase string
+	// eventListeners receive events for committed changes
+	eventListeners []EventListener
+	eventMu        sync.RWMutex
+	// queryStats aggregates executed statements when EnableQueryStats is set
+	queryStats *queryStats
+	// queryValidation is the report of the startup query validation
+	queryValidation *QueryValidationReport
+	// queryCache keeps generated load queries
+	queryCache *queryCache
+	// stats counts open sessions and in flight transactions
+	stats *connectionStats
+	// connected is set once the driver and database are ready, connectMu guards connecting with LazyConnect
+	connected bool
+	connectMu sync.Mutex
+	// origin is the instance a copy made before a LazyConnect instance connected connects through, so they share a driver
+	origin *Gogm
 	// isNoOp specifies whether this instance of gogm can do anything
 	// is only used for the default global gogm
 	isNoOp bool
@@ -89,6 +104,12 @@ func NewContext(ctx context.Context, config *Config, pkStrategy *PrimaryKeyStrat
 		mappedRelations:  &relationConfigs{},
 		ogmTypes:         mapTypes,
 		pkStrategy:       pkStrategy,
+		eventListeners:   append([]EventListener{}, config.EventListeners...),
+		stats:            &connectionStats{},
+	}
+
+	if config.EnableQueryStats {
+		g.queryStats = newQueryStats()
 	}
 
 	err := g.init(ctx)
@@ -106,25 +127,19 @@ func (g *Gogm) init(ctx context.Context) error {
 		return fmt.Errorf("failed to validate config, %w", err)
 	}
 
+	g.queryCache = newQueryCache(g.config.LoadQueryCacheSize)
+
 	err = g.parseOgmTypes()
 	if err != nil {
 		return fmt.Errorf("failed to parse ogm types, %w", err)
 	}
 
-	g.logger.Debug("establishing neo connection")
-
-	err = g.initDriver(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to initialize driver, %w", err)
-	}
-
-	g.logger.Debug("initializing indices")
-	err = g.initIndex(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to init indices, %w", err)
+	if g.config.LazyConnect {
+		g.logger.Debug("lazy connect enabled, connecting on first use")
+		return nil
 	}
 
-	return nil
+	return g.connect(ctx)
 }
 
 // validate checks that the config is valid and also validates other information
@@ -175,45 +190,25 @@ func (g *Gogm) parseOgmTypes() error {
 		return fmt.Errorf("failed to validate edges, %w", err)
 	}
 
+	err = g.validateDatabases()
+	if err != nil {
+		return fmt.Errorf("failed to validate databases, %w", err)
+	}
+
 	return nil
 }
 
 // initDriver initializes the underlying neo4j driver
 func (g *Gogm) initDriver(ctx context.Context) error {
-	isEncrypted := strings.Contains(g.config.Protocol, "+s")
-
-	if isEncrypted {
-		if g.config.TLSConfig == nil {
-			g.config.TLSConfig = &tls.Config{}
+	if g.config.isEncrypted() {
+		if err := g.config.loadTLSFiles(); err != nil {
+			return err
 		}
+	}
 
-		// handle deprecated config support
-		if g.config.CAFileLocation != "" {
-			g.logger.Debugf("loading ca file at location `%s`", g.config.CAFileLocation)
-			bytes, err := ioutil.ReadFile(g.config.CAFileLocation)
-			if err != nil {
-				return fmt.Errorf("failed to open ca file, %w", err)
-			}
-			g.logger.Debugf("successfully loaded ca file")
-
-			var certPool *x509.CertPool
-			if g.config.UseSystemCertPool {
-				g.logger.Debug("loading system cert pool")
-				var err error
-				certPool, err = x509.SystemCertPool()
-				if err != nil {
-					return fmt.Errorf("failed to get system cert pool")
-				}
-				g.logger.Debug("successfully loaded system cert pool")
-			} else {
-				certPool = x509.NewCertPool()
-			}
-
-			if !certPool.AppendCertsFromPEM(bytes) {
-				return errors.New("failed to load CA into cert pool")
-			}
-			g.config.TLSConfig.RootCAs = certPool
-		}
+	resolver, err := g.config.addressResolver()
+	if err != nil {
+		return fmt.Errorf("failed to build address resolver, %w", err)
 	}
 
 	neoConfig := func(neoConf *neo4j.Config) {
@@ -221,84 +216,153 @@ func (g *Gogm) initDriver(ctx context.Context) error {
 			neoConf.Log = wrapLogger(g.logger)
 		}
 
-		neoConf.MaxConnectionPoolSize = g.config.PoolSize
+		if g.config.PoolSize != 0 {
+			neoConf.MaxConnectionPoolSize = g.config.PoolSize
+		}
+		if g.config.MaxConnectionLifetime != 0 {
+			neoConf.MaxConnectionLifetime = g.config.MaxConnectionLifetime
+		}
+		if g.config.ConnectionAcquisitionTimeout != 0 {
+			neoConf.ConnectionAcquisitionTimeout = g.config.ConnectionAcquisitionTimeout
+		}
+		if g.config.SocketConnectTimeout != 0 {
+			neoConf.SocketConnectTimeout = g.config.SocketConnectTimeout
+		}
+		if g.config.MaxTransactionRetryTime != 0 {
+			neoConf.MaxTransactionRetryTime = g.config.MaxTransactionRetryTime
+		}
+		if g.config.FetchSize != 0 {
+			neoConf.FetchSize = g.config.FetchSize
+		}
+		if g.config.UserAgent != "" {
+			neoConf.UserAgent = g.config.UserAgent
+		}
+		neoConf.SocketKeepalive = !g.config.DisableSocketKeepalive
+		if resolver != nil {
+			neoConf.AddressResolver = resolver
+		}
 
-		if isEncrypted {
+		if g.config.isEncrypted() {
 			if g.config.TLSConfig.RootCAs != nil {
 				neoConf.RootCAs = g.config.TLSConfig.RootCAs
 			}
+			neoConf.TlsConfig = g.config.TLSConfig.Clone()
 		}
 	}
 
-	doneChan := make(chan error, 1)
+	doneChan := make(chan driverInit, 1)
 
 	_, hasDeadline := ctx.Deadline()
 
-	go g.initDriverRoutine(neoConfig, doneChan)
+	go g.initDriverRoutine(ctx, neoConfig, doneChan)
 
+	var result driverInit
 	if hasDeadline {
 		ctx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
 		select {
-		case err := <-doneChan:
-			if err != nil {
-				return fmt.Errorf("failed to init driver, %w", err)
-			}
-			return nil
+		case result = <-doneChan:
 		case <-ctx.Done():
+			// the driver may still come up, it is closed since nothing will use it
+			go func() {
+				if late := <-doneChan; late.err == nil {
+					_ = late.driver.Close()
+				}
+			}()
 			return errors.New("timed out initializing driver")
 		}
 	} else {
-		err := <-doneChan
-		if err != nil {
-			return fmt.Errorf("failed to init driver, %w", err)
-		}
-		return nil
+		result = <-doneChan
+	}
+
+	if result.err != nil {
+		return fmt.Errorf("failed to init driver, %w", result.err)
 	}
+
+	g.driver = result.driver
+	g.boltMajorVersion = result.boltMajorVersion
+	g.defaultDatabase = result.defaultDatabase
+	return nil
+}
+
+// driverInit is the outcome of initDriverRoutine
+type driverInit struct {
+	driver           neo4j.Driver
+	boltMajorVersion int
+	defaultDatabase  string
+	err              error
 }
 
-// initDriverRoutine is the goroutine that initializes the driver and verifies the version numbers
-func (g *Gogm) initDriverRoutine(neoConfig func(neoConf *neo4j.Config), doneChan chan error) {
-	connStr := g.config.ConnectionString()
+// initDriverRoutine is the goroutine that initializes the driver and verifies the version numbers. The driver is
+// only handed back once it has answered a test query, it is closed on every failure
+func (g *Gogm) initDriverRoutine(ctx context.Context, neoConfig func(neoConf *neo4j.Config), doneChan chan driverInit) {
+	connStr, err := g.config.connectionString()
+	if err != nil {
+		doneChan <- driverInit{err: fmt.Errorf("failed to build connection string, %w", err)}
+		return
+	}
+
 	g.logger.Debugf("connection string: %s\n", connStr)
-	driver, err := neo4j.NewDriver(connStr, neo4j.BasicAuth(g.config.Username, g.config.Password, g.config.Realm), neoConfig)
+	driver, err := g.newDriver(ctx, connStr, neoConfig)
 	if err != nil {
-		doneChan <- fmt.Errorf("failed to create driver, %w", err)
+		doneChan <- driverInit{err: fmt.Errorf("failed to create driver, %w", err)}
 		return
 	}
 
-	err = driver.VerifyConnectivity()
+	version, defaultDatabase, err := probeDriver(g, driver)
 	if err != nil {
-		doneChan <- fmt.Errorf("failed to verify connectivity, %w", err)
+		_ = driver.Close()
+		doneChan <- driverInit{err: err}
 		return
 	}
 
-	// set driver
-	g.driver = driver
+	doneChan <- driverInit{driver: driver, boltMajorVersion: version, defaultDatabase: defaultDatabase}
+}
+
+// probeDriver checks that driver can reach neo4j and returns the major bolt version it speaks and its default database,
+// which is empty when the server does not report it
+func probeDriver(g *Gogm, driver neo4j.Driver) (int, string, error) {
+	err := driver.VerifyConnectivity()
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to verify connectivity, %w", classifyError(g, err))
+	}
 
 	// get neoversion
 	sess := driver.NewSession(neo4j.SessionConfig{
 		AccessMode: neo4j.AccessModeRead,
 	})
+	defer sess.Close()
 
 	res, err := sess.Run("return 1", nil)
 	if err != nil {
-		doneChan <- fmt.Errorf("failed to run test query, %w", err)
-		return
+		return 0, "", fmt.Errorf("failed to run test query, %w", err)
 	} else if err = res.Err(); err != nil {
-		doneChan <- fmt.Errorf("failed to run test query, %w", err)
-		return
+		return 0, "", fmt.Errorf("failed to run test query, %w", err)
 	}
 
 	sum, err := res.Consume()
 	if err != nil {
-		doneChan <- fmt.Errorf("failed to consume test query, %w", err)
-		return
+		return 0, "", fmt.Errorf("failed to consume test query, %w", err)
+	}
+
+	var defaultDatabase string
+	if db := sum.Database(); db != nil {
+		defaultDatabase = db.Name()
+	}
+
+	return sum.Server().ProtocolVersion().Major, defaultDatabase, nil
+}
+
+// newDriver creates a driver that authenticates with the configured auth provider or credentials
+func (g *Gogm) newDriver(ctx context.Context, connStr string, neoConfig func(neoConf *neo4j.Config)) (neo4j.Driver, error) {
+	if g.config.AuthProvider == nil {
+		return neo4j.NewDriver(connStr, g.config.authToken(), neoConfig)
 	}
 
-	g.boltMajorVersion = sum.Server().ProtocolVersion().Major
-	doneChan <- nil
+	return newRotatingDriver(ctx, g.config.AuthProvider, func(token neo4j.AuthToken) (neo4j.Driver, error) {
+		return neo4j.NewDriver(connStr, token, neoConfig)
+	}, g.logger)
 }
 
 // initIndex initializes indexes based on the provided index strategy
@@ -344,21 +408,50 @@ func (g *Gogm) initIndex(ctx context.Context) error {
 // Copy creates a copy instance of gogm
 // todo verify if its copying the members or just referencing their pointers
 // if it is each member will need copy functionality
+// A copy of a LazyConnect instance that has not connected yet connects through g, so both share one driver
 func (g *Gogm) Copy() *Gogm {
-	return &Gogm{
+	g.eventMu.RLock()
+	defer g.eventMu.RUnlock()
+	g.connectMu.Lock()
+	defer g.connectMu.Unlock()
+
+	copied := &Gogm{
 		config:           g.config,
 		logger:           g.logger,
 		boltMajorVersion: g.boltMajorVersion,
+		defaultDatabase:  g.defaultDatabase,
 		mappedTypes:      g.mappedTypes,
 		driver:           g.driver,
 		mappedRelations:  g.mappedRelations,
 		ogmTypes:         g.ogmTypes,
+		eventListeners:   append([]EventListener{}, g.eventListeners...),
+		queryStats:       g.queryStats,
+		queryValidation:  g.queryValidation,
+		queryCache:       g.queryCache,
+		stats:            g.stats,
+		connected:        g.connected,
+	}
+
+	if g.config != nil && g.config.LazyConnect && !g.connected {
+		copied.origin = g
+		if g.origin != nil {
+			copied.origin = g.origin
+		}
 	}
+
+	return copied
 }
 
 // Close implements io.Closer and closes the underlying driver
 func (g *Gogm) Close() error {
+	g.connectMu.Lock()
+	defer g.connectMu.Unlock()
+
 	if g.driver == nil {
+		// a lazy instance that was never used has nothing to close
+		if g.config != nil && g.config.LazyConnect {
+			return nil
+		}
 		return errors.New("unable to close nil driver")
 	}
 
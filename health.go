@@ -0,0 +1,299 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// indexStateOnline and indexStateFailed are the index states reported by db.indexes()
+const (
+	indexStateOnline = "ONLINE"
+	indexStateFailed = "FAILED"
+)
+
+// DatabaseHealth is the health of one of Config.TargetDbs
+type DatabaseHealth struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Error     string `json:"error,omitempty"`
+}
+
+// IndexHealth is the state of an index in one of Config.TargetDbs
+type IndexHealth struct {
+	Database string `json:"database"`
+	Name     string `json:"name"`
+	// State is ONLINE, POPULATING or FAILED
+	State string `json:"state"`
+}
+
+// HealthReport is the result of Gogm.HealthCheck
+type HealthReport struct {
+	Healthy bool `json:"healthy"`
+	// Error is set when the driver can not connect to neo4j
+	Error         string           `json:"error,omitempty"`
+	ServerAddress string           `json:"server_address,omitempty"`
+	ServerVersion string           `json:"server_version,omitempty"`
+	Databases     []DatabaseHealth `json:"databases,omitempty"`
+	Indexes       []IndexHealth    `json:"indexes,omitempty"`
+	Stats         Stats            `json:"stats"`
+	CheckedAt     time.Time        `json:"checked_at"`
+	Duration      time.Duration    `json:"duration"`
+}
+
+// Stats is a snapshot of the sessions and transactions of a gogm instance
+type Stats struct {
+	OpenSessions         int64 `json:"open_sessions"`
+	InFlightTransactions int64 `json:"in_flight_transactions"`
+	// PoolSize is the configured size of the driver connection pool, zero if the driver default is used
+	PoolSize int `json:"pool_size"`
+	// PoolUtilization is the share of PoolSize held by in flight transactions. The driver does not expose
+	// its pool so connections held outside of gogm transactions are not counted
+	PoolUtilization float64 `json:"pool_utilization"`
+}
+
+// connectionStats counts the sessions and transactions gogm has open
+type connectionStats struct {
+	openSessions         int64
+	inFlightTransactions int64
+}
+
+func (c *connectionStats) addSessions(delta int64) {
+	if c != nil {
+		atomic.AddInt64(&c.openSessions, delta)
+	}
+}
+
+func (c *connectionStats) addTransactions(delta int64) {
+	if c != nil {
+		atomic.AddInt64(&c.inFlightTransactions, delta)
+	}
+}
+
+// trackTransaction counts a transaction as in flight until the returned func is called
+func (c *connectionStats) trackTransaction() func() {
+	c.addTransactions(1)
+	return func() {
+		c.addTransactions(-1)
+	}
+}
+
+// Stats returns the number of open sessions, in flight transactions and how much of the connection pool they use
+func (g *Gogm) Stats() Stats {
+	var stats Stats
+	if g.stats != nil {
+		stats.OpenSessions = atomic.LoadInt64(&g.stats.openSessions)
+		stats.InFlightTransactions = atomic.LoadInt64(&g.stats.inFlightTransactions)
+	}
+
+	if g.config != nil && g.config.PoolSize > 0 {
+		stats.PoolSize = g.config.PoolSize
+		stats.PoolUtilization = float64(stats.InFlightTransactions) / float64(stats.PoolSize)
+	}
+
+	return stats
+}
+
+// Ping checks that the driver can reach neo4j
+func (g *Gogm) Ping(ctx context.Context) error {
+	if g.isNoOp {
+		return errors.New("gogm instance is no op. Unable to ping")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := g.ensureConnected(ctx)
+	if err != nil {
+		return err
+	}
+
+	doneChan := make(chan error, 1)
+	go func() {
+		doneChan <- g.driver.VerifyConnectivity()
+	}()
+
+	select {
+	case err := <-doneChan:
+		if err != nil {
+			return fmt.Errorf("failed to verify connectivity, %w", classifyError(g, err))
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("ping did not complete, %w", ctx.Err())
+	}
+}
+
+// HealthCheck checks connectivity, that every database in Config.TargetDbs can serve queries and the state of their indexes.
+// The report is always returned, the error is set when the instance is not healthy
+func (g *Gogm) HealthCheck(ctx context.Context) (*HealthReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report := &HealthReport{
+		CheckedAt: time.Now(),
+	}
+	defer func() {
+		report.Duration = time.Since(report.CheckedAt)
+		report.Stats = g.Stats()
+	}()
+
+	err := g.Ping(ctx)
+	if err != nil {
+		report.Error = err.Error()
+		return report, err
+	}
+
+	var failed []string
+	for _, db := range g.healthCheckDbs() {
+		dbHealth, indexes, err := g.checkDatabase(ctx, db, report)
+		report.Databases = append(report.Databases, dbHealth)
+		report.Indexes = append(report.Indexes, indexes...)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", db, err))
+		}
+	}
+
+	if len(failed) != 0 {
+		return report, fmt.Errorf("unhealthy databases %v, %w", failed, ErrDatabaseUnavailable)
+	}
+
+	report.Healthy = true
+	return report, nil
+}
+
+// healthCheckDbs returns the databases checked by HealthCheck
+func (g *Gogm) healthCheckDbs() []string {
+	if g.boltMajorVersion < 4 || g.config == nil || len(g.config.TargetDbs) == 0 {
+		// neo4j 3 only has the default database
+		return []string{""}
+	}
+
+	return g.config.TargetDbs
+}
+
+// checkDatabase runs a query against db and reads the state of its indexes
+func (g *Gogm) checkDatabase(ctx context.Context, db string, report *HealthReport) (DatabaseHealth, []IndexHealth, error) {
+	dbHealth := DatabaseHealth{Name: db}
+
+	sess, err := g.NewSessionV2(SessionConfig{
+		AccessMode:   AccessModeRead,
+		DatabaseName: db,
+	})
+	if err != nil {
+		dbHealth.Error = err.Error()
+		return dbHealth, nil, err
+	}
+	defer sess.Close()
+
+	_, sum, err := sess.QueryRaw(ctx, "RETURN 1", nil)
+	if err != nil {
+		dbHealth.Error = err.Error()
+		return dbHealth, nil, err
+	}
+	dbHealth.Available = true
+
+	if sum != nil && sum.Server() != nil {
+		report.ServerAddress = sum.Server().Address()
+		report.ServerVersion = sum.Server().Agent()
+	}
+
+	query := "CALL db.indexes() YIELD name, state RETURN name, state"
+	if g.boltMajorVersion < 4 {
+		query = "CALL db.indexes() YIELD indexName, state RETURN indexName, state"
+	}
+
+	rows, _, err := sess.QueryRaw(ctx, query, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to read index state, %w", err)
+		dbHealth.Error = err.Error()
+		return dbHealth, nil, err
+	}
+
+	var indexes []IndexHealth
+	var failedIndexes []string
+	for _, row := range rows {
+		if len(row) != 2 {
+			continue
+		}
+
+		index := IndexHealth{Database: db}
+		index.Name, _ = row[0].(string)
+		index.State, _ = row[1].(string)
+		indexes = append(indexes, index)
+
+		if index.State == indexStateFailed {
+			failedIndexes = append(failedIndexes, index.Name)
+		}
+	}
+
+	if len(failedIndexes) != 0 {
+		err = fmt.Errorf("failed indexes %v", failedIndexes)
+		dbHealth.Error = err.Error()
+		return dbHealth, indexes, err
+	}
+
+	return dbHealth, indexes, nil
+}
+
+// HealthHandler returns an http.Handler that serves a liveness check on /livez, which pings neo4j, and a readiness
+// check on /readyz, which runs HealthCheck and writes the report as json. Both respond with 503 when the check fails
+func (g *Gogm) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		body := map[string]interface{}{"alive": true}
+		if err := g.Ping(r.Context()); err != nil {
+			status = http.StatusServiceUnavailable
+			body = map[string]interface{}{"alive": false, "error": err.Error()}
+		}
+
+		writeHealthResponse(w, status, body)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		status := http.StatusOK
+		report, err := g.HealthCheck(r.Context())
+		if err != nil {
+			status = http.StatusServiceUnavailable
+		}
+
+		writeHealthResponse(w, status, report)
+	})
+
+	return mux
+}
+
+func writeHealthResponse(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// trackTransaction counts a transaction run by the session as in flight until the returned func is called
+func (s *SessionV2Impl) trackTransaction() func() {
+	return s.gogm.stats.trackTransaction()
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDriver hands out healthSessions and fails connectivity checks with connErr
+type fakeDriver struct {
+	neo4j.Driver
+	connErr    error
+	indexState string
+	// unavailable databases fail every query
+	unavailable map[string]bool
+	stats       *connectionStats
+	inFlight    []int64
+}
+
+func (f *fakeDriver) VerifyConnectivity() error {
+	return f.connErr
+}
+
+func (f *fakeDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	return &healthSession{driver: f, db: config.DatabaseName}
+}
+
+type healthSession struct {
+	neo4j.Session
+	driver *fakeDriver
+	db     string
+}
+
+func (h *healthSession) ReadTransaction(work neo4j.TransactionWork, _ ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return h.run(work)
+}
+
+func (h *healthSession) WriteTransaction(work neo4j.TransactionWork, _ ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return h.run(work)
+}
+
+func (h *healthSession) run(work neo4j.TransactionWork) (interface{}, error) {
+	if h.driver.unavailable[h.db] {
+		return nil, &neo4j.Neo4jError{Code: "Neo.ClientError.Database.DatabaseNotFound", Msg: "database not found"}
+	}
+
+	h.driver.inFlight = append(h.driver.inFlight, h.driver.stats.inFlightTransactions)
+	return work(&healthTx{driver: h.driver})
+}
+
+func (h *healthSession) Close() error {
+	return nil
+}
+
+type healthTx struct {
+	neo4j.Transaction
+	driver *fakeDriver
+}
+
+func (h *healthTx) Run(cypher string, _ map[string]interface{}) (neo4j.Result, error) {
+	res := &recordsResult{summary: &serverSummary{}}
+	if strings.Contains(cypher, "db.indexes") {
+		res.records = []*neo4j.Record{
+			{Values: []interface{}{"index_a", "ONLINE"}, Keys: []string{"name", "state"}},
+			{Values: []interface{}{"index_b", h.driver.indexState}, Keys: []string{"name", "state"}},
+		}
+	} else {
+		res.records = []*neo4j.Record{{Values: []interface{}{int64(1)}, Keys: []string{"1"}}}
+	}
+
+	return res, nil
+}
+
+type recordsResult struct {
+	neo4j.Result
+	records []*neo4j.Record
+	current *neo4j.Record
+	summary neo4j.ResultSummary
+}
+
+func (r *recordsResult) Next() bool {
+	if len(r.records) == 0 {
+		return false
+	}
+	r.current, r.records = r.records[0], r.records[1:]
+	return true
+}
+
+func (r *recordsResult) Record() *neo4j.Record {
+	return r.current
+}
+
+func (r *recordsResult) Err() error {
+	return nil
+}
+
+func (r *recordsResult) Consume() (neo4j.ResultSummary, error) {
+	r.records = nil
+	return r.summary, nil
+}
+
+type serverSummary struct {
+	neo4j.ResultSummary
+}
+
+func (serverSummary) Server() neo4j.ServerInfo            { return fakeServerInfo{} }
+func (serverSummary) ResultAvailableAfter() time.Duration { return 0 }
+func (serverSummary) ResultConsumedAfter() time.Duration  { return 0 }
+
+type fakeServerInfo struct {
+	neo4j.ServerInfo
+}
+
+func (fakeServerInfo) Address() string { return "localhost:7687" }
+func (fakeServerInfo) Agent() string   { return "Neo4j/4.4.0" }
+
+func getTestHealthGogm(driver *fakeDriver) *Gogm {
+	g := &Gogm{
+		config: &Config{
+			TargetDbs:     []string{"neo4j", "other"},
+			PoolSize:      10,
+			ParamRedactor: RedactAll,
+		},
+		logger:           GetDefaultLogger(),
+		boltMajorVersion: 4,
+		driver:           driver,
+		stats:            &connectionStats{},
+	}
+	driver.stats = g.stats
+	return g
+}
+
+func TestGogm_HealthCheck(t *testing.T) {
+	req := require.New(t)
+	ctx := context.Background()
+
+	driver := &fakeDriver{indexState: "ONLINE", unavailable: map[string]bool{}}
+	g := getTestHealthGogm(driver)
+
+	req.Nil(g.Ping(ctx))
+	report, err := g.HealthCheck(ctx)
+	req.Nil(err)
+	req.True(report.Healthy)
+	req.Equal("Neo4j/4.4.0", report.ServerVersion)
+	req.Equal("localhost:7687", report.ServerAddress)
+	req.Len(report.Databases, 2)
+	req.True(report.Databases[0].Available)
+	req.Equal("other", report.Databases[1].Name)
+	req.Len(report.Indexes, 4)
+	// sessions opened by the check are closed and their transactions were counted while running
+	req.Equal(int64(0), report.Stats.OpenSessions)
+	req.Equal(int64(0), report.Stats.InFlightTransactions)
+	req.Equal([]int64{1, 1, 1, 1}, driver.inFlight)
+
+	// a failed index makes the database unhealthy
+	driver.indexState = "FAILED"
+	report, err = g.HealthCheck(ctx)
+	req.True(errors.Is(err, ErrDatabaseUnavailable))
+	req.False(report.Healthy)
+	req.True(report.Databases[0].Available)
+	req.Contains(report.Databases[0].Error, "index_b")
+
+	// so does a database that can not serve queries
+	driver.indexState = "POPULATING"
+	driver.unavailable["other"] = true
+	report, err = g.HealthCheck(ctx)
+	req.NotNil(err)
+	req.True(report.Databases[0].Available)
+	req.False(report.Databases[1].Available)
+
+	// and losing connectivity
+	driver.connErr = errors.New("connection refused")
+	req.NotNil(g.Ping(ctx))
+	report, err = g.HealthCheck(ctx)
+	req.NotNil(err)
+	req.False(report.Healthy)
+	req.NotEmpty(report.Error)
+	req.Empty(report.Databases)
+}
+
+func TestGogm_Stats(t *testing.T) {
+	req := require.New(t)
+
+	g := getTestHealthGogm(&fakeDriver{})
+	sess, err := g.NewSessionV2(SessionConfig{AccessMode: AccessModeRead})
+	req.Nil(err)
+
+	done := sess.(*SessionV2Impl).trackTransaction()
+	stats := g.Stats()
+	req.Equal(int64(1), stats.OpenSessions)
+	req.Equal(int64(1), stats.InFlightTransactions)
+	req.Equal(10, stats.PoolSize)
+	req.Equal(0.1, stats.PoolUtilization)
+
+	done()
+	req.Nil(sess.Close())
+	req.Equal(Stats{PoolSize: 10}, g.Stats())
+
+	// instances without stats report nothing
+	req.Equal(Stats{}, (&Gogm{}).Stats())
+}
+
+func TestGogm_HealthHandler(t *testing.T) {
+	req := require.New(t)
+
+	driver := &fakeDriver{indexState: "ONLINE", unavailable: map[string]bool{}}
+	handler := getTestHealthGogm(driver).HealthHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	req.Equal(http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	req.Equal(http.StatusOK, rec.Code)
+	req.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	var report HealthReport
+	req.Nil(json.Unmarshal(rec.Body.Bytes(), &report))
+	req.True(report.Healthy)
+	req.Len(report.Databases, 2)
+
+	driver.unavailable["neo4j"] = true
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	req.Equal(http.StatusServiceUnavailable, rec.Code)
+
+	driver.connErr = errors.New("connection refused")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	req.Equal(http.StatusServiceUnavailable, rec.Code)
+}
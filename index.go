@@ -28,7 +28,7 @@ import (
 //drops all known indexes
 func dropAllIndexesAndConstraints(ctx context.Context, gogm *Gogm) error {
 	if gogm.boltMajorVersion >= 4 {
-		for _, db := range gogm.config.TargetDbs {
+		for _, db := range gogm.databases() {
 			err := dropAllIndexesAndConstraintsV4(ctx, gogm, db)
 			if err != nil {
 				return fmt.Errorf("failed to drop indexes and constraints for db %s on db version 4+, %w", db, err)
@@ -47,7 +47,7 @@ func dropAllIndexesAndConstraints(ctx context.Context, gogm *Gogm) error {
 //creates all indexes
 func createAllIndexesAndConstraints(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap) error {
 	if gogm.boltMajorVersion >= 4 {
-		for _, db := range gogm.config.TargetDbs {
+		for _, db := range gogm.databases() {
 			err := createAllIndexesAndConstraintsV4(ctx, gogm, mappedTypes, db)
 			if err != nil {
 				return fmt.Errorf("failed to create indexes and constraints for db %s on db version 4+, %w", db, err)
@@ -65,7 +65,7 @@ func createAllIndexesAndConstraints(ctx context.Context, gogm *Gogm, mappedTypes
 //verifies all indexes
 func verifyAllIndexesAndConstraints(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap) error {
 	if gogm.boltMajorVersion >= 4 {
-		for _, db := range gogm.config.TargetDbs {
+		for _, db := range gogm.databases() {
 			err := verifyAllIndexesAndConstraintsV4(ctx, gogm, mappedTypes, db)
 			if err != nil {
 				return fmt.Errorf("failed to verify indexes and constraints for db %s on db version 4+, %w", db, err)
@@ -57,7 +57,7 @@ func resultToStringArrV3(result [][]interface{}) ([]string, error) {
 
 //drops all known indexes
 func dropAllIndexesAndConstraintsV3(ctx context.Context, gogm *Gogm) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode: neo4j.AccessModeWrite,
 	})
 	if err != nil {
@@ -124,7 +124,7 @@ func dropAllIndexesAndConstraintsV3(ctx context.Context, gogm *Gogm) error {
 
 //creates all indexes
 func createAllIndexesAndConstraintsV3(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode: neo4j.AccessModeWrite,
 	})
 	if err != nil {
@@ -201,7 +201,7 @@ func createAllIndexesAndConstraintsV3(ctx context.Context, gogm *Gogm, mappedTyp
 
 //verifies all indexes
 func verifyAllIndexesAndConstraintsV3(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode: neo4j.AccessModeRead,
 	})
 	if err != nil {
@@ -94,7 +94,7 @@ func resultToStringArrV4(isConstraint bool, result [][]interface{}) ([]string, e
 
 //drops all known indexes
 func dropAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, db string) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode:   neo4j.AccessModeWrite,
 		DatabaseName: db,
 	})
@@ -165,7 +165,7 @@ func dropAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, db string)
 
 //creates all indexes
 func createAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap, db string) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode:   neo4j.AccessModeWrite,
 		DatabaseName: db,
 	})
@@ -187,7 +187,7 @@ func createAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, mappedTyp
 		for nodes := range mappedTypes.Iter() {
 			node := nodes.Key.(string)
 			structConfig := nodes.Value.(structDecoratorConfig)
-			if structConfig.Fields == nil || len(structConfig.Fields) == 0 {
+			if structConfig.Fields == nil || len(structConfig.Fields) == 0 || !structConfig.inDatabase(db, gogm.config.TargetDbs) {
 				continue
 			}
 
@@ -228,7 +228,7 @@ func createAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, mappedTyp
 
 //verifies all indexes
 func verifyAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, mappedTypes *hashmap.HashMap, db string) error {
-	sess, err := gogm.NewSessionV2(SessionConfig{
+	sess, err := openSessionV2(gogm, SessionConfig{
 		AccessMode:   neo4j.AccessModeWrite,
 		DatabaseName: db,
 	})
@@ -251,7 +251,7 @@ func verifyAllIndexesAndConstraintsV4(ctx context.Context, gogm *Gogm, mappedTyp
 		node := nodes.Key.(string)
 		structConfig := nodes.Value.(structDecoratorConfig)
 
-		if structConfig.Fields == nil || len(structConfig.Fields) == 0 {
+		if structConfig.Fields == nil || len(structConfig.Fields) == 0 || !structConfig.inDatabase(db, gogm.config.TargetDbs) {
 			continue
 		}
 
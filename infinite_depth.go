@@ -0,0 +1,352 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+)
+
+// DepthInfinite is passed as the depth of a load to load every node reachable from the loaded nodes through mapped
+// relationships. Nodes reached more than once, such as through a cycle, are loaded once and every pointer to them
+// is the same. The load fails with ErrDepthLimit when a loaded node reaches more nodes than it is allowed to, see
+// InfiniteDepthOptions and Config.MaxInfiniteDepthNodes. Relationships are only followed in the directions the mapped
+// types declare them in. It requires neo4j 4 or newer
+const DepthInfinite = -1
+
+// defaultMaxInfiniteDepthNodes is the number of nodes a DepthInfinite load can reach when Config.MaxInfiniteDepthNodes is not set
+const defaultMaxInfiniteDepthNodes = 1000
+
+// maxNodesParam is the param DepthInfinite queries take the node limit from, so the limit is not part of the cached query
+const maxNodesParam = "gogm_max_nodes"
+
+// InfiniteDepthOptions limits what a DepthInfinite load follows
+type InfiniteDepthOptions struct {
+	// Relationships are the relationship types followed. Every mapped relationship is followed when it is empty
+	Relationships []string
+	// MaxNodes is the number of nodes each loaded node can reach, itself included, before the load fails with ErrDepthLimit.
+	// It can only lower Config.MaxInfiniteDepthNodes
+	MaxNodes int
+}
+
+type infiniteDepthContextKey struct{}
+
+// WithInfiniteDepthOptions returns a copy of ctx that limits DepthInfinite loads made with it to opts
+func WithInfiniteDepthOptions(ctx context.Context, opts InfiniteDepthOptions) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, infiniteDepthContextKey{}, opts)
+}
+
+// InfiniteDepthOptionsFromContext returns the options set with WithInfiniteDepthOptions
+func InfiniteDepthOptionsFromContext(ctx context.Context) (InfiniteDepthOptions, bool) {
+	if ctx == nil {
+		return InfiniteDepthOptions{}, false
+	}
+
+	opts, ok := ctx.Value(infiniteDepthContextKey{}).(InfiniteDepthOptions)
+	return opts, ok
+}
+
+// depthLimits are the InfiniteDepthOptions of a load checked against the mapped types and the config
+type depthLimits struct {
+	// relationships is sorted so the generated query is the same for the same set of relationships
+	relationships []string
+	// outgoing and incoming are the relationships only mapped from their start or their end node, they are only
+	// followed in that direction. The others are followed both ways
+	outgoing, incoming []string
+	maxNodes           int
+}
+
+// depthLimits returns the limits of a load with depth from ctx, nil when depth is not DepthInfinite
+func (g *Gogm) depthLimits(ctx context.Context, depth int) (*depthLimits, error) {
+	if depth != DepthInfinite {
+		return nil, nil
+	}
+
+	opts, _ := InfiniteDepthOptionsFromContext(ctx)
+	if opts.MaxNodes < 0 {
+		return nil, fmt.Errorf("infinite depth max nodes can not be less than 0, %w", ErrInvalidParams)
+	}
+
+	maxNodes := defaultMaxInfiniteDepthNodes
+	if g.config != nil && g.config.MaxInfiniteDepthNodes > 0 {
+		maxNodes = g.config.MaxInfiniteDepthNodes
+	}
+	if opts.MaxNodes > 0 && opts.MaxNodes < maxNodes {
+		maxNodes = opts.MaxNodes
+	}
+
+	mapped := g.mappedRelationshipTypes()
+	relationships := mapped
+	if len(opts.Relationships) != 0 {
+		relationships = make([]string, 0, len(opts.Relationships))
+		for _, rel := range opts.Relationships {
+			i := sort.SearchStrings(mapped, rel)
+			if i == len(mapped) || mapped[i] != rel {
+				return nil, fmt.Errorf("relationship %s is not mapped by any type, %w", rel, ErrInvalidParams)
+			}
+			relationships = append(relationships, rel)
+		}
+		relationships = sortedUnique(relationships)
+	}
+
+	outgoing, incoming := g.mappedDirections(relationships)
+	return &depthLimits{
+		relationships: relationships,
+		outgoing:      outgoing,
+		incoming:      incoming,
+		maxNodes:      maxNodes,
+	}, nil
+}
+
+// shape identifies the queries the limits generate. The node limit is left out since it is a param
+func (d *depthLimits) shape() string {
+	if d == nil {
+		return ""
+	}
+
+	shape := strings.Join(d.relationships, "|")
+	if len(d.outgoing) != 0 {
+		shape += ">" + strings.Join(d.outgoing, "|")
+	}
+	if len(d.incoming) != 0 {
+		shape += "<" + strings.Join(d.incoming, "|")
+	}
+	return shape
+}
+
+// params adds the node limit to params, creating the map when needed
+func (d *depthLimits) params(params map[string]interface{}) map[string]interface{} {
+	if d == nil {
+		return params
+	}
+
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params[maxNodesParam] = d.maxNodes
+	return params
+}
+
+// nodeLimit is the number of nodes the result of a load can decode to, 0 when it is not limited
+func (d *depthLimits) nodeLimit() int {
+	if d == nil {
+		return 0
+	}
+
+	return d.maxNodes
+}
+
+// mappedRelationshipTypes returns every relationship type used by the mapped types, sorted
+func (g *Gogm) mappedRelationshipTypes() []string {
+	var types []string
+	for entry := range g.mappedTypes.Iter() {
+		conf, ok := entry.Value.(structDecoratorConfig)
+		if !ok {
+			continue
+		}
+
+		for _, field := range conf.Fields {
+			if !field.Ignore && field.Relationship != "" {
+				types = append(types, field.Relationship)
+			}
+		}
+	}
+
+	return sortedUnique(types)
+}
+
+// mappedDirections returns the relationships of rels that the mapped types only map from their start node and those
+// they only map from their end node, keeping the order of rels
+func (g *Gogm) mappedDirections(rels []string) (outgoing, incoming []string) {
+	fromStart, fromEnd := map[string]bool{}, map[string]bool{}
+	for entry := range g.mappedTypes.Iter() {
+		conf, ok := entry.Value.(structDecoratorConfig)
+		if !ok {
+			continue
+		}
+
+		for _, field := range conf.Fields {
+			if field.Ignore || field.Relationship == "" {
+				continue
+			}
+
+			switch field.Direction {
+			case dsl.DirectionOutgoing:
+				fromStart[field.Relationship] = true
+			case dsl.DirectionIncoming:
+				fromEnd[field.Relationship] = true
+			default:
+				fromStart[field.Relationship] = true
+				fromEnd[field.Relationship] = true
+			}
+		}
+	}
+
+	for _, rel := range rels {
+		switch {
+		case fromStart[rel] && !fromEnd[rel]:
+			outgoing = append(outgoing, rel)
+		case fromEnd[rel] && !fromStart[rel]:
+			incoming = append(incoming, rel)
+		}
+	}
+
+	return outgoing, incoming
+}
+
+// expansion is the OPTIONAL MATCH that reaches the nodes variable can reach through the relationships of the limits,
+// as gogm_node. Mixed directions and tenancy need the path, which stops the server from pruning paths to nodes it
+// already reached
+func (d *depthLimits) expansion(scope *tenantScope, variable string) string {
+	types := strings.Join(d.relationships, "|")
+
+	pattern := fmt.Sprintf("(%s)-[:%s*1..]-(gogm_node)", variable, types)
+	mixed := false
+	switch {
+	case len(d.outgoing) == len(d.relationships):
+		pattern = fmt.Sprintf("(%s)-[:%s*1..]->(gogm_node)", variable, types)
+	case len(d.incoming) == len(d.relationships):
+		pattern = fmt.Sprintf("(%s)<-[:%s*1..]-(gogm_node)", variable, types)
+	default:
+		mixed = len(d.outgoing) != 0 || len(d.incoming) != 0
+	}
+
+	conditions := []string{"gogm_node <> " + variable}
+	if mixed {
+		var step []string
+		if len(d.outgoing) != 0 {
+			step = append(step, fmt.Sprintf("(NOT type(relationships(gogm_path)[gogm_i]) IN %s OR startNode(relationships(gogm_path)[gogm_i]) = nodes(gogm_path)[gogm_i])", cypherStrings(d.outgoing)))
+		}
+		if len(d.incoming) != 0 {
+			step = append(step, fmt.Sprintf("(NOT type(relationships(gogm_path)[gogm_i]) IN %s OR endNode(relationships(gogm_path)[gogm_i]) = nodes(gogm_path)[gogm_i])", cypherStrings(d.incoming)))
+		}
+		conditions = append(conditions, fmt.Sprintf("all(gogm_i IN range(0, length(gogm_path) - 1) WHERE %s)", strings.Join(step, " AND ")))
+	}
+	if scope != nil {
+		conditions = append(conditions, fmt.Sprintf("all(%s IN nodes(gogm_path)[1..] WHERE %s)", tenantPathVariable, scope.predicate(tenantPathVariable)))
+	}
+	if mixed || scope != nil {
+		pattern = "gogm_path=" + pattern
+	}
+
+	return fmt.Sprintf("OPTIONAL MATCH %s WHERE %s", pattern, strings.Join(conditions, " AND "))
+}
+
+// cypherStrings is values as a cypher list of strings
+func cypherStrings(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// sortedUnique sorts values and removes duplicates in place
+func sortedUnique(values []string) []string {
+	sort.Strings(values)
+
+	unique := values[:0]
+	for i, value := range values {
+		if i == 0 || value != values[i-1] {
+			unique = append(unique, value)
+		}
+	}
+
+	return unique
+}
+
+// infiniteDepthLoadMany loads every node of label matching additionalConstraints with DepthInfinite. Pagination,
+// which may be nil, is applied to the root nodes before their relationships are followed
+func infiniteDepthLoadMany(scope *tenantScope, limits *depthLimits, variable, label string, additionalConstraints dsl.ConditionOperator, pagination *Pagination) (dsl.Cypher, error) {
+	builder, err := matchRoots(variable, label, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	return infiniteDepthExpandRoot(scope, limits, builder, variable, pagination)
+}
+
+// infiniteDepthLoadByKey loads the node of label with the primary key in paramName with DepthInfinite. When many is
+// set paramName holds a list of primary keys and every node with one of them is loaded
+func infiniteDepthLoadByKey(scope *tenantScope, limits *depthLimits, variable, label, fieldOn, paramName string, isGraphId, many bool, additionalConstraints dsl.ConditionOperator, pagination *Pagination) (dsl.Cypher, error) {
+	builder, err := matchRoot(variable, label, fieldOn, paramName, isGraphId, many, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	return infiniteDepthExpandRoot(scope, limits, builder, variable, pagination)
+}
+
+// infiniteDepthExpandRoot paginates the matched root nodes, then collects the nodes reachable from each of them and
+// the relationships between those nodes. Each root collects at most maxNodes distinct nodes besides itself, which is
+// one more than a root within the limit reaches, so the server never collects more than that and the decoder can tell
+// the limit was exceeded from the row of the root
+func infiniteDepthExpandRoot(scope *tenantScope, limits *depthLimits, builder dsl.Cypher, variable string, pagination *Pagination) (dsl.Cypher, error) {
+	if limits == nil {
+		return nil, errors.New("limits can not be nil")
+	}
+
+	builder = builder.Cypher("WITH " + variable)
+
+	if pagination != nil {
+		if err := pagination.Paginate(builder); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(limits.relationships) == 0 {
+		builder = builder.Cypher(fmt.Sprintf("RETURN %s AS %s", variable, rootColumn))
+	} else {
+		types := strings.Join(limits.relationships, "|")
+
+		builder = builder.
+			Cypher(fmt.Sprintf("CALL { WITH %s %s WITH DISTINCT gogm_node LIMIT $%s RETURN collect(gogm_node) AS gogm_nodes }",
+				variable, limits.expansion(scope, variable), maxNodesParam)).
+			Cypher(fmt.Sprintf("UNWIND [%s] + gogm_nodes AS gogm_from", variable)).
+			// a root over the limit fails the load, so its relationships are not needed
+			Cypher(fmt.Sprintf("OPTIONAL MATCH (gogm_from)-[gogm_rel:%s]->(gogm_to) WHERE size(gogm_nodes) < $%s AND (gogm_to = %s OR gogm_to IN gogm_nodes)", types, maxNodesParam, variable)).
+			Cypher(fmt.Sprintf("WITH %s, gogm_nodes, collect(gogm_rel) AS gogm_rels", variable)).
+			Cypher(fmt.Sprintf("RETURN %s AS %s, gogm_nodes, gogm_rels", variable, rootColumn))
+	}
+
+	// collecting the reached nodes loses the order of the roots
+	if pagination != nil && pagination.OrderByField != "" {
+		order := &Pagination{
+			OrderByVarName: pagination.OrderByVarName,
+			OrderByField:   pagination.OrderByField,
+			OrderByDesc:    pagination.OrderByDesc,
+		}
+		if err := order.Paginate(builder); err != nil {
+			return nil, err
+		}
+	}
+
+	return builder, nil
+}
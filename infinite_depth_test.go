@@ -0,0 +1,210 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGogm_DepthLimits(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	// only DepthInfinite has limits
+	limits, err := gogm.depthLimits(ctx, 2)
+	req.Nil(err)
+	req.Nil(limits)
+	req.Equal(0, limits.nodeLimit())
+
+	// every mapped relationship is followed by default
+	limits, err = gogm.depthLimits(ctx, DepthInfinite)
+	req.Nil(err)
+	req.Equal([]string{"multib", "special_multi", "special_single", "test", "test_rel", "testm2o"}, limits.relationships)
+	req.Equal(defaultMaxInfiniteDepthNodes, limits.nodeLimit())
+	// the test types map each relationship from both ends
+	req.Empty(limits.outgoing)
+	req.Empty(limits.incoming)
+
+	limits, err = gogm.depthLimits(WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{
+		Relationships: []string{"testm2o", "test", "testm2o"},
+		MaxNodes:      50,
+	}), DepthInfinite)
+	req.Nil(err)
+	req.Equal([]string{"test", "testm2o"}, limits.relationships)
+	req.Equal("test|testm2o", limits.shape())
+	req.Equal(50, limits.nodeLimit())
+	req.Equal(map[string]interface{}{maxNodesParam: 50}, limits.params(nil))
+
+	// the options can not raise the limit of the config
+	gogm.config.MaxInfiniteDepthNodes = 20
+	limits, err = gogm.depthLimits(WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{MaxNodes: 50}), DepthInfinite)
+	req.Nil(err)
+	req.Equal(20, limits.nodeLimit())
+
+	_, err = gogm.depthLimits(WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{Relationships: []string{"unknown"}}), DepthInfinite)
+	req.True(errors.Is(err, ErrInvalidParams))
+
+	_, err = gogm.depthLimits(WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{MaxNodes: -1}), DepthInfinite)
+	req.True(errors.Is(err, ErrInvalidParams))
+}
+
+func TestSessionV2Impl_InfiniteDepthLoadQuery(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	sess := &SessionV2Impl{gogm: gogm}
+	limits := &depthLimits{relationships: []string{"test"}, maxNodes: 5}
+
+	cyp, params, err := sess.loadAllQuery(nil, limits, &[]f{}, DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH (n:f) WITH n CALL { WITH n OPTIONAL MATCH (n)-[:test*1..]-(gogm_node) WHERE gogm_node <> n "+
+		"WITH DISTINCT gogm_node LIMIT $gogm_max_nodes RETURN collect(gogm_node) AS gogm_nodes } "+
+		"UNWIND [n] + gogm_nodes AS gogm_from "+
+		"OPTIONAL MATCH (gogm_from)-[gogm_rel:test]->(gogm_to) WHERE size(gogm_nodes) < $gogm_max_nodes AND (gogm_to = n OR gogm_to IN gogm_nodes) "+
+		"WITH n, gogm_nodes, collect(gogm_rel) AS gogm_rels RETURN n AS gogm_root, gogm_nodes, gogm_rels", cyp)
+	// without a path the server can prune paths to nodes it already reached
+	req.NotContains(cyp, "gogm_path")
+	req.Equal(map[string]interface{}{maxNodesParam: 5}, params)
+
+	// the roots are paginated before they are expanded and ordered again once the reached nodes are collected
+	cyp, _, err = sess.loadAllQuery(nil, limits, &[]f{}, DepthInfinite, nil, nil, &Pagination{LimitPerPage: 2, OrderByVarName: "n", OrderByField: "uuid"})
+	req.Nil(err)
+	req.Regexp("^MATCH \\(n:f\\) WITH n ORDER BY n.uuid LIMIT 2 CALL \\{", cyp)
+	req.Regexp("RETURN n AS gogm_root, gogm_nodes, gogm_rels ORDER BY n.uuid$", cyp)
+
+	// every node on the way has to belong to the tenant
+	scope := &tenantScope{mode: TenancyProperty, tenant: "acme", property: "tenant_id"}
+	cyp, params, err = sess.loadQuery(scope, limits, &f{}, "uuid", DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Regexp("^MATCH \\(n:f\\) WHERE \\(n.`tenant_id` = \\$gogm_tenant\\) AND n.uuid = \\$idprm WITH n", cyp)
+	req.Contains(cyp, "OPTIONAL MATCH gogm_path=(n)-[:test*1..]-(gogm_node) WHERE gogm_node <> n AND "+
+		"all(gogm_tenant_node IN nodes(gogm_path)[1..] WHERE gogm_tenant_node.`tenant_id` = $gogm_tenant) WITH DISTINCT gogm_node")
+	req.Equal(map[string]interface{}{"idprm": "uuid", tenantParam: "acme", maxNodesParam: 5}, params)
+
+	// a different set of relationships is a different query
+	other, _, err := sess.loadQuery(scope, &depthLimits{relationships: []string{"test", "testm2o"}, maxNodes: 5}, &f{}, "uuid", DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.NotEqual(cyp, other)
+	req.Contains(other, "-[:test|testm2o*1..]-")
+
+	// relationships mapped one way are only followed that way
+	cyp, _, err = sess.loadAllQuery(nil, &depthLimits{relationships: []string{"test"}, outgoing: []string{"test"}, maxNodes: 5}, &[]f{}, DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Contains(cyp, "OPTIONAL MATCH (n)-[:test*1..]->(gogm_node) WHERE gogm_node <> n WITH")
+	cyp, _, err = sess.loadAllQuery(nil, &depthLimits{relationships: []string{"test"}, incoming: []string{"test"}, maxNodes: 5}, &[]f{}, DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Contains(cyp, "OPTIONAL MATCH (n)<-[:test*1..]-(gogm_node) WHERE gogm_node <> n WITH")
+
+	// mixed directions are checked on every step of the path
+	mixed := &depthLimits{relationships: []string{"test", "test_rel", "testm2o"}, outgoing: []string{"test"}, incoming: []string{"testm2o"}, maxNodes: 5}
+	req.Equal("test|test_rel|testm2o>test<testm2o", mixed.shape())
+	cyp, _, err = sess.loadAllQuery(nil, mixed, &[]f{}, DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Contains(cyp, "OPTIONAL MATCH gogm_path=(n)-[:test|test_rel|testm2o*1..]-(gogm_node) WHERE gogm_node <> n AND all(gogm_i IN range(0, length(gogm_path) - 1) WHERE "+
+		"(NOT type(relationships(gogm_path)[gogm_i]) IN ['test'] OR startNode(relationships(gogm_path)[gogm_i]) = nodes(gogm_path)[gogm_i]) AND "+
+		"(NOT type(relationships(gogm_path)[gogm_i]) IN ['testm2o'] OR endNode(relationships(gogm_path)[gogm_i]) = nodes(gogm_path)[gogm_i])) WITH DISTINCT gogm_node")
+
+	// without relationships to follow only the root is loaded
+	cyp, _, err = sess.loadAllQuery(nil, &depthLimits{maxNodes: 5}, &[]f{}, DepthInfinite, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH (n:f) WITH n RETURN n AS gogm_root", cyp)
+}
+
+func TestDecodeLimited_Cycle(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	// f1 -> f2 -> f3 -> f1
+	f1 := neo4j.Node{Id: 1, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f1"}}
+	f2 := neo4j.Node{Id: 2, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f2"}}
+	f3 := neo4j.Node{Id: 3, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f3"}}
+	result := func() neo4j.Result {
+		return &recordsResult{records: []*neo4j.Record{{
+			Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"},
+			Values: []interface{}{f1, []interface{}{f2, f3}, []interface{}{
+				neo4j.Relationship{Id: 10, StartId: 1, EndId: 2, Type: "test"},
+				neo4j.Relationship{Id: 11, StartId: 2, EndId: 3, Type: "test"},
+				neo4j.Relationship{Id: 12, StartId: 3, EndId: 1, Type: "test"},
+			}},
+		}}}
+	}
+
+	var loaded []*f
+	req.Nil(decodeLimited(gogm, result(), &loaded, 3))
+	req.Len(loaded, 1)
+
+	// following the cycle leads back to the same value
+	root := loaded[0]
+	req.Len(root.Parents, 1)
+	req.Equal("f2", root.Parents[0].UUID)
+	req.Equal("f3", root.Parents[0].Parents[0].UUID)
+	req.Same(root, root.Parents[0].Parents[0].Parents[0])
+	req.Same(root, root.Children[0].Children[0].Children[0])
+	req.Same(root.Parents[0], root.Children[0].Children[0])
+
+	var single f
+	req.Nil(decodeLimited(gogm, result(), &single, 0))
+	req.Equal("f1", single.UUID)
+	req.Same(&single, single.Parents[0].Parents[0].Parents[0])
+
+	loaded = nil
+	err = decodeLimited(gogm, result(), &loaded, 2)
+	req.True(errors.Is(err, ErrDepthLimit))
+	req.Nil(loaded)
+
+	// the limit applies to each root, roots sharing a limit's worth of nodes each load
+	f4 := neo4j.Node{Id: 4, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f4"}}
+	twoRoots := &recordsResult{records: []*neo4j.Record{
+		{Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"}, Values: []interface{}{f1, []interface{}{f2}, []interface{}{}}},
+		{Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"}, Values: []interface{}{f3, []interface{}{f4}, []interface{}{}}},
+	}}
+	loaded = nil
+	req.Nil(decodeLimited(gogm, twoRoots, &loaded, 2))
+	req.Len(loaded, 2)
+
+	// the load fails on the first root over the limit without reading the rest of the result
+	over := &recordsResult{records: []*neo4j.Record{
+		{Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"}, Values: []interface{}{f1, []interface{}{f2, f3}, []interface{}{}}},
+		{Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"}, Values: []interface{}{f4, []interface{}{}, []interface{}{}}},
+	}}
+	loaded = nil
+	req.True(errors.Is(decodeLimited(gogm, over, &loaded, 2), ErrDepthLimit))
+	req.Len(over.records, 1)
+}
+
+func TestSession_DepthInfinite(t *testing.T) {
+	req := require.New(t)
+
+	// the deprecated session can not take the options
+	sess := &Session{}
+	req.True(errors.Is(sess.LoadDepth(&a{}, "uuid", DepthInfinite), ErrInvalidParams))
+	req.True(errors.Is(sess.LoadAllDepth(&[]a{}, DepthInfinite), ErrInvalidParams))
+}
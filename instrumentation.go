@@ -0,0 +1,429 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+)
+
+// attribute keys following the OpenTelemetry database semantic conventions
+const (
+	AttributeDbSystem    = "db.system"
+	AttributeDbName      = "db.name"
+	AttributeDbStatement = "db.statement"
+	AttributeDbOperation = "db.operation"
+	// AttributeDbLabel is the label of the node type an operation works on
+	AttributeDbLabel = "db.neo4j.label"
+	// AttributeDbParams holds query params after they went through Config.ParamRedactor
+	AttributeDbParams = "db.neo4j.params"
+	// AttributeRetryAttempt is the attempt number of a retried transaction
+	AttributeRetryAttempt = "db.gogm.retry.attempt"
+	// AttributeRetryBackoff is how long gogm waits before retrying a transaction
+	AttributeRetryBackoff = "db.gogm.retry.backoff"
+)
+
+const dbSystemNeo4j = "neo4j"
+
+// Attribute is a key/value pair attached to spans
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates an Attribute
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans around gogm operations. Set Config.Tracer to plug in a tracing backend,
+// the otelgogm package provides an OpenTelemetry implementation
+type Tracer interface {
+	// Start starts a span named operation and returns a context carrying it
+	Start(ctx context.Context, operation string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation. Spans that also have a `TraceId() string` method get their trace id
+// added to gogm's log messages
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	AddEvent(name string, attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// QueryMetric describes a single cypher statement executed by gogm
+type QueryMetric struct {
+	// Operation is the gogm operation that ran the statement, i.e Load or Save
+	Operation string
+	Database  string
+	Statement string
+	Duration  time.Duration
+	// Rows is the number of records returned
+	Rows int
+	// ResultAvailableAfter is the time the server took until the first record was available
+	ResultAvailableAfter time.Duration
+	// ResultConsumedAfter is the time the server took to stream all records
+	ResultConsumedAfter time.Duration
+	Err                 error
+}
+
+// RetryMetric describes a managed transaction attempt that failed and is retried
+type RetryMetric struct {
+	Operation string
+	Database  string
+	Attempt   int
+	Backoff   time.Duration
+	Err       error
+}
+
+// MetricsRecorder receives measurements from gogm. Set Config.Metrics to plug in a metrics backend,
+// the otelgogm package provides an OpenTelemetry implementation which can be exported to Prometheus
+type MetricsRecorder interface {
+	// RecordQuery is called after every statement gogm runs
+	RecordQuery(ctx context.Context, metric QueryMetric)
+	// RecordRetry is called before a failed transaction is retried
+	RecordRetry(ctx context.Context, metric RetryMetric)
+	// RecordSessions is called with +1 when a session is opened and -1 when it is closed
+	RecordSessions(ctx context.Context, database string, delta int64)
+}
+
+type operationContextKey struct{}
+
+type spanContextKey struct{}
+
+// traceIdFromContext returns the trace id of the gogm span carried by ctx, if its span exposes one
+func traceIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	span, ok := ctx.Value(spanContextKey{}).(interface{ TraceId() string })
+	if !ok {
+		return ""
+	}
+
+	return span.TraceId()
+}
+
+// operationFromContext returns the outermost gogm operation running with ctx
+func operationFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	op, _ := ctx.Value(operationContextKey{}).(string)
+	return op
+}
+
+// tracer returns the tracer configured for g, or nil if tracing is disabled
+func (g *Gogm) tracer() Tracer {
+	if g.config == nil {
+		return nil
+	}
+
+	if g.config.Tracer != nil {
+		return g.config.Tracer
+	}
+
+	if g.config.OpentracingEnabled {
+		return opentracingTracer{}
+	}
+
+	return nil
+}
+
+// metrics returns the metrics recorder configured for g, or nil
+func (g *Gogm) metrics() MetricsRecorder {
+	if g.config == nil {
+		return nil
+	}
+
+	return g.config.Metrics
+}
+
+// startSpan starts a span for a session operation. The operation name is added to the returned
+// context so statements run on its behalf can be attributed to it. The returned span is never nil
+func (s *SessionV2Impl) startSpan(ctx context.Context, operation string, attrs ...Attribute) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if operationFromContext(ctx) == "" {
+		ctx = context.WithValue(ctx, operationContextKey{}, operation)
+	}
+
+	tracer := s.gogm.tracer()
+	if tracer == nil {
+		return ctx, noopSpan{}
+	}
+
+	attrs = append([]Attribute{
+		Attr(AttributeDbSystem, dbSystemNeo4j),
+		Attr(AttributeDbName, s.conf.DatabaseName),
+		Attr(AttributeDbOperation, operationFromContext(ctx)),
+	}, attrs...)
+
+	ctx, span := tracer.Start(ctx, "gogm.SessionV2Impl."+operation, attrs...)
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// traceParams adds params, redacted with the configured ParamRedactor, to span
+func (s *SessionV2Impl) traceParams(span Span, params map[string]interface{}) {
+	if s.gogm.tracer() == nil || s.gogm.config.ParamRedactor == nil {
+		return
+	}
+
+	span.SetAttributes(Attr(AttributeDbParams, s.gogm.config.ParamRedactor.RedactParams(params)))
+}
+
+// recordQuery hands a finished statement to the metrics recorder and the query observers
+func (s *SessionV2Impl) recordQuery(ctx context.Context, metric QueryMetric, params map[string]interface{}) {
+	metric.Operation = operationFromContext(ctx)
+	metric.Database = s.conf.DatabaseName
+
+	if metrics := s.gogm.metrics(); metrics != nil {
+		metrics.RecordQuery(ctx, metric)
+	}
+
+	s.observeQuery(ctx, metric, params)
+}
+
+// recordSessions hands a change in open sessions to the configured metrics recorder and the gogm stats
+func (s *SessionV2Impl) recordSessions(delta int64) {
+	s.gogm.stats.addSessions(delta)
+	if metrics := s.gogm.metrics(); metrics != nil {
+		metrics.RecordSessions(context.Background(), s.conf.DatabaseName, delta)
+	}
+}
+
+// labelOf returns the label of the node type obj points to, or an empty string if it is unknown
+func labelOf(obj interface{}) string {
+	if obj == nil {
+		return ""
+	}
+
+	label, err := getTypeName(reflect.TypeOf(obj))
+	if err != nil {
+		return ""
+	}
+
+	return label
+}
+
+// observedTx reports every statement run through it to the configured metrics recorder
+type observedTx struct {
+	neo4j.Transaction
+	ctx     context.Context
+	sess    *SessionV2Impl
+	results []*observedResult
+}
+
+// observe wraps tx so the statements run with it are measured. flush has to be called once the work is done
+func (s *SessionV2Impl) observe(ctx context.Context, tx neo4j.Transaction) *observedTx {
+	return &observedTx{
+		Transaction: tx,
+		ctx:         ctx,
+		sess:        s,
+	}
+}
+
+func (o *observedTx) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	start := time.Now()
+	res, err := o.Transaction.Run(cypher, params)
+	if err != nil {
+		o.sess.recordQuery(o.ctx, QueryMetric{
+			Statement: cypher,
+			Duration:  time.Since(start),
+			Err:       err,
+		}, params)
+		return nil, err
+	}
+
+	observed := &observedResult{
+		Result:    res,
+		tx:        o,
+		statement: cypher,
+		params:    params,
+		start:     start,
+	}
+	o.results = append(o.results, observed)
+	return observed, nil
+}
+
+// flush records the statements whose results were not read to the end
+func (o *observedTx) flush() {
+	for _, res := range o.results {
+		if res.done {
+			continue
+		}
+
+		for res.Result.Next() {
+			res.rows++
+		}
+		sum, err := res.Result.Consume()
+		res.finish(sum, err)
+	}
+	o.results = nil
+}
+
+// observedResult counts the records read from a result and records the statement once it is exhausted
+type observedResult struct {
+	neo4j.Result
+	tx        *observedTx
+	statement string
+	params    map[string]interface{}
+	start     time.Time
+	rows      int
+	done      bool
+}
+
+func (r *observedResult) Next() bool {
+	if r.Result.Next() {
+		r.rows++
+		return true
+	}
+
+	r.complete(r.Result.Err())
+	return false
+}
+
+func (r *observedResult) NextRecord(record **neo4j.Record) bool {
+	if r.Result.NextRecord(record) {
+		r.rows++
+		return true
+	}
+
+	r.complete(r.Result.Err())
+	return false
+}
+
+func (r *observedResult) Collect() ([]*neo4j.Record, error) {
+	records, err := r.Result.Collect()
+	r.rows += len(records)
+	r.complete(err)
+	return records, err
+}
+
+func (r *observedResult) Single() (*neo4j.Record, error) {
+	record, err := r.Result.Single()
+	if err == nil {
+		r.rows++
+	}
+	r.complete(err)
+	return record, err
+}
+
+func (r *observedResult) Consume() (neo4j.ResultSummary, error) {
+	for r.Result.Next() {
+		r.rows++
+	}
+
+	sum, err := r.Result.Consume()
+	r.finish(sum, err)
+	return sum, err
+}
+
+// complete records the statement once all records were read, fetching the summary for the server timings
+func (r *observedResult) complete(err error) {
+	if r.done {
+		return
+	}
+
+	var sum neo4j.ResultSummary
+	if err == nil {
+		sum, err = r.Result.Consume()
+	}
+	r.finish(sum, err)
+}
+
+func (r *observedResult) finish(sum neo4j.ResultSummary, err error) {
+	if r.done {
+		return
+	}
+
+	r.done = true
+	metric := QueryMetric{
+		Statement: r.statement,
+		Duration:  time.Since(r.start),
+		Rows:      r.rows,
+		Err:       err,
+	}
+	if sum != nil {
+		metric.ResultAvailableAfter = sum.ResultAvailableAfter()
+		metric.ResultConsumedAfter = sum.ResultConsumedAfter()
+	}
+
+	r.tx.sess.recordQuery(r.tx.ctx, metric, r.params)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute)    {}
+func (noopSpan) AddEvent(string, ...Attribute) {}
+func (noopSpan) RecordError(error)             {}
+func (noopSpan) End()                          {}
+
+// opentracingTracer backs Tracer with the global opentracing tracer, it is used when OpentracingEnabled is set
+type opentracingTracer struct{}
+
+func (opentracingTracer) Start(ctx context.Context, operation string, attrs ...Attribute) (context.Context, Span) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, operation)
+	ext.DBType.Set(span, dbSystemNeo4j)
+	otSpan := opentracingSpan{span: span}
+	otSpan.SetAttributes(attrs...)
+	return ctx, otSpan
+}
+
+type opentracingSpan struct {
+	span opentracing.Span
+}
+
+func (o opentracingSpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		o.span.SetTag(attr.Key, attr.Value)
+	}
+}
+
+func (o opentracingSpan) AddEvent(name string, attrs ...Attribute) {
+	fields := []otlog.Field{otlog.String("event", name)}
+	for _, attr := range attrs {
+		fields = append(fields, otlog.Object(attr.Key, attr.Value))
+	}
+	o.span.LogFields(fields...)
+}
+
+func (o opentracingSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	ext.LogError(o.span, err)
+}
+
+func (o opentracingSpan) End() {
+	o.span.Finish()
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingTracer keeps every span it starts
+type recordingTracer struct {
+	spans []*recordingSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, operation string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordingSpan{name: operation, attrs: attrs}
+	r.spans = append(r.spans, span)
+	return ctx, span
+}
+
+type recordingSpan struct {
+	name   string
+	attrs  []Attribute
+	events []string
+	errs   []error
+	ended  bool
+}
+
+func (r *recordingSpan) SetAttributes(attrs ...Attribute) {
+	r.attrs = append(r.attrs, attrs...)
+}
+
+func (r *recordingSpan) AddEvent(name string, attrs ...Attribute) {
+	r.events = append(r.events, name)
+}
+
+func (r *recordingSpan) RecordError(err error) {
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
+func (r *recordingSpan) End() {
+	r.ended = true
+}
+
+// recordingMetrics keeps every measurement it receives
+type recordingMetrics struct {
+	queries  []QueryMetric
+	retries  []RetryMetric
+	sessions int64
+}
+
+func (r *recordingMetrics) RecordQuery(ctx context.Context, metric QueryMetric) {
+	r.queries = append(r.queries, metric)
+}
+
+func (r *recordingMetrics) RecordRetry(ctx context.Context, metric RetryMetric) {
+	r.retries = append(r.retries, metric)
+}
+
+func (r *recordingMetrics) RecordSessions(ctx context.Context, database string, delta int64) {
+	r.sessions += delta
+}
+
+// fakeResultTx returns a fakeResult with rows records from every Run
+type fakeResultTx struct {
+	neo4j.Transaction
+	statements []string
+	rows       int
+	err        error
+	summary    neo4j.ResultSummary
+}
+
+func (f *fakeResultTx) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	f.statements = append(f.statements, cypher)
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &fakeResult{remaining: f.rows, summary: f.summary}, nil
+}
+
+type fakeResult struct {
+	neo4j.Result
+	remaining int
+	summary   neo4j.ResultSummary
+}
+
+func (f *fakeResult) Next() bool {
+	if f.remaining == 0 {
+		return false
+	}
+	f.remaining--
+	return true
+}
+
+func (f *fakeResult) Err() error {
+	return nil
+}
+
+func (f *fakeResult) Consume() (neo4j.ResultSummary, error) {
+	f.remaining = 0
+	return f.summary, nil
+}
+
+func TestSessionV2Impl_StartSpan(t *testing.T) {
+	req := require.New(t)
+
+	tracer := &recordingTracer{}
+	sess := &SessionV2Impl{gogm: &Gogm{config: &Config{Tracer: tracer}}, conf: SessionConfig{DatabaseName: "db"}}
+
+	ctx, span := sess.startSpan(context.Background(), "Load", Attr(AttributeDbLabel, "Person"))
+	_, inner := sess.startSpan(ctx, "runReadOnly")
+	inner.End()
+	span.End()
+
+	req.Len(tracer.spans, 2)
+	req.Equal("gogm.SessionV2Impl.Load", tracer.spans[0].name)
+	req.Contains(tracer.spans[0].attrs, Attr(AttributeDbSystem, "neo4j"))
+	req.Contains(tracer.spans[0].attrs, Attr(AttributeDbName, "db"))
+	req.Contains(tracer.spans[0].attrs, Attr(AttributeDbLabel, "Person"))
+	// nested spans keep the outermost operation
+	req.Contains(tracer.spans[1].attrs, Attr(AttributeDbOperation, "Load"))
+	req.True(tracer.spans[1].ended)
+
+	// no tracer configured
+	sess.gogm.config.Tracer = nil
+	ctx, span = sess.startSpan(nil, "Save")
+	req.IsType(noopSpan{}, span)
+	req.Equal("Save", operationFromContext(ctx))
+}
+
+func TestObservedTx(t *testing.T) {
+	req := require.New(t)
+
+	metrics := &recordingMetrics{}
+	sess := &SessionV2Impl{gogm: &Gogm{config: &Config{Metrics: metrics}}, conf: SessionConfig{DatabaseName: "db"}}
+	ctx := context.WithValue(context.Background(), operationContextKey{}, "Load")
+
+	// fully read results are recorded as soon as they are exhausted
+	tx := sess.observe(ctx, &fakeResultTx{rows: 3})
+	res, err := tx.Run("MATCH (n) RETURN n", nil)
+	req.Nil(err)
+	for res.Next() {
+	}
+	req.Len(metrics.queries, 1)
+	req.Equal(3, metrics.queries[0].Rows)
+	req.Equal("Load", metrics.queries[0].Operation)
+	req.Equal("db", metrics.queries[0].Database)
+	req.Equal("MATCH (n) RETURN n", metrics.queries[0].Statement)
+
+	// unread results are drained and recorded by flush
+	_, err = tx.Run("CREATE (n)", nil)
+	req.Nil(err)
+	tx.flush()
+	tx.flush()
+	req.Len(metrics.queries, 2)
+	req.Equal(3, metrics.queries[1].Rows)
+
+	// failed statements are recorded with their error
+	tx = sess.observe(ctx, &fakeResultTx{err: errors.New("boom")})
+	_, err = tx.Run("MATCH (n) RETURN n", nil)
+	req.NotNil(err)
+	req.Len(metrics.queries, 3)
+	req.NotNil(metrics.queries[2].Err)
+}
@@ -21,6 +21,7 @@ package gogm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -984,6 +985,24 @@ func (integrationTest *IntegrationTestSuite) TestRelationshipWithinSingleType()
 	req.NotNil(n3.SelfBothOne)
 	req.Equal(&n3, &n3.SelfBothOne)
 
+	// every node is reached through the cycles and wired back to the same value
+	var all narcissisticTestNode
+	err = sess.LoadDepth(ctx, &all, testUuid2, DepthInfinite)
+	req.Nil(err, "Load should not fail")
+	req.Equal(testUuid3, all.SelfBothOne.UUID)
+	req.Same(&all, all.SelfBothOne.SelfBothOne)
+	req.Same(all.SelfBothMany[0], all.SelfBothOne.SelfBothMany[0])
+
+	limited := WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{MaxNodes: 2})
+	err = sess.LoadDepth(limited, &narcissisticTestNode{}, testUuid2, DepthInfinite)
+	req.True(errors.Is(err, ErrDepthLimit))
+
+	// only the whitelisted relationships are followed
+	var one narcissisticTestNode
+	err = sess.LoadDepth(WithInfiniteDepthOptions(ctx, InfiniteDepthOptions{Relationships: []string{"self_both_one"}}), &one, testUuid2, DepthInfinite)
+	req.Nil(err)
+	req.Same(&one, one.SelfBothOne.SelfBothOne)
+	req.Empty(one.SelfBothMany)
 }
 
 func testRelationshipWithinSingleType_Setup(gogm *Gogm, req *require.Assertions) {
@@ -41,6 +41,13 @@ type SessionV2 interface {
 	Close() error
 }
 
+// BookmarkedSession is implemented by sessions that report the bookmarks of their last committed transaction.
+// Sessions returned by NewSessionV2 implement it
+type BookmarkedSession interface {
+	// LastBookmarks returns the bookmarks of the last transaction committed by the session
+	LastBookmarks() []string
+}
+
 // TransactionV2 specifies functions for Neo4j ACID transactions
 type TransactionV2 interface {
 	// Rollback rolls back transaction
@@ -58,7 +65,7 @@ type ogmFunctions interface {
 	//load single object
 	Load(ctx context.Context, respObj, id interface{}) error
 
-	//load object with depth
+	//load object with depth, DepthInfinite loads everything reachable from it
 	LoadDepth(ctx context.Context, respObj, id interface{}, depth int) error
 
 	//load with depth and filter
@@ -70,7 +77,7 @@ type ogmFunctions interface {
 	//load slice of something
 	LoadAll(ctx context.Context, respObj interface{}) error
 
-	//load all of depth
+	//load all of depth, DepthInfinite loads everything reachable from them
 	LoadAllDepth(ctx context.Context, respObj interface{}, depth int) error
 
 	//load all of type with depth and filter
@@ -79,6 +86,15 @@ type ogmFunctions interface {
 	//load all with depth, filter and pagination
 	LoadAllDepthFilterPagination(ctx context.Context, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) error
 
+	//load the objects with the primary keys in ids with one query, in the order of ids
+	LoadMany(ctx context.Context, respSlice interface{}, ids []interface{}, depth int) error
+
+	//load the named relationship fields of an object that has been loaded or saved, every relationship field when none are named
+	LoadRelations(ctx context.Context, obj interface{}, fieldNames ...string) error
+
+	//load an object that has been loaded or saved again with depth, replacing its fields
+	Refresh(ctx context.Context, obj interface{}, depth int) error
+
 	//save object at default depth
 	Save(ctx context.Context, saveObj interface{}) error
 
@@ -96,6 +112,15 @@ type ogmFunctions interface {
 
 	//similar to query, but returns raw rows/cols
 	QueryRaw(ctx context.Context, query string, properties map[string]interface{}) ([][]interface{}, neo4j.ResultSummary, error)
+
+	//returns the query LoadDepthFilterPagination would run with its EXPLAIN plan, without executing it
+	ExplainLoad(ctx context.Context, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (*QueryExplanation, error)
+
+	//returns the query LoadAllDepthFilterPagination would run with its EXPLAIN plan, without executing it
+	ExplainLoadAll(ctx context.Context, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (*QueryExplanation, error)
+
+	//returns the statements SaveDepth would run for obj, without executing them
+	PlanSave(ctx context.Context, obj interface{}, depth int) ([]SaveStatement, error)
 }
 
 type TransactionWork func(tx TransactionV2) error
@@ -0,0 +1,202 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+)
+
+// idsParam is the param LoadMany binds the primary keys it loads to
+const idsParam = "ids"
+
+// LoadMany loads the objects with the primary keys in ids into respSlice with one query. The objects are in the
+// order of ids, primary keys that are not found are left out
+func (s *SessionV2Impl) LoadMany(ctx context.Context, respSlice interface{}, ids []interface{}, depth int) error {
+	ctx, span := s.startSpan(ctx, "LoadMany", Attr(AttributeDbLabel, labelOf(respSlice)))
+	defer span.End()
+
+	if len(ids) == 0 {
+		return fmt.Errorf("ids can not be empty, %w", ErrInvalidParams)
+	}
+
+	sess, err := s.routedSession(ctx, respSlice)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return err
+	}
+
+	cyp, params, err := s.loadManyQuery(scope, limits, respSlice, ids, depth)
+	if err != nil {
+		return err
+	}
+
+	err = sess.runReadOnly(ctx, cyp, params, respSlice, limits.nodeLimit())
+	if err != nil {
+		return err
+	}
+
+	return orderByIds(s.gogm, respSlice, ids)
+}
+
+// loadManyQuery generates the cypher and params LoadMany runs.
+// The query is limited to the nodes in scope, which may be nil. Limits is only set for DepthInfinite
+func (s *SessionV2Impl) loadManyQuery(scope *tenantScope, limits *depthLimits, respSlice interface{}, ids []interface{}, depth int) (string, map[string]interface{}, error) {
+	respType := reflect.TypeOf(respSlice)
+	if respType == nil || respType.Kind() != reflect.Ptr || respType.Elem().Kind() != reflect.Slice {
+		return "", nil, fmt.Errorf("respSlice must be a pointer to a slice, instead it is %T, %w", respSlice, ErrInvalidParams)
+	}
+
+	respType = respType.Elem().Elem()
+	if respType.Kind() == reflect.Ptr {
+		respType = respType.Elem()
+	}
+
+	//get the type name -- this maps directly to the label
+	respObjName := respType.Name()
+
+	varName := "n"
+
+	build := func() (string, error) {
+		var query dsl.Cypher
+		var err error
+
+		isGraphId := s.gogm.pkStrategy.StrategyName == DefaultPrimaryKeyStrategy.StrategyName
+		field := s.gogm.pkStrategy.DBName
+		if limits != nil {
+			// unlimited depth can not be expressed by the load strategies
+			query, err = infiniteDepthLoadByKey(scope, limits, varName, respObjName, field, idsParam, isGraphId, true, scope.filter(varName, nil, false), nil)
+			if err != nil {
+				return "", err
+			}
+			return query.ToCypher()
+		}
+
+		//make the query based off of the load strategy
+		switch s.gogm.config.LoadStrategy {
+		case PATH_LOAD_STRATEGY:
+			query, err = pathLoadStrategyByKey(varName, respObjName, field, idsParam, isGraphId, true, depth, scope.filter(varName, nil, true))
+		case SCHEMA_LOAD_STRATEGY:
+			query, err = schemaLoadStrategyByKey(s.gogm, scope, varName, respObjName, field, idsParam, isGraphId, true, depth, scope.filter(varName, nil, false))
+		case SUBQUERY_LOAD_STRATEGY:
+			query, err = subqueryLoadStrategyByKey(s.gogm, scope, varName, respObjName, field, idsParam, isGraphId, true, depth, scope.filter(varName, nil, false), nil)
+		default:
+			return "", errors.New("unknown load strategy")
+		}
+		if err != nil {
+			return "", err
+		}
+
+		return query.ToCypher()
+	}
+
+	cyp, err := s.gogm.cachedLoadQuery(s.gogm.config.LoadStrategy, scope, limits, respObjName, loadByIds, depth, nil, nil, build)
+	if err != nil {
+		return "", nil, err
+	}
+
+	params := limits.params(scope.params(map[string]interface{}{
+		idsParam: ids,
+	}))
+
+	return cyp, params, nil
+}
+
+// orderByIds puts the objects in respSlice in the order of ids. Objects without one of the ids, like related nodes
+// of the same label, are dropped
+func orderByIds(gogm *Gogm, respSlice interface{}, ids []interface{}) error {
+	slice := reflect.ValueOf(respSlice).Elem()
+
+	byId := make(map[interface{}]reflect.Value, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i)
+		node := elem
+		if node.Kind() == reflect.Ptr {
+			if node.IsNil() {
+				continue
+			}
+			node = node.Elem()
+		}
+
+		pk, ok := primaryKeyOf(gogm, node)
+		if !ok {
+			continue
+		}
+		byId[primaryKeyKey(pk)] = elem
+	}
+
+	ordered := reflect.MakeSlice(slice.Type(), 0, len(byId))
+	seen := make(map[interface{}]bool, len(ids))
+	for _, id := range ids {
+		key := primaryKeyKey(id)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if elem, ok := byId[key]; ok {
+			ordered = reflect.Append(ordered, elem)
+		}
+	}
+
+	if ordered.Len() == 0 {
+		return ErrNotFound
+	}
+
+	slice.Set(ordered)
+	return nil
+}
+
+// primaryKeyKey normalizes a primary key so the same key compares equal however it was typed. Graph ids can be
+// passed as any integer type but are decoded as int64
+func primaryKeyKey(pk interface{}) interface{} {
+	val := reflect.ValueOf(pk)
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return nil
+		}
+		return primaryKeyKey(val.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return val.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(val.Uint())
+	case reflect.Invalid:
+		return nil
+	}
+
+	if !val.Type().Comparable() {
+		return fmt.Sprint(pk)
+	}
+
+	return pk
+}
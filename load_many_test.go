@@ -0,0 +1,164 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimaryKeyCondition(t *testing.T) {
+	req := require.New(t)
+
+	where, err := primaryKeyCondition("n", "uuid", "idprm", false, false, nil)
+	req.Nil(err)
+	req.EqualValues("n.uuid = $idprm", where)
+
+	where, err = primaryKeyCondition("n", "id", "ids", true, true, nil)
+	req.Nil(err)
+	req.EqualValues("ID(n) IN $ids", where)
+
+	// an OR in the constraints can not match nodes without the key
+	constraints := dsl.C(&dsl.ConditionConfig{Name: "n", Field: "a", ConditionOperator: dsl.EqualToOperator, Check: dsl.ParamString("$a")}).
+		Or(&dsl.ConditionConfig{Name: "n", Field: "b", ConditionOperator: dsl.EqualToOperator, Check: dsl.ParamString("$b")})
+	where, err = primaryKeyCondition("n", "uuid", "idprm", false, false, constraints)
+	req.Nil(err)
+	req.EqualValues("(n.a = $a OR n.b = $b) AND n.uuid = $idprm", where)
+}
+
+func TestPathLoadStrategyByKey(t *testing.T) {
+	req := require.New(t)
+
+	one, err := PathLoadStrategyOne("n", "b", "uuid", "idprm", false, 1, nil)
+	req.Nil(err)
+	cyp, err := one.ToCypher()
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() WHERE n.uuid = $idprm RETURN p", cyp)
+
+	many, err := pathLoadStrategyByKey("n", "b", "uuid", "ids", false, true, 1, nil)
+	req.Nil(err)
+	cyp, err = many.ToCypher()
+	req.Nil(err)
+	req.Equal("MATCH p=(n:b)-[*0..1]-() WHERE n.uuid IN $ids RETURN p", cyp)
+
+	many, err = pathLoadStrategyByKey("n", "b", "id", "ids", true, true, 0, nil)
+	req.Nil(err)
+	cyp, err = many.ToCypher()
+	req.Nil(err)
+	req.Equal("MATCH p=(n:b) WHERE ID(n) IN $ids RETURN p", cyp)
+}
+
+func TestSessionV2Impl_LoadManyQuery(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	sess := &SessionV2Impl{gogm: g}
+	ids := []interface{}{"b1", "b2"}
+	prefixes := map[LoadStrategy]string{
+		PATH_LOAD_STRATEGY:     "MATCH p=(n:b)-[*0..1]-() WHERE n.uuid IN $ids RETURN p",
+		SCHEMA_LOAD_STRATEGY:   "MATCH (n:b) WHERE n.uuid IN $ids RETURN n , [[(n)",
+		SUBQUERY_LOAD_STRATEGY: "MATCH (n:b) WHERE n.uuid IN $ids WITH n CALL { WITH n MATCH (n)",
+	}
+
+	for _, strategy := range []LoadStrategy{PATH_LOAD_STRATEGY, SCHEMA_LOAD_STRATEGY, SUBQUERY_LOAD_STRATEGY} {
+		g.config.LoadStrategy = strategy
+		cyp, params, err := sess.loadManyQuery(nil, nil, &[]*b{}, ids, 1)
+		req.Nil(err)
+		req.True(strings.HasPrefix(cyp, prefixes[strategy]), cyp)
+		req.Equal(map[string]interface{}{"ids": ids}, params)
+	}
+
+	// the load by ids is cached apart from the load all and load one of the same label
+	g.config.LoadStrategy = SCHEMA_LOAD_STRATEGY
+	all, _, err := sess.loadAllQuery(nil, nil, &[]*b{}, 1, nil, nil, nil)
+	req.Nil(err)
+	one, _, err := sess.loadQuery(nil, nil, &b{}, "b1", 1, nil, nil, nil)
+	req.Nil(err)
+	many, _, err := sess.loadManyQuery(nil, nil, &[]*b{}, ids, 1)
+	req.Nil(err)
+	req.NotEqual(all, many)
+	req.NotEqual(one, many)
+
+	limits, err := g.depthLimits(context.Background(), DepthInfinite)
+	req.Nil(err)
+	cyp, params, err := sess.loadManyQuery(nil, limits, &[]b{}, ids, DepthInfinite)
+	req.Nil(err)
+	req.True(strings.HasPrefix(cyp, "MATCH (n:b) WHERE n.uuid IN $ids WITH n CALL { WITH n OPTIONAL MATCH (n)-[:multib|"), cyp)
+	req.True(strings.HasSuffix(cyp, "RETURN n AS gogm_root, gogm_nodes, gogm_rels"), cyp)
+	req.Equal(ids, params["ids"])
+	req.Equal(limits.maxNodes, params[maxNodesParam])
+
+	_, _, err = sess.loadManyQuery(nil, nil, &b{}, ids, 1)
+	req.True(errors.Is(err, ErrInvalidParams))
+}
+
+func TestSessionV2Impl_LoadMany(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	node := func(id int64, uuid string) neo4j.Node {
+		return neo4j.Node{Id: id, Labels: []string{"b"}, Props: map[string]interface{}{"uuid": uuid}}
+	}
+	tx := &recordsTx{records: []*neo4j.Record{
+		{Keys: []string{rootColumn}, Values: []interface{}{node(1, "b1")}},
+		{Keys: []string{rootColumn}, Values: []interface{}{node(3, "b3")}},
+		{Keys: []string{rootColumn}, Values: []interface{}{node(2, "b2")}},
+	}}
+	sess := &SessionV2Impl{gogm: g, tx: tx}
+	g.config.LoadStrategy = SCHEMA_LOAD_STRATEGY
+
+	// objects come back in the order of the ids, without the ones that were not asked for
+	var ptrs []*b
+	req.Nil(sess.LoadMany(ctx, &ptrs, []interface{}{"b2", "b4", "b1", "b2"}, 0))
+	req.Len(tx.statements, 1)
+	req.Equal([]interface{}{"b2", "b4", "b1", "b2"}, tx.params[0]["ids"])
+	req.Len(ptrs, 2)
+	req.Equal("b2", ptrs[0].UUID)
+	req.Equal("b1", ptrs[1].UUID)
+
+	var structs []b
+	req.Nil(sess.LoadMany(ctx, &structs, []interface{}{"b3", "b1"}, 0))
+	req.Len(structs, 2)
+	req.Equal("b3", structs[0].UUID)
+	req.Equal("b1", structs[1].UUID)
+
+	req.True(errors.Is(sess.LoadMany(ctx, &ptrs, []interface{}{"b5"}, 0), ErrNotFound))
+	req.True(errors.Is(sess.LoadMany(ctx, &ptrs, nil, 0), ErrInvalidParams))
+}
+
+func TestPrimaryKeyKey(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal(int64(5), primaryKeyKey(5))
+	req.Equal(int64(5), primaryKeyKey(int32(5)))
+	req.Equal(int64(5), primaryKeyKey(uint(5)))
+	req.Equal(int64(5), primaryKeyKey(int64Ptr(5)))
+	req.Equal("b1", primaryKeyKey("b1"))
+	req.Nil(primaryKeyKey(nil))
+}
@@ -0,0 +1,359 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// LoadRelations loads the relationship fields in fieldNames of obj, which has to have been loaded or saved before.
+// Every relationship field is loaded when no field is named. The related nodes are loaded at depth 1 and replace what
+// the fields held, the other fields of obj are left alone. The LoadMap of obj is updated for the fields, so the next
+// save only removes the relationships that were removed after they were loaded
+func (s *SessionV2Impl) LoadRelations(ctx context.Context, obj interface{}, fieldNames ...string) error {
+	ctx, span := s.startSpan(ctx, "LoadRelations", Attr(AttributeDbLabel, labelOf(obj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	val, config, id, err := loadedNode(s.gogm, obj)
+	if err != nil {
+		return err
+	}
+
+	rels, err := relationFields(config, fieldNames)
+	if err != nil {
+		return err
+	}
+
+	cyp, params, err := loadRelationsQuery(s.gogm, scope, config.Label, id, rels)
+	if err != nil {
+		return err
+	}
+
+	fresh := reflect.New(val.Elem().Type())
+	if err = sess.runReadOnly(ctx, cyp, params, fresh.Interface(), 0); err != nil {
+		return err
+	}
+
+	return mergeRelations(s.gogm, val, fresh, rels)
+}
+
+// Refresh loads obj, which has to have been loaded or saved before, again with depth. Every field and the LoadMap of
+// obj are replaced with what is in the database, so changes to obj that were not saved are lost
+func (s *SessionV2Impl) Refresh(ctx context.Context, obj interface{}, depth int) error {
+	ctx, span := s.startSpan(ctx, "Refresh", Attr(AttributeDbLabel, labelOf(obj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return err
+	}
+
+	val, _, id, err := loadedNode(s.gogm, obj)
+	if err != nil {
+		return err
+	}
+
+	fresh := reflect.New(val.Elem().Type())
+	cyp, params, err := s.loadQuery(scope, limits, fresh.Interface(), id, depth, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = sess.runReadOnly(ctx, cyp, params, fresh.Interface(), limits.nodeLimit()); err != nil {
+		return err
+	}
+
+	val.Elem().Set(fresh.Elem())
+	return repoint(s.gogm, val, fresh, nil)
+}
+
+// loadedNode returns obj as a reflect value with its struct config and primary key. Obj has to be a pointer to a
+// node that has been loaded or saved
+func loadedNode(gogm *Gogm, obj interface{}) (reflect.Value, structDecoratorConfig, interface{}, error) {
+	val := reflect.ValueOf(obj)
+	if !val.IsValid() || val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, structDecoratorConfig{}, nil, fmt.Errorf("obj must be a pointer to a struct, instead it is %T, %w", obj, ErrInvalidParams)
+	}
+
+	label := val.Elem().Type().Name()
+	raw, ok := gogm.mappedTypes.Get(label)
+	if !ok {
+		return reflect.Value{}, structDecoratorConfig{}, nil, fmt.Errorf("struct config not found type (%s), %w", label, ErrInvalidParams)
+	}
+
+	config, ok := raw.(structDecoratorConfig)
+	if !ok {
+		return reflect.Value{}, structDecoratorConfig{}, nil, errors.New("unable to cast into struct decorator config")
+	}
+
+	if !config.IsVertex {
+		return reflect.Value{}, structDecoratorConfig{}, nil, fmt.Errorf("%s is an edge, only nodes can be loaded, %w", label, ErrInvalidParams)
+	}
+
+	pk, ok := primaryKeyOf(gogm, val.Elem())
+	if !ok {
+		return reflect.Value{}, structDecoratorConfig{}, nil, fmt.Errorf("%s has no %s, it has to be loaded or saved first, %w", label, gogm.pkStrategy.FieldName, ErrInvalidParams)
+	}
+
+	return val, config, pk, nil
+}
+
+// primaryKeyOf returns the primary key of the node struct in val, false when it is not set
+func primaryKeyOf(gogm *Gogm, val reflect.Value) (interface{}, bool) {
+	if gogm.pkStrategy.StrategyName == DefaultPrimaryKeyStrategy.StrategyName {
+		id := val.FieldByName(DefaultPrimaryKeyStrategy.FieldName)
+		if !id.IsValid() || id.IsNil() {
+			return nil, false
+		}
+		return id.Elem().Interface(), true
+	}
+
+	pk := val.FieldByName(gogm.pkStrategy.FieldName)
+	if !pk.IsValid() || pk.IsZero() {
+		return nil, false
+	}
+
+	return pk.Interface(), true
+}
+
+// relationFields returns the configs of the relationship fields in fieldNames, every relationship field of config
+// when fieldNames is empty
+func relationFields(config structDecoratorConfig, fieldNames []string) ([]decoratorConfig, error) {
+	var rels []decoratorConfig
+	if len(fieldNames) == 0 {
+		for _, field := range config.Fields {
+			if !field.Ignore && field.Relationship != "" {
+				rels = append(rels, field)
+			}
+		}
+	} else {
+		seen := map[string]bool{}
+		for _, name := range fieldNames {
+			field, ok := config.Fields[name]
+			if !ok || field.Ignore || field.Relationship == "" {
+				return nil, fmt.Errorf("%s is not a relationship field of %s, %w", name, config.Label, ErrInvalidParams)
+			}
+
+			if !seen[name] {
+				seen[name] = true
+				rels = append(rels, field)
+			}
+		}
+	}
+
+	sortRelationships(rels)
+
+	return rels, nil
+}
+
+// loadRelationsQuery generates the query that loads the node of label with the primary key id and the nodes related
+// to it through rels
+func loadRelationsQuery(gogm *Gogm, scope *tenantScope, label string, id interface{}, rels []decoratorConfig) (string, map[string]interface{}, error) {
+	varName := "n"
+	paramName := "idprm"
+	isGraphId := gogm.pkStrategy.StrategyName == DefaultPrimaryKeyStrategy.StrategyName
+
+	builder, err := matchRoot(varName, label, gogm.pkStrategy.DBName, paramName, isGraphId, false, scope.filter(varName, nil, false))
+	if err != nil {
+		return "", nil, err
+	}
+
+	comprehensions := make([]string, 0, len(rels))
+	for i, rel := range rels {
+		comprehension, err := listComprehension(gogm, scope, varName, label, rel, i+1, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		comprehensions = append(comprehensions, comprehension)
+	}
+
+	cyp, err := builder.Cypher(fmt.Sprintf("RETURN %s AS %s, [%s]", varName, rootColumn, strings.Join(comprehensions, ", "))).ToCypher()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return cyp, scope.params(map[string]interface{}{paramName: id}), nil
+}
+
+// mergeRelations moves the fields in rels and their part of the LoadMap from fresh, a copy of node decoded from the
+// database, to node
+func mergeRelations(gogm *Gogm, node, fresh reflect.Value, rels []decoratorConfig) error {
+	for _, rel := range rels {
+		node.Elem().FieldByName(rel.FieldName).Set(fresh.Elem().FieldByName(rel.FieldName))
+	}
+
+	loadMapVal := node.Elem().FieldByName(loadMapField)
+	if loadMapVal.IsNil() {
+		loadMapVal.Set(reflect.ValueOf(map[string]*RelationConfig{}))
+	}
+
+	loadMap, ok := loadMapVal.Interface().(map[string]*RelationConfig)
+	if !ok {
+		return fmt.Errorf("unable to cast conf to [map[string]*RelationConfig], %w", ErrInternal)
+	}
+
+	freshMap, _ := fresh.Elem().FieldByName(loadMapField).Interface().(map[string]*RelationConfig)
+	for _, rel := range rels {
+		if conf, ok := freshMap[rel.FieldName]; ok {
+			loadMap[rel.FieldName] = conf
+		} else {
+			delete(loadMap, rel.FieldName)
+		}
+	}
+
+	return repoint(gogm, node, fresh, rels)
+}
+
+// repointer points the relationships of a graph that lead to old at node instead
+type repointer struct {
+	gogm    *Gogm
+	node    reflect.Value
+	old     reflect.Value
+	visited map[uintptr]bool
+}
+
+// repoint walks the nodes reachable through the relationship fields in rels of node, every relationship field when
+// rels is nil, and points every relationship to old at node. Each node and edge is walked once, so cycles end
+func repoint(gogm *Gogm, node, old reflect.Value, rels []decoratorConfig) error {
+	r := &repointer{
+		gogm:    gogm,
+		node:    node,
+		old:     old,
+		visited: map[uintptr]bool{node.Pointer(): true},
+	}
+
+	return r.walk(node, rels)
+}
+
+// walk repoints the relationship fields in rels of current
+func (r *repointer) walk(current reflect.Value, rels []decoratorConfig) error {
+	if rels == nil {
+		var err error
+		rels, err = getRelationshipsForLabel(r.gogm, current.Elem().Type().Name())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, rel := range rels {
+		field := current.Elem().FieldByName(rel.FieldName)
+
+		var related []reflect.Value
+		if field.Kind() == reflect.Slice {
+			for i := 0; i < field.Len(); i++ {
+				related = append(related, field.Index(i))
+			}
+		} else {
+			related = append(related, field)
+		}
+
+		for _, relVal := range related {
+			if relVal.Kind() != reflect.Ptr || relVal.IsNil() {
+				continue
+			}
+
+			var err error
+			if rel.UsesEdgeNode {
+				err = r.walkEdge(relVal)
+			} else if relVal.Pointer() == r.old.Pointer() {
+				relVal.Set(r.node)
+			} else {
+				err = r.visit(relVal)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkEdge repoints the start and end of a special edge
+func (r *repointer) walkEdge(edgeVal reflect.Value) error {
+	if r.visited[edgeVal.Pointer()] {
+		return nil
+	}
+	r.visited[edgeVal.Pointer()] = true
+
+	edge, ok := edgeVal.Interface().(Edge)
+	if !ok {
+		return fmt.Errorf("%T does not implement Edge, %w", edgeVal.Interface(), ErrInternal)
+	}
+
+	ends := []struct {
+		get func() interface{}
+		set func(interface{}) error
+	}{
+		{get: edge.GetStartNode, set: edge.SetStartNode},
+		{get: edge.GetEndNode, set: edge.SetEndNode},
+	}
+
+	for _, end := range ends {
+		endVal := reflect.ValueOf(end.get())
+		if !endVal.IsValid() || endVal.Kind() != reflect.Ptr || endVal.IsNil() {
+			continue
+		}
+
+		if endVal.Pointer() == r.old.Pointer() {
+			if err := end.set(r.node.Interface()); err != nil {
+				return fmt.Errorf("failed to set node of edge %T, %w", edgeVal.Interface(), err)
+			}
+		} else if err := r.visit(endVal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visit walks next unless it has been walked already
+func (r *repointer) visit(next reflect.Value) error {
+	if r.visited[next.Pointer()] {
+		return nil
+	}
+	r.visited[next.Pointer()] = true
+
+	return r.walk(next, nil)
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// recordsTx answers every query with records
+type recordsTx struct {
+	neo4j.Transaction
+	statements []string
+	params     []map[string]interface{}
+	records    []*neo4j.Record
+}
+
+func (r *recordsTx) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	r.statements = append(r.statements, cypher)
+	r.params = append(r.params, params)
+	return &recordsResult{records: r.records}, nil
+}
+
+func TestLoadRelationsQuery(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	raw, ok := g.mappedTypes.Get("b")
+	req.True(ok)
+	config := raw.(structDecoratorConfig)
+
+	rels, err := relationFields(config, []string{"Multi", "Single", "Multi"})
+	req.Nil(err)
+	req.Len(rels, 2)
+	req.Equal("Multi", rels[0].FieldName)
+	req.Equal("Single", rels[1].FieldName)
+
+	cyp, params, err := loadRelationsQuery(g, nil, "b", "b1", rels)
+	req.Nil(err)
+	req.Equal("MATCH (n:b) WHERE n.uuid = $idprm RETURN n AS gogm_root, "+
+		"[[(n)-[r_m_1:multib]->(n_a_1:a) | [r_m_1, n_a_1]], [(n)-[r_t_2:test_rel]->(n_a_2:a) | [r_t_2, n_a_2]]]", cyp)
+	req.Equal(map[string]interface{}{"idprm": "b1"}, params)
+
+	// related nodes are scoped to the tenant
+	scope := &tenantScope{mode: TenancyLabel, tenant: "acme", label: "`Tenant_acme`"}
+	cyp, _, err = loadRelationsQuery(g, scope, "b", "b1", rels[:1])
+	req.Nil(err)
+	req.Equal("MATCH (n:b) WHERE (n:`Tenant_acme`) AND n.uuid = $idprm RETURN n AS gogm_root, "+
+		"[[(n)-[r_m_1:multib]->(n_a_1:a) WHERE n_a_1:`Tenant_acme` | [r_m_1, n_a_1]]]", cyp)
+
+	// every relationship field when none are named
+	rels, err = relationFields(config, nil)
+	req.Nil(err)
+	req.Len(rels, 5)
+
+	_, err = relationFields(config, []string{"TestField"})
+	req.True(errors.Is(err, ErrInvalidParams))
+	_, err = relationFields(config, []string{"Unknown"})
+	req.True(errors.Is(err, ErrInvalidParams))
+}
+
+func TestLoadedNode(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	_, _, _, err = loadedNode(g, b{})
+	req.True(errors.Is(err, ErrInvalidParams))
+	_, _, _, err = loadedNode(g, (*b)(nil))
+	req.True(errors.Is(err, ErrInvalidParams))
+	_, _, _, err = loadedNode(g, &c{})
+	req.True(errors.Is(err, ErrInvalidParams))
+
+	// nodes that have not been loaded or saved can not be found again
+	_, _, _, err = loadedNode(g, &b{})
+	req.True(errors.Is(err, ErrInvalidParams))
+
+	node := &b{}
+	node.UUID = "b1"
+	_, config, id, err := loadedNode(g, node)
+	req.Nil(err)
+	req.Equal("b", config.Label)
+	req.Equal("b1", id)
+}
+
+func TestSessionV2Impl_LoadRelations(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	a1 := neo4j.Node{Id: 1, Labels: []string{"a"}, Props: map[string]interface{}{"uuid": "a1"}}
+	a2 := neo4j.Node{Id: 2, Labels: []string{"a"}, Props: map[string]interface{}{"uuid": "a2"}}
+	b1 := neo4j.Node{Id: 3, Labels: []string{"b"}, Props: map[string]interface{}{"uuid": "b1", "test_field": "from the database"}}
+	tx := &recordsTx{records: []*neo4j.Record{{
+		Keys: []string{rootColumn, "rels"},
+		Values: []interface{}{b1, []interface{}{[]interface{}{
+			[]interface{}{neo4j.Relationship{Id: 10, StartId: 3, EndId: 1, Type: "multib"}, a1},
+			[]interface{}{neo4j.Relationship{Id: 11, StartId: 3, EndId: 2, Type: "multib"}, a2},
+		}}},
+	}}}
+	sess := &SessionV2Impl{gogm: g, tx: tx}
+
+	// loaded at depth 0, with a relationship that is not being loaded
+	single := &a{}
+	single.UUID = "a3"
+	node := &b{TestField: "changed", Single: single}
+	node.Id = int64Ptr(3)
+	node.UUID = "b1"
+
+	req.Nil(sess.LoadRelations(ctx, node, "Multi"))
+	req.Len(tx.statements, 1)
+	req.Equal("b1", tx.params[0]["idprm"])
+
+	// only the relationship is loaded
+	req.Equal("changed", node.TestField)
+	req.Same(single, node.Single)
+	req.Len(node.Multi, 2)
+	req.ElementsMatch([]string{"a1", "a2"}, []string{node.Multi[0].UUID, node.Multi[1].UUID})
+	for _, related := range node.Multi {
+		req.Len(related.MultiA, 1)
+		req.Same(node, related.MultiA[0])
+	}
+	req.ElementsMatch([]int64{1, 2}, node.LoadMap["Multi"].Ids)
+	req.NotContains(node.LoadMap, "Single")
+
+	// a save after removing a loaded relationship deletes it
+	kept, removed := node.Multi[0], node.Multi[1]
+	node.Multi = []*a{kept}
+	plan, err := g.PlanSave(ctx, node, 1)
+	req.Nil(err)
+	var deleted []interface{}
+	for _, statement := range plan {
+		if strings.Contains(statement.Cypher, "DELETE e") {
+			deleted = statement.Params["rows"].([]interface{})
+		}
+	}
+	req.Equal([]interface{}{map[string]interface{}{"startNodeId": int64(3), "endNodeIds": []int64{*removed.Id}}}, deleted)
+
+	// relationships that are gone are removed from the LoadMap
+	tx.records = []*neo4j.Record{{Keys: []string{rootColumn, "rels"}, Values: []interface{}{b1, []interface{}{[]interface{}{}}}}}
+	req.Nil(sess.LoadRelations(ctx, node, "Multi"))
+	req.Empty(node.Multi)
+	req.NotContains(node.LoadMap, "Multi")
+
+	req.True(errors.Is(sess.LoadRelations(ctx, node, "TestField"), ErrInvalidParams))
+}
+
+func TestSessionV2Impl_LoadRelations_SpecialEdge(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	a1 := neo4j.Node{Id: 1, Labels: []string{"a"}, Props: map[string]interface{}{"uuid": "a1"}}
+	b1 := neo4j.Node{Id: 3, Labels: []string{"b"}, Props: map[string]interface{}{"uuid": "b1"}}
+	tx := &recordsTx{records: []*neo4j.Record{{
+		Keys: []string{rootColumn, "rels"},
+		Values: []interface{}{a1, []interface{}{[]interface{}{
+			[]interface{}{neo4j.Relationship{Id: 10, StartId: 1, EndId: 3, Type: "special_single", Props: map[string]interface{}{"test": "edge"}}, b1},
+		}}},
+	}}}
+	sess := &SessionV2Impl{gogm: g, tx: tx}
+
+	node := &a{}
+	node.UUID = "a1"
+	req.Nil(sess.LoadRelations(context.Background(), node, "SingleSpecA"))
+	req.NotNil(node.SingleSpecA)
+	req.Equal("edge", node.SingleSpecA.Test)
+	req.Same(node, node.SingleSpecA.Start)
+	req.Equal("b1", node.SingleSpecA.End.UUID)
+	req.Same(node.SingleSpecA, node.SingleSpecA.End.SingleSpec)
+}
+
+func TestSessionV2Impl_Refresh(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	// f1 -> f2 -> f1
+	f1 := neo4j.Node{Id: 1, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f1"}}
+	f2 := neo4j.Node{Id: 2, Labels: []string{"f"}, Props: map[string]interface{}{"uuid": "f2"}}
+	tx := &recordsTx{records: []*neo4j.Record{{
+		Keys: []string{rootColumn, "gogm_nodes", "gogm_rels"},
+		Values: []interface{}{f1, []interface{}{f2}, []interface{}{
+			neo4j.Relationship{Id: 10, StartId: 1, EndId: 2, Type: "test"},
+			neo4j.Relationship{Id: 11, StartId: 2, EndId: 1, Type: "test"},
+		}},
+	}}}
+	sess := &SessionV2Impl{gogm: g, tx: tx}
+
+	stale := &f{}
+	stale.UUID = "stale"
+	node := &f{Parents: []*f{stale}}
+	node.UUID = "f1"
+
+	req.Nil(sess.Refresh(context.Background(), node, DepthInfinite))
+	req.Len(tx.statements, 1)
+	req.Contains(tx.statements[0], "gogm_root")
+	req.Equal("f1", node.UUID)
+	req.Len(node.Parents, 1)
+	req.Equal("f2", node.Parents[0].UUID)
+	req.Same(node, node.Parents[0].Parents[0])
+	req.Same(node, node.Children[0].Children[0])
+	req.Equal([]int64{2}, node.LoadMap["Parents"].Ids)
+
+	req.True(errors.Is(sess.Refresh(context.Background(), &f{}, 1), ErrInvalidParams))
+}
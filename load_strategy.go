@@ -23,6 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	dsl "github.com/mindstand/go-cypherdsl"
 )
@@ -35,17 +38,69 @@ const (
 	PATH_LOAD_STRATEGY LoadStrategy = iota
 	// SchemaLoadStrategy generates queries specifically from generated schema
 	SCHEMA_LOAD_STRATEGY
+	// SubqueryLoadStrategy generates a CALL subquery per relationship from the generated schema, requires neo4j 4 or newer
+	SUBQUERY_LOAD_STRATEGY
 )
 
+// loadStrategyNames is indexed by LoadStrategy
+var loadStrategyNames = []string{"PATH_LOAD_STRATEGY", "SCHEMA_LOAD_STRATEGY", "SUBQUERY_LOAD_STRATEGY"}
+
 func (ls LoadStrategy) validate() error {
 	switch ls {
-	case PATH_LOAD_STRATEGY, SCHEMA_LOAD_STRATEGY:
+	case PATH_LOAD_STRATEGY, SCHEMA_LOAD_STRATEGY, SUBQUERY_LOAD_STRATEGY:
 		return nil
 	default:
 		return fmt.Errorf("invalid load strategy %d", ls)
 	}
 }
 
+// String returns the name of the strategy, such as SCHEMA_LOAD_STRATEGY
+func (ls LoadStrategy) String() string {
+	if ls.validate() == nil {
+		return loadStrategyNames[ls]
+	}
+	return fmt.Sprintf("LoadStrategy(%d)", int(ls))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (ls LoadStrategy) MarshalText() ([]byte, error) {
+	if err := ls.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(ls.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of the strategy or its number
+func (ls *LoadStrategy) UnmarshalText(text []byte) error {
+	value, err := parseStrategy(string(text), "load", loadStrategyNames)
+	if err != nil {
+		return err
+	}
+	*ls = LoadStrategy(value)
+	return nil
+}
+
+// UnmarshalJSON accepts the name of the strategy or its number
+func (ls *LoadStrategy) UnmarshalJSON(data []byte) error {
+	return unmarshalStrategyJSON(data, ls.UnmarshalText)
+}
+
+// parseStrategy finds a strategy by name, ignoring case, or by number
+func parseStrategy(text, kind string, names []string) (int, error) {
+	text = strings.TrimSpace(text)
+	for i, name := range names {
+		if strings.EqualFold(text, name) {
+			return i, nil
+		}
+	}
+
+	if i, err := strconv.Atoi(text); err == nil && i >= 0 && i < len(names) {
+		return i, nil
+	}
+
+	return 0, fmt.Errorf("invalid %s strategy %q, expected one of %s", kind, text, strings.Join(names, ", "))
+}
+
 // PathLoadStrategyMany loads many using path strategy
 func PathLoadStrategyMany(variable, label string, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
 	if variable == "" {
@@ -82,6 +137,12 @@ func PathLoadStrategyMany(variable, label string, depth int, additionalConstrain
 
 // PathLoadStrategyOne loads one object using path strategy
 func PathLoadStrategyOne(variable, label, fieldOn, paramName string, isGraphId bool, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	return pathLoadStrategyByKey(variable, label, fieldOn, paramName, isGraphId, false, depth, additionalConstraints)
+}
+
+// pathLoadStrategyByKey loads the object with the primary key in paramName using path strategy. When many is set
+// paramName holds a list of primary keys and every object with one of them is loaded
+func pathLoadStrategyByKey(variable, label, fieldOn, paramName string, isGraphId, many bool, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
 	if variable == "" {
 		return nil, errors.New("variable name cannot be empty")
 	}
@@ -94,9 +155,15 @@ func PathLoadStrategyOne(variable, label, fieldOn, paramName string, isGraphId b
 		return nil, errors.New("depth can not be less than 0")
 	}
 
+	// the list of keys is matched against every node of label, so the root is labelled
+	root := dsl.V{Name: variable}
+	if many {
+		root.Type = label
+	}
+
 	path := dsl.Path().
 		P().
-		V(dsl.V{Name: variable})
+		V(root)
 
 	if depth != 0 {
 		path = path.
@@ -104,33 +171,43 @@ func PathLoadStrategyOne(variable, label, fieldOn, paramName string, isGraphId b
 			V(dsl.V{})
 	}
 
+	where, err := primaryKeyCondition(variable, fieldOn, paramName, isGraphId, many, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
 	builder := dsl.QB().
-		Match(path.Build())
+		Match(path.Build()).
+		Cypher("WHERE " + string(where))
+
+	return builder.Return(false, dsl.ReturnPart{Name: "p"}), nil
+}
 
-	var condition *dsl.ConditionConfig
+// primaryKeyCondition is the condition that variable has the primary key in paramName, on top of
+// additionalConstraints. When many is set paramName holds a list and variable can have any primary key in it.
+// additionalConstraints is parenthesized so an OR in it can not bypass the key
+func primaryKeyCondition(variable, fieldOn, paramName string, isGraphId, many bool, additionalConstraints dsl.ConditionOperator) (dsl.WhereQuery, error) {
+	key := fmt.Sprintf("%s.%s", variable, fieldOn)
 	if isGraphId {
-		condition = &dsl.ConditionConfig{
-			FieldManipulationFunction: "ID",
-			Name:                      variable,
-			ConditionOperator:         dsl.EqualToOperator,
-			Check:                     dsl.ParamString("$" + paramName),
-		}
-	} else {
-		condition = &dsl.ConditionConfig{
-			Name:              variable,
-			Field:             fieldOn,
-			ConditionOperator: dsl.EqualToOperator,
-			Check:             dsl.ParamString("$" + paramName),
-		}
+		key = fmt.Sprintf("ID(%s)", variable)
 	}
 
-	if additionalConstraints != nil {
-		builder = builder.Where(additionalConstraints.And(condition))
-	} else {
-		builder = builder.Where(dsl.C(condition))
+	operator := dsl.EqualToOperator
+	if many {
+		operator = dsl.InOperator
 	}
 
-	return builder.Return(false, dsl.ReturnPart{Name: "p"}), nil
+	condition := dsl.WhereQuery(fmt.Sprintf("%s %s $%s", key, operator, paramName))
+	if additionalConstraints == nil {
+		return condition, nil
+	}
+
+	where, err := additionalConstraints.Build()
+	if err != nil {
+		return "", err
+	}
+
+	return "(" + where + ") AND " + condition, nil
 }
 
 // PathLoadStrategyEdgeConstraint is similar to load many, but requires that it is related to another node via some edge
@@ -201,7 +278,15 @@ func getRelationshipsForLabel(gogm *Gogm, label string) ([]decoratorConfig, erro
 	return fields, nil
 }
 
-func expandBootstrap(gogm *Gogm, variable, label string, depth int) (string, error) {
+// sortRelationships orders rels by field name. Queries built from them stay the same every time, so the server can
+// reuse their plans
+func sortRelationships(rels []decoratorConfig) {
+	sort.Slice(rels, func(i, j int) bool {
+		return rels[i].FieldName < rels[j].FieldName
+	})
+}
+
+func expandBootstrap(gogm *Gogm, scope *tenantScope, variable, label string, depth int) (string, error) {
 	clause := ""
 	rels, err := getRelationshipsForLabel(gogm, label)
 	if err != nil {
@@ -213,7 +298,7 @@ func expandBootstrap(gogm *Gogm, variable, label string, depth int) (string, err
 			clause += ", ["
 		}
 
-		expanded, err := expand(gogm, variable, label, rels, 1, depth-1)
+		expanded, err := expand(gogm, scope, variable, label, rels, 1, depth-1)
 		if err != nil {
 			return "", err
 		}
@@ -227,7 +312,7 @@ func expandBootstrap(gogm *Gogm, variable, label string, depth int) (string, err
 	return clause, nil
 }
 
-func expand(gogm *Gogm, variable, label string, rels []decoratorConfig, level, depth int) (string, error) {
+func expand(gogm *Gogm, scope *tenantScope, variable, label string, rels []decoratorConfig, level, depth int) (string, error) {
 	clause := ""
 
 	for i, rel := range rels {
@@ -236,7 +321,7 @@ func expand(gogm *Gogm, variable, label string, rels []decoratorConfig, level, d
 			clause += ", "
 		}
 
-		ret, err := listComprehension(gogm, variable, label, rel, level, depth)
+		ret, err := listComprehension(gogm, scope, variable, label, rel, level, depth)
 		if err != nil {
 			return "", err
 		}
@@ -259,7 +344,7 @@ func relString(variable string, rel decoratorConfig) string {
 	return fmt.Sprintf("%s[%s:%s]%s", start, variable, rel.Relationship, end)
 }
 
-func listComprehension(gogm *Gogm, fromNodeVar, label string, rel decoratorConfig, level, depth int) (string, error) {
+func listComprehension(gogm *Gogm, scope *tenantScope, fromNodeVar, label string, rel decoratorConfig, level, depth int) (string, error) {
 	relVar := fmt.Sprintf("r_%c_%d", rel.Relationship[0], level)
 
 	toNodeType := rel.Type.Elem()
@@ -274,7 +359,7 @@ func listComprehension(gogm *Gogm, fromNodeVar, label string, rel decoratorConfi
 
 	toNodeVar := fmt.Sprintf("n_%c_%d", toNodeLabel[0], level)
 
-	clause := fmt.Sprintf("[(%s)%s(%s:%s) | [%s, %s", fromNodeVar, relString(relVar, rel), toNodeVar, toNodeLabel, relVar, toNodeVar)
+	clause := fmt.Sprintf("[(%s)%s(%s:%s)%s | [%s, %s", fromNodeVar, relString(relVar, rel), toNodeVar, toNodeLabel, scope.comprehensionFilter(toNodeVar), relVar, toNodeVar)
 
 	if depth > 0 {
 		toNodeRels, err := getRelationshipsForLabel(gogm, label)
@@ -283,7 +368,7 @@ func listComprehension(gogm *Gogm, fromNodeVar, label string, rel decoratorConfi
 		}
 
 		if len(toNodeRels) > 0 {
-			toNodeExpansion, err := expand(gogm, toNodeVar, toNodeLabel, toNodeRels, level+1, depth-1)
+			toNodeExpansion, err := expand(gogm, scope, toNodeVar, toNodeLabel, toNodeRels, level+1, depth-1)
 			if err != nil {
 				return "", err
 			}
@@ -297,6 +382,12 @@ func listComprehension(gogm *Gogm, fromNodeVar, label string, rel decoratorConfi
 
 // SchemaLoadStrategyMany loads many using schema strategy
 func SchemaLoadStrategyMany(gogm *Gogm, variable, label string, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	return schemaLoadStrategyMany(gogm, nil, variable, label, depth, additionalConstraints)
+}
+
+// schemaLoadStrategyMany is SchemaLoadStrategyMany with the related nodes limited to those in scope. The root node
+// is scoped through additionalConstraints
+func schemaLoadStrategyMany(gogm *Gogm, scope *tenantScope, variable, label string, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
 	if variable == "" {
 		return nil, errors.New("variable name cannot be empty")
 	}
@@ -318,7 +409,7 @@ func SchemaLoadStrategyMany(gogm *Gogm, variable, label string, depth int, addit
 	builder = builder.Cypher("RETURN " + variable)
 
 	if depth > 0 {
-		clause, err := expandBootstrap(gogm, variable, label, depth)
+		clause, err := expandBootstrap(gogm, scope, variable, label, depth)
 		if err != nil {
 			return nil, err
 		}
@@ -330,6 +421,80 @@ func SchemaLoadStrategyMany(gogm *Gogm, variable, label string, depth int, addit
 
 // SchemaLoadStrategyOne loads one object using schema strategy
 func SchemaLoadStrategyOne(gogm *Gogm, variable, label, fieldOn, paramName string, isGraphId bool, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	return schemaLoadStrategyByKey(gogm, nil, variable, label, fieldOn, paramName, isGraphId, false, depth, additionalConstraints)
+}
+
+// schemaLoadStrategyByKey loads the object with the primary key in paramName using schema strategy, with the related
+// nodes limited to those in scope. The root node is scoped through additionalConstraints. When many is set paramName
+// holds a list of primary keys and every object with one of them is loaded
+func schemaLoadStrategyByKey(gogm *Gogm, scope *tenantScope, variable, label, fieldOn, paramName string, isGraphId, many bool, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	if depth < 0 {
+		return nil, errors.New("depth can not be less than 0")
+	}
+
+	builder, err := matchRoot(variable, label, fieldOn, paramName, isGraphId, many, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	builder = builder.Cypher("RETURN " + variable)
+
+	if depth > 0 {
+		clause, err := expandBootstrap(gogm, scope, variable, label, depth)
+		if err != nil {
+			return nil, err
+		}
+		builder = builder.Cypher(clause)
+	}
+
+	return builder, nil
+}
+
+// SubqueryLoadStrategyMany loads many using subquery strategy. Pagination, which may be nil, is applied to the
+// root nodes before their relationships are expanded
+func SubqueryLoadStrategyMany(gogm *Gogm, variable, label string, depth int, additionalConstraints dsl.ConditionOperator, pagination *Pagination) (dsl.Cypher, error) {
+	return subqueryLoadStrategyMany(gogm, nil, variable, label, depth, additionalConstraints, pagination)
+}
+
+// subqueryLoadStrategyMany is SubqueryLoadStrategyMany with the related nodes limited to those in scope. The root
+// node is scoped through additionalConstraints
+func subqueryLoadStrategyMany(gogm *Gogm, scope *tenantScope, variable, label string, depth int, additionalConstraints dsl.ConditionOperator, pagination *Pagination) (dsl.Cypher, error) {
+	if depth < 0 {
+		return nil, errors.New("depth can not be less than 0")
+	}
+
+	builder, err := matchRoots(variable, label, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	return subqueryExpandRoot(gogm, scope, builder, variable, label, depth, pagination)
+}
+
+// SubqueryLoadStrategyOne loads one object using subquery strategy
+func SubqueryLoadStrategyOne(gogm *Gogm, variable, label, fieldOn, paramName string, isGraphId bool, depth int, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	return subqueryLoadStrategyByKey(gogm, nil, variable, label, fieldOn, paramName, isGraphId, false, depth, additionalConstraints, nil)
+}
+
+// subqueryLoadStrategyByKey loads the object with the primary key in paramName using subquery strategy, with the
+// related nodes limited to those in scope and the root paginated. The root node is scoped through
+// additionalConstraints. When many is set paramName holds a list of primary keys and every object with one of them
+// is loaded
+func subqueryLoadStrategyByKey(gogm *Gogm, scope *tenantScope, variable, label, fieldOn, paramName string, isGraphId, many bool, depth int, additionalConstraints dsl.ConditionOperator, pagination *Pagination) (dsl.Cypher, error) {
+	if depth < 0 {
+		return nil, errors.New("depth can not be less than 0")
+	}
+
+	builder, err := matchRoot(variable, label, fieldOn, paramName, isGraphId, many, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	return subqueryExpandRoot(gogm, scope, builder, variable, label, depth, pagination)
+}
+
+// matchRoots matches the root nodes of a load many
+func matchRoots(variable, label string, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
 	if variable == "" {
 		return nil, errors.New("variable name cannot be empty")
 	}
@@ -338,44 +503,107 @@ func SchemaLoadStrategyOne(gogm *Gogm, variable, label, fieldOn, paramName strin
 		return nil, errors.New("label can not be empty")
 	}
 
-	if depth < 0 {
-		return nil, errors.New("depth can not be less than 0")
+	builder := dsl.QB().Cypher(fmt.Sprintf("MATCH (%s:%s)", variable, label))
+
+	if additionalConstraints != nil {
+		builder = builder.Where(additionalConstraints)
 	}
 
-	builder := dsl.QB().Cypher(fmt.Sprintf("MATCH (%s:%s)", variable, label))
+	return builder, nil
+}
 
-	var condition *dsl.ConditionConfig
-	if isGraphId {
-		condition = &dsl.ConditionConfig{
-			FieldManipulationFunction: "ID",
-			Name:                      variable,
-			ConditionOperator:         dsl.EqualToOperator,
-			Check:                     dsl.ParamString("$" + paramName),
-		}
-	} else {
-		condition = &dsl.ConditionConfig{
-			Name:              variable,
-			Field:             fieldOn,
-			ConditionOperator: dsl.EqualToOperator,
-			Check:             dsl.ParamString("$" + paramName),
-		}
+// matchRoot matches the root node of a load one by the primary key in paramName, or the root nodes with any of the
+// primary keys in paramName when many is set
+func matchRoot(variable, label, fieldOn, paramName string, isGraphId, many bool, additionalConstraints dsl.ConditionOperator) (dsl.Cypher, error) {
+	if variable == "" {
+		return nil, errors.New("variable name cannot be empty")
 	}
 
-	if additionalConstraints != nil {
-		builder = builder.Where(additionalConstraints.And(condition))
-	} else {
-		builder = builder.Where(dsl.C(condition))
+	if label == "" {
+		return nil, errors.New("label can not be empty")
 	}
 
-	builder = builder.Cypher("RETURN " + variable)
+	where, err := primaryKeyCondition(variable, fieldOn, paramName, isGraphId, many, additionalConstraints)
+	if err != nil {
+		return nil, err
+	}
+
+	return dsl.QB().
+		Cypher(fmt.Sprintf("MATCH (%s:%s)", variable, label)).
+		Cypher("WHERE " + string(where)), nil
+}
+
+// subqueryExpandRoot paginates the matched root nodes, then adds a subquery for each of their relationships
+func subqueryExpandRoot(gogm *Gogm, scope *tenantScope, builder dsl.Cypher, variable, label string, depth int, pagination *Pagination) (dsl.Cypher, error) {
+	builder = builder.Cypher("WITH " + variable)
+
+	if pagination != nil {
+		if err := pagination.Paginate(builder); err != nil {
+			return nil, err
+		}
+	}
 
+	returns := []string{variable + " AS " + rootColumn}
 	if depth > 0 {
-		clause, err := expandBootstrap(gogm, variable, label, depth)
+		calls, collected, err := subqueryExpand(gogm, scope, variable, label, "", depth)
 		if err != nil {
 			return nil, err
 		}
-		builder = builder.Cypher(clause)
+
+		for _, call := range calls {
+			builder = builder.Cypher(call)
+		}
+		returns = append(returns, collected...)
 	}
 
-	return builder, nil
+	return builder.Cypher("RETURN " + strings.Join(returns, ", ")), nil
+}
+
+// subqueryExpand returns a CALL subquery for every relationship of label, each collecting the relationships and
+// related nodes of variable into the list it returns. Related nodes are expanded the same way inside the
+// subquery until depth is reached. Variables are suffixed with path, the position of the relationship in the tree
+func subqueryExpand(gogm *Gogm, scope *tenantScope, variable, label, path string, depth int) ([]string, []string, error) {
+	rels, err := getRelationshipsForLabel(gogm, label)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sortRelationships(rels)
+
+	calls := make([]string, 0, len(rels))
+	collected := make([]string, 0, len(rels))
+	for i, rel := range rels {
+		relPath := fmt.Sprintf("%s_%d", path, i)
+		relVar, toNodeVar, listVar := "r"+relPath, "m"+relPath, "c"+relPath
+
+		toNodeType := rel.Type.Elem()
+		if rel.Type.Kind() == reflect.Slice {
+			toNodeType = toNodeType.Elem()
+		}
+
+		toNodeLabel, err := traverseRelType(toNodeType, rel.Direction)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		call := fmt.Sprintf("CALL { WITH %s MATCH (%s)%s(%s:%s)%s", variable, variable, relString(relVar, rel), toNodeVar, toNodeLabel, scope.comprehensionFilter(toNodeVar))
+
+		row := []string{relVar, toNodeVar}
+		if depth > 1 {
+			nested, nestedCollected, err := subqueryExpand(gogm, scope, toNodeVar, toNodeLabel, relPath, depth-1)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(nested) > 0 {
+				call += " " + strings.Join(nested, " ")
+			}
+			row = append(row, nestedCollected...)
+		}
+
+		calls = append(calls, fmt.Sprintf("%s RETURN collect([%s]) AS %s }", call, strings.Join(row, ", "), listVar))
+		collected = append(collected, listVar)
+	}
+
+	return calls, collected, nil
 }
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/stretchr/testify/require"
 )
 
@@ -83,3 +84,132 @@ func TestSchemaLoadStrategyOne(t *testing.T) {
 	req.Nil(err)
 	req.Equal(cypherStr, "MATCH (n:a) WHERE n.uuid = $uuid RETURN n")
 }
+
+func TestSubqueryLoadStrategyMany(t *testing.T) {
+	req := require.New(t)
+
+	// reusing structs from decode_test
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	req.NotNil(gogm)
+
+	// test base case with no expansion
+	cypher, err := SubqueryLoadStrategyMany(gogm, "n", "a", 0, nil, nil)
+	req.Nil(err)
+	cypherStr, err := cypher.ToCypher()
+	req.Nil(err)
+	req.Equal("MATCH (n:a) WITH n RETURN n AS gogm_root", cypherStr)
+
+	// the root is filtered and paginated before it is expanded
+	cypher, err = SubqueryLoadStrategyMany(gogm, "n", "a", 1, dsl.C(&dsl.ConditionConfig{
+		Name:              "n",
+		ConditionOperator: dsl.EqualToOperator,
+		Field:             "test_field",
+		Check:             dsl.ParamString("$someParam"),
+	}), &Pagination{PageNumber: 1, LimitPerPage: 5, OrderByVarName: "n", OrderByField: "test_field"})
+	req.Nil(err)
+	cypherStr, err = cypher.ToCypher()
+	req.Nil(err)
+	req.Regexp("^MATCH \\(n:a\\) WHERE n.test_field = \\$someParam WITH n ORDER BY n.test_field SKIP 5 LIMIT 5 CALL \\{", cypherStr)
+	req.Contains(cypherStr, "CALL { WITH n MATCH (n)-[r_2:special_multi]->(m_2:b) RETURN collect([r_2, m_2]) AS c_2 }")
+	req.NotContains(cypherStr, ":c)", "Spec edge should not be treated as a node")
+	req.Regexp("RETURN n AS gogm_root, c_0, c_1, c_2, c_3, c_4$", cypherStr)
+
+	// related nodes are expanded inside the subquery of their relationship
+	cypher, err = SubqueryLoadStrategyMany(gogm, "n", "a", 2, nil, nil)
+	req.Nil(err)
+	cypherStr, err = cypher.ToCypher()
+	req.Nil(err)
+	req.Contains(cypherStr, "CALL { WITH m_2 MATCH (m_2)<-[r_2_2:special_multi]-(m_2_2:a) RETURN collect([r_2_2, m_2_2]) AS c_2_2 }")
+	req.Contains(cypherStr, "RETURN collect([r_2, m_2, c_2_0, c_2_1, c_2_2, c_2_3, c_2_4]) AS c_2 }")
+
+	// the query is the same every time
+	again, err := SubqueryLoadStrategyMany(gogm, "n", "a", 2, nil, nil)
+	req.Nil(err)
+	againStr, err := again.ToCypher()
+	req.Nil(err)
+	req.Equal(cypherStr, againStr)
+
+	// test fail condition of non-existing label
+	cypher, err = SubqueryLoadStrategyMany(gogm, "n", "nonexisting", 2, nil, nil)
+	req.NotNil(err, "Should fail due to non-existing label")
+	req.Nil(cypher)
+
+	_, err = SubqueryLoadStrategyMany(gogm, "n", "a", 1, nil, &Pagination{PageNumber: -1})
+	req.NotNil(err)
+}
+
+func TestSubqueryLoadStrategyOne(t *testing.T) {
+	req := require.New(t)
+
+	// reusing structs from decode_test
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	req.NotNil(gogm)
+
+	// test base case with no expansion
+	cypher, err := SubqueryLoadStrategyOne(gogm, "n", "a", "uuid", "uuid", false, 0, nil)
+	req.Nil(err)
+	cypherStr, err := cypher.ToCypher()
+	req.Nil(err)
+	req.Equal("MATCH (n:a) WHERE n.uuid = $uuid WITH n RETURN n AS gogm_root", cypherStr)
+
+	cypher, err = SubqueryLoadStrategyOne(gogm, "n", "b", "", "idprm", true, 1, nil)
+	req.Nil(err)
+	cypherStr, err = cypher.ToCypher()
+	req.Nil(err)
+	req.Regexp("^MATCH \\(n:b\\) WHERE ID\\(n\\) = \\$idprm WITH n CALL \\{ WITH n MATCH \\(n\\)", cypherStr)
+}
+
+func TestSubqueryLoadStrategy_Decode(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	a1 := neo4j.Node{Id: 1, Labels: []string{"a"}, Props: map[string]interface{}{"uuid": "a1"}}
+	a2 := neo4j.Node{Id: 2, Labels: []string{"a"}, Props: map[string]interface{}{"uuid": "a2"}}
+	b1 := neo4j.Node{Id: 3, Labels: []string{"b"}, Props: map[string]interface{}{"uuid": "b1"}}
+	result := &recordsResult{records: []*neo4j.Record{{
+		Keys: []string{rootColumn, "c_0"},
+		Values: []interface{}{a1, []interface{}{
+			[]interface{}{
+				neo4j.Relationship{Id: 10, StartId: 3, EndId: 1, Type: "multib"}, b1,
+				[]interface{}{
+					[]interface{}{neo4j.Relationship{Id: 11, StartId: 3, EndId: 2, Type: "multib"}, a2},
+				},
+			},
+		}},
+	}}}
+
+	// a2 has the label of the root but is only related to it
+	var loaded []*a
+	req.Nil(decode(gogm, result, &loaded))
+	req.Len(loaded, 1)
+	req.Equal("a1", loaded[0].UUID)
+	req.Len(loaded[0].MultiA, 1)
+	req.Equal("b1", loaded[0].MultiA[0].UUID)
+	req.Len(loaded[0].MultiA[0].Multi, 2)
+}
+
+func TestSessionV2Impl_SubqueryLoadQuery(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	gogm.config.LoadStrategy = SUBQUERY_LOAD_STRATEGY
+	sess := &SessionV2Impl{gogm: gogm}
+
+	// pagination is only applied to the root
+	cyp, _, err := sess.loadAllQuery(nil, nil, &[]a{}, 0, nil, nil, &Pagination{LimitPerPage: 2})
+	req.Nil(err)
+	req.Equal("MATCH (n:a) WITH n LIMIT 2 RETURN n AS gogm_root", cyp)
+
+	// related nodes are scoped to the tenant
+	scope := &tenantScope{mode: TenancyLabel, tenant: "acme", label: "`Tenant_acme`"}
+	cyp, params, err := sess.loadQuery(scope, nil, &b{}, "uuid", 1, nil, nil, nil)
+	req.Nil(err)
+	req.Regexp("^MATCH \\(n:b\\) WHERE \\(n:`Tenant_acme`\\) AND n.uuid = \\$idprm WITH n CALL", cyp)
+	req.Contains(cyp, "MATCH (n)-[r_0:testm2o]->(m_0:a) WHERE m_0:`Tenant_acme` RETURN collect([r_0, m_0]) AS c_0")
+	req.Equal(map[string]interface{}{"idprm": "uuid"}, params)
+}
@@ -0,0 +1,355 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// defaultLoaderWait is how long a Loader collects loads when LoaderConfig.Wait is not set
+const defaultLoaderWait = 2 * time.Millisecond
+
+// LoaderConfig configures a Loader
+type LoaderConfig struct {
+	// Wait is how long a load waits for more loads of the same type before they are run as one batch. Defaults to 2ms
+	Wait time.Duration
+	// MaxBatch is the number of ids loaded by one query, a batch runs as soon as it is full. 0 does not limit it
+	MaxBatch int
+	// Depth is the depth objects are loaded at
+	Depth int
+	// DisableCache makes every Load go to the database instead of reusing objects the Loader already loaded.
+	// Loads of an id that is already being loaded still share its batch
+	DisableCache bool
+}
+
+// Loader batches Load calls for the same type into one LoadMany query, which stops resolvers that load one object
+// at a time from running a query per object. A Loader is meant to live for one request, it keeps every object it
+// loads unless LoaderConfig.DisableCache is set. It is safe to use from multiple goroutines
+type Loader struct {
+	ctx  context.Context
+	gogm *Gogm
+	conf LoaderConfig
+	load func(ctx context.Context, respSlice interface{}, ids []interface{}) error
+
+	mu sync.Mutex
+	// collecting is the batch of each type and tenant that has not run yet
+	collecting map[loaderKey]*loaderBatch
+	// batches is the batch each id of a type and tenant was loaded by
+	batches map[loaderKey]map[interface{}]*loaderBatch
+}
+
+// loaderKey is what loads are batched by, loads of different tenants can not share a query
+type loaderKey struct {
+	typ    reflect.Type
+	tenant string
+}
+
+// loaderBatch is the ids of one type that are loaded together
+type loaderBatch struct {
+	key        loaderKey
+	ids        []interface{}
+	timer      *time.Timer
+	dispatched bool
+	// ctx is cancelled once no load waits for the batch anymore
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiting int
+	// done is closed once results and err are set
+	done    chan struct{}
+	results map[interface{}]reflect.Value
+	err     error
+}
+
+// NewLoader returns a Loader that runs its batches in read sessions with the values and deadline of the load that
+// started them, falling back to the values of ctx. Loads of different tenants are batched separately. A batch is
+// cancelled with ctx or once every load waiting for it stopped waiting
+func (g *Gogm) NewLoader(ctx context.Context, conf LoaderConfig) *Loader {
+	return newLoader(ctx, g, conf, func(ctx context.Context, respSlice interface{}, ids []interface{}) error {
+		sess, err := g.NewSessionV2(SessionConfig{AccessMode: AccessModeRead})
+		if err != nil {
+			return err
+		}
+		defer sess.Close()
+
+		return sess.LoadMany(ctx, respSlice, ids, conf.Depth)
+	})
+}
+
+// newLoader returns a Loader that runs its batches with load
+func newLoader(ctx context.Context, gogm *Gogm, conf LoaderConfig, load func(ctx context.Context, respSlice interface{}, ids []interface{}) error) *Loader {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if conf.Wait <= 0 {
+		conf.Wait = defaultLoaderWait
+	}
+
+	return &Loader{
+		ctx:        ctx,
+		gogm:       gogm,
+		conf:       conf,
+		load:       load,
+		collecting: map[loaderKey]*loaderBatch{},
+		batches:    map[loaderKey]map[interface{}]*loaderBatch{},
+	}
+}
+
+// Load loads the object with the primary key id into respObj, together with the other loads of its type made
+// within LoaderConfig.Wait. RespObj is either a pointer to a struct, which the object is copied into, or a pointer
+// to a pointer to a struct, which is pointed at the object the Loader keeps
+func (l *Loader) Load(ctx context.Context, respObj, id interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	val := reflect.ValueOf(respObj)
+	if !val.IsValid() || val.Kind() != reflect.Ptr || val.IsNil() {
+		return fmt.Errorf("respObj must be a pointer, instead it is %T, %w", respObj, ErrInvalidParams)
+	}
+
+	typ := val.Type().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("respObj must point to a struct, instead it is %T, %w", respObj, ErrInvalidParams)
+	}
+
+	if id == nil {
+		return fmt.Errorf("id can not be nil, %w", ErrInvalidParams)
+	}
+
+	key := primaryKeyKey(id)
+	batch, full := l.batch(ctx, typ, key, id)
+	if full {
+		l.dispatch(batch)
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		l.leave(batch)
+		return ctx.Err()
+	}
+
+	if batch.err != nil {
+		return batch.err
+	}
+
+	result, ok := batch.results[key]
+	if !ok {
+		return fmt.Errorf("%s with id %v, %w", typ.Name(), id, ErrNotFound)
+	}
+
+	if val.Elem().Kind() == reflect.Ptr {
+		val.Elem().Set(result)
+	} else {
+		val.Elem().Set(result.Elem())
+	}
+
+	return nil
+}
+
+// batch returns the batch that loads id for the tenant of ctx, adding it to the collecting batch of typ when no batch
+// has it yet. Full is true when the batch has to run now, it no longer collects loads
+func (l *Loader) batch(ctx context.Context, typ reflect.Type, key, id interface{}) (*loaderBatch, bool) {
+	tenant, _ := TenantFromContext(ctx)
+	batchKey := loaderKey{typ: typ, tenant: tenant}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if batch, ok := l.batches[batchKey][key]; ok {
+		batch.waiting++
+		return batch, false
+	}
+
+	batch, ok := l.collecting[batchKey]
+	if !ok {
+		batch = &loaderBatch{
+			key:  batchKey,
+			done: make(chan struct{}),
+		}
+		batchCtx := loaderContext{Context: l.ctx, values: ctx}
+		if deadline, ok := ctx.Deadline(); ok {
+			batch.ctx, batch.cancel = context.WithDeadline(batchCtx, deadline)
+		} else {
+			batch.ctx, batch.cancel = context.WithCancel(batchCtx)
+		}
+		batch.timer = time.AfterFunc(l.conf.Wait, func() {
+			l.dispatch(batch)
+		})
+		l.collecting[batchKey] = batch
+	}
+
+	batch.ids = append(batch.ids, id)
+	batch.waiting++
+	if l.batches[batchKey] == nil {
+		l.batches[batchKey] = map[interface{}]*loaderBatch{}
+	}
+	l.batches[batchKey][key] = batch
+
+	full := l.conf.MaxBatch > 0 && len(batch.ids) >= l.conf.MaxBatch
+	if full {
+		delete(l.collecting, batchKey)
+	}
+
+	return batch, full
+}
+
+// dispatch starts running batch in its own goroutine, unless it already ran
+func (l *Loader) dispatch(batch *loaderBatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if batch.dispatched {
+		return
+	}
+	batch.dispatched = true
+	batch.timer.Stop()
+	if l.collecting[batch.key] == batch {
+		delete(l.collecting, batch.key)
+	}
+
+	go l.run(batch)
+}
+
+// run loads the ids of batch and hands the results to the loads waiting for it
+func (l *Loader) run(batch *loaderBatch) {
+	defer batch.cancel()
+
+	resp := reflect.New(reflect.SliceOf(reflect.PtrTo(batch.key.typ)))
+	err := l.load(batch.ctx, resp.Interface(), batch.ids)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		batch.err = err
+	} else {
+		slice := resp.Elem()
+		batch.results = make(map[interface{}]reflect.Value, slice.Len())
+		for i := 0; i < slice.Len(); i++ {
+			obj := slice.Index(i)
+			if obj.IsNil() {
+				continue
+			}
+
+			if pk, ok := primaryKeyOf(l.gogm, obj.Elem()); ok {
+				batch.results[primaryKeyKey(pk)] = obj
+			}
+		}
+	}
+
+	// failed batches are forgotten so the ids can be loaded again
+	if batch.err != nil || l.conf.DisableCache {
+		l.mu.Lock()
+		l.forget(batch)
+		l.mu.Unlock()
+	}
+
+	close(batch.done)
+}
+
+// leave stops a load from waiting for batch. Once no load waits for a batch that has not finished, it is cancelled and
+// forgotten, so later loads of its ids start a new batch instead of sharing its error
+func (l *Loader) leave(batch *loaderBatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	batch.waiting--
+	if batch.waiting > 0 {
+		return
+	}
+
+	select {
+	case <-batch.done:
+		return
+	default:
+	}
+
+	batch.cancel()
+	l.forget(batch)
+	if !batch.dispatched {
+		batch.dispatched = true
+		batch.timer.Stop()
+		if l.collecting[batch.key] == batch {
+			delete(l.collecting, batch.key)
+		}
+	}
+}
+
+// forget removes the ids of batch from the loaded ids, l.mu has to be held
+func (l *Loader) forget(batch *loaderBatch) {
+	for _, id := range batch.ids {
+		key := primaryKeyKey(id)
+		if l.batches[batch.key][key] == batch {
+			delete(l.batches[batch.key], key)
+		}
+	}
+}
+
+// Flush starts the batches that are still collecting loads without waiting for LoaderConfig.Wait
+func (l *Loader) Flush() {
+	l.mu.Lock()
+	batches := make([]*loaderBatch, 0, len(l.collecting))
+	for _, batch := range l.collecting {
+		batches = append(batches, batch)
+	}
+	l.mu.Unlock()
+
+	for _, batch := range batches {
+		l.dispatch(batch)
+	}
+}
+
+// Clear forgets the objects the Loader loaded, so they are loaded from the database again
+func (l *Loader) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for batchKey, batches := range l.batches {
+		for key, batch := range batches {
+			if batch.dispatched {
+				delete(batches, key)
+			}
+		}
+		if len(batches) == 0 {
+			delete(l.batches, batchKey)
+		}
+	}
+}
+
+// loaderContext is the context of a Loader with the values of the load that started a batch, so the batch runs for
+// the tenant and options of that load without being cancelled with it while other loads still wait
+type loaderContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c loaderContext) Value(key interface{}) interface{} {
+	if value := c.values.Value(key); value != nil {
+		return value
+	}
+
+	return c.Context.Value(key)
+}
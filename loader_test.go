@@ -0,0 +1,300 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBatchLoad loads b nodes for the ids it is asked for, unless they are missing
+type fakeBatchLoad struct {
+	mu      sync.Mutex
+	batches [][]interface{}
+	missing map[interface{}]bool
+	err     error
+}
+
+func (f *fakeBatchLoad) load(_ context.Context, respSlice interface{}, ids []interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.batches = append(f.batches, ids)
+	if f.err != nil {
+		return f.err
+	}
+
+	slice := reflect.ValueOf(respSlice).Elem()
+	for _, id := range ids {
+		if f.missing[id] {
+			continue
+		}
+		node := &b{TestField: "loaded"}
+		node.UUID = id.(string)
+		slice.Set(reflect.Append(slice, reflect.ValueOf(node)))
+	}
+
+	if slice.Len() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (f *fakeBatchLoad) calls() [][]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.batches
+}
+
+func TestLoader_Batches(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	fake := &fakeBatchLoad{missing: map[interface{}]bool{"b4": true}}
+	loader := newLoader(ctx, g, LoaderConfig{Wait: 20 * time.Millisecond}, fake.load)
+
+	ids := []string{"b1", "b2", "b3", "b2"}
+	results := make([]*b, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			errs[i] = loader.Load(ctx, &results[i], id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	// every load is served by one batch, which asks for each id once
+	req.Len(fake.calls(), 1)
+	req.ElementsMatch([]interface{}{"b1", "b2", "b3"}, fake.calls()[0])
+	for i, id := range ids {
+		req.Nil(errs[i])
+		req.Equal(id, results[i].UUID)
+	}
+	req.Same(results[1], results[3])
+
+	// loaded objects are kept, a struct gets a copy of them
+	var copied b
+	req.Nil(loader.Load(ctx, &copied, "b1"))
+	req.Len(fake.calls(), 1)
+	req.Equal("b1", copied.UUID)
+	copied.TestField = "changed"
+	req.Equal("loaded", results[0].TestField)
+
+	// ids that do not exist are not found
+	var missing *b
+	req.True(errors.Is(loader.Load(ctx, &missing, "b4"), ErrNotFound))
+	req.Len(fake.calls(), 2)
+	req.Nil(missing)
+
+	// cleared objects are loaded again
+	loader.Clear()
+	req.Nil(loader.Load(ctx, &copied, "b1"))
+	req.Len(fake.calls(), 3)
+
+	req.True(errors.Is(loader.Load(ctx, b{}, "b1"), ErrInvalidParams))
+	req.True(errors.Is(loader.Load(ctx, &copied, nil), ErrInvalidParams))
+	var notStruct *string
+	req.True(errors.Is(loader.Load(ctx, &notStruct, "b1"), ErrInvalidParams))
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	// a full batch runs without waiting
+	fake := &fakeBatchLoad{}
+	loader := newLoader(ctx, g, LoaderConfig{Wait: time.Hour, MaxBatch: 2}, fake.load)
+
+	results := make([]b, 2)
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, id := range []string{"b1", "b2"} {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			errs[i] = loader.Load(ctx, &results[i], id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	req.Len(fake.calls(), 1)
+	req.Nil(errs[0])
+	req.Nil(errs[1])
+	req.Equal("b1", results[0].UUID)
+	req.Equal("b2", results[1].UUID)
+
+	// flush runs a batch that is not full
+	done := make(chan error)
+	go func() {
+		var node b
+		done <- loader.Load(ctx, &node, "b3")
+	}()
+	req.Eventually(func() bool {
+		loader.mu.Lock()
+		defer loader.mu.Unlock()
+		return len(loader.collecting) == 1
+	}, time.Second, time.Millisecond)
+	loader.Flush()
+	req.Nil(<-done)
+	req.Len(fake.calls(), 2)
+
+	// waiting stops with the context of the load
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	var node b
+	req.True(errors.Is(loader.Load(cancelled, &node, "b5"), context.Canceled))
+	loader.Flush()
+}
+
+func TestLoader_Errors(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	fake := &fakeBatchLoad{err: errors.New("connection lost")}
+	loader := newLoader(ctx, g, LoaderConfig{Wait: time.Millisecond}, fake.load)
+
+	var node b
+	req.EqualError(loader.Load(ctx, &node, "b1"), "connection lost")
+
+	// failed loads are not kept
+	fake.mu.Lock()
+	fake.err = nil
+	fake.mu.Unlock()
+	req.Nil(loader.Load(ctx, &node, "b1"))
+	req.Equal("b1", node.UUID)
+	req.Len(fake.calls(), 2)
+
+	// without the cache every load goes to the database
+	loader = newLoader(ctx, g, LoaderConfig{Wait: time.Millisecond, DisableCache: true}, fake.load)
+	req.Nil(loader.Load(ctx, &node, "b1"))
+	req.Nil(loader.Load(ctx, &node, "b1"))
+	req.Len(fake.calls(), 4)
+}
+
+func TestLoader_Context(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	// batches run with the tenant and deadline of their loads, loads of different tenants are not batched together
+	fake := &fakeBatchLoad{}
+	var mu sync.Mutex
+	tenants := map[string]time.Time{}
+	loader := newLoader(ctx, g, LoaderConfig{Wait: 20 * time.Millisecond}, func(ctx context.Context, respSlice interface{}, ids []interface{}) error {
+		mu.Lock()
+		tenant, _ := TenantFromContext(ctx)
+		tenants[tenant], _ = ctx.Deadline()
+		mu.Unlock()
+		return fake.load(ctx, respSlice, ids)
+	})
+
+	withDeadline, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	deadline, _ := withDeadline.Deadline()
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, tenant := range []string{"acme", "globex"} {
+		wg.Add(1)
+		go func(i int, tenant string) {
+			defer wg.Done()
+			var node b
+			errs[i] = loader.Load(WithTenant(withDeadline, tenant), &node, "b1")
+		}(i, tenant)
+	}
+	wg.Wait()
+
+	req.Nil(errs[0])
+	req.Nil(errs[1])
+	req.Len(fake.calls(), 2)
+	req.Equal(map[string]time.Time{"acme": deadline, "globex": deadline}, tenants)
+
+	// a batch nobody waits for anymore is cancelled and its ids are loaded again by the next load
+	started := make(chan struct{})
+	var once sync.Once
+	loader = newLoader(ctx, g, LoaderConfig{Wait: time.Millisecond}, func(ctx context.Context, respSlice interface{}, ids []interface{}) error {
+		first := false
+		once.Do(func() {
+			first = true
+			close(started)
+		})
+		if first {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return fake.load(ctx, respSlice, ids)
+	})
+
+	cancelled, cancelLoad := context.WithCancel(ctx)
+	go func() {
+		<-started
+		cancelLoad()
+	}()
+	var node b
+	req.True(errors.Is(loader.Load(cancelled, &node, "b2"), context.Canceled))
+	req.Nil(loader.Load(ctx, &node, "b2"))
+	req.Equal("b2", node.UUID)
+}
+
+func TestLoader_FullBatch(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	ctx := context.Background()
+
+	// a full batch stops collecting before it runs, so it never grows past MaxBatch
+	fake := &fakeBatchLoad{}
+	loader := newLoader(ctx, g, LoaderConfig{Wait: time.Hour, MaxBatch: 2}, fake.load)
+
+	_, full := loader.batch(ctx, reflect.TypeOf(b{}), primaryKeyKey("b1"), "b1")
+	req.False(full)
+	batch, full := loader.batch(ctx, reflect.TypeOf(b{}), primaryKeyKey("b2"), "b2")
+	req.True(full)
+	next, full := loader.batch(ctx, reflect.TypeOf(b{}), primaryKeyKey("b3"), "b3")
+	req.False(full)
+	req.NotSame(batch, next)
+	req.Len(batch.ids, 2)
+
+	loader.dispatch(batch)
+	loader.Flush()
+	<-batch.done
+	<-next.done
+	req.Len(fake.calls(), 2)
+}
@@ -20,8 +20,12 @@
 package gogm
 
 import (
-	_neoLog "github.com/neo4j/neo4j-go-driver/v5/neo4j/log"
+	"context"
+	"fmt"
 	"log"
+	"strings"
+
+	_neoLog "github.com/neo4j/neo4j-go-driver/v5/neo4j/log"
 )
 
 type Logger interface {
@@ -70,39 +74,266 @@ func (wn *wrapNeoLogger) Debugf(name string, id string, msg string, args ...inte
 	wn.log.Debugf("[name=%s] [id=%s] "+msg, arr...)
 }
 
+// LogLevel is the severity of a log message
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelFatal
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	case LogLevelFatal:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// ParseLogLevel converts a level name like "debug" or "WARN" to a LogLevel. An empty name is DEBUG, which is what
+// gogm logged at before log levels could be set, and TRACE is treated as DEBUG
+func ParseLogLevel(level string) (LogLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "", "TRACE", "DEBUG":
+		return LogLevelDebug, nil
+	case "INFO":
+		return LogLevelInfo, nil
+	case "WARN", "WARNING":
+		return LogLevelWarn, nil
+	case "ERROR":
+		return LogLevelError, nil
+	case "FATAL":
+		return LogLevelFatal, nil
+	default:
+		return LogLevelInfo, fmt.Errorf("unknown log level %s", level)
+	}
+}
+
+// keys of the fields gogm adds to log messages
+const (
+	LogFieldTraceId   = "trace_id"
+	LogFieldDatabase  = "db"
+	LogFieldOperation = "operation"
+	LogFieldStatement = "statement"
+	LogFieldParams    = "params"
+	LogFieldError     = "error"
+)
+
+// Field is a key/value pair attached to a log message
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// LogField creates a Field
+func LogField(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogger is a Logger that accepts key/value fields. When Config.Logger implements it, gogm logs
+// session activity through Log with the trace id, database and operation of the call as fields
+type StructuredLogger interface {
+	Logger
+	// Enabled reports whether messages at level are written
+	Enabled(level LogLevel) bool
+	// Log writes msg with fields at level
+	Log(ctx context.Context, level LogLevel, msg string, fields ...Field)
+}
+
+type logFieldsContextKey struct{}
+
+// ContextWithLogFields returns a copy of ctx carrying fields, which are added to everything gogm logs for calls made with it
+func ContextWithLogFields(ctx context.Context, fields ...Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	existing := logFieldsFromContext(ctx)
+	combined := make([]Field, 0, len(existing)+len(fields))
+	combined = append(combined, existing...)
+	combined = append(combined, fields...)
+	return context.WithValue(ctx, logFieldsContextKey{}, combined)
+}
+
+func logFieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(logFieldsContextKey{}).([]Field)
+	return fields
+}
+
+// formatFields renders fields as ` key=value` pairs for printf style loggers
+func formatFields(fields []Field) string {
+	var sb strings.Builder
+	for _, field := range fields {
+		sb.WriteString(" ")
+		sb.WriteString(field.Key)
+		sb.WriteString("=")
+		sb.WriteString(fmt.Sprintf("%v", field.Value))
+	}
+
+	return sb.String()
+}
+
+// logEnabled reports whether the configured logger writes messages at level
+func (g *Gogm) logEnabled(level LogLevel) bool {
+	if sl, ok := g.logger.(StructuredLogger); ok {
+		return sl.Enabled(level)
+	}
+
+	return g.logger != nil
+}
+
+// log writes msg through the configured logger. Structured loggers receive the fields as they are,
+// printf style loggers get them appended to the message
+func (g *Gogm) log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	if g.logger == nil {
+		return
+	}
+
+	if ctxFields := logFieldsFromContext(ctx); len(ctxFields) != 0 {
+		fields = append(append([]Field{}, ctxFields...), fields...)
+	}
+
+	if sl, ok := g.logger.(StructuredLogger); ok {
+		if sl.Enabled(level) {
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			sl.Log(ctx, level, msg, fields...)
+		}
+		return
+	}
+
+	line := msg + formatFields(fields)
+	switch level {
+	case LogLevelDebug:
+		g.logger.Debug(line)
+	case LogLevelInfo:
+		g.logger.Info(line)
+	case LogLevelWarn:
+		g.logger.Warn(line)
+	case LogLevelError:
+		g.logger.Error(line)
+	default:
+		g.logger.Fatal(line)
+	}
+}
+
+// log writes msg with the database, operation and trace id of the call
+func (s *SessionV2Impl) log(ctx context.Context, level LogLevel, msg string, fields ...Field) {
+	base := make([]Field, 0, 3+len(fields))
+	if id := traceIdFromContext(ctx); id != "" {
+		base = append(base, LogField(LogFieldTraceId, id))
+	}
+	base = append(base, LogField(LogFieldDatabase, s.conf.DatabaseName))
+	if op := operationFromContext(ctx); op != "" {
+		base = append(base, LogField(LogFieldOperation, op))
+	}
+
+	s.gogm.log(ctx, level, msg, append(base, fields...)...)
+}
+
+// logQuery logs a statement and its params, redacted with the configured ParamRedactor, at debug level
+func (s *SessionV2Impl) logQuery(ctx context.Context, statement string, params map[string]interface{}) {
+	if !s.gogm.logEnabled(LogLevelDebug) {
+		return
+	}
+
+	fields := []Field{LogField(LogFieldStatement, statement)}
+	if redactor := s.gogm.config.ParamRedactor; redactor != nil {
+		fields = append(fields, LogField(LogFieldParams, redactor.RedactParams(params)))
+	}
+
+	s.log(ctx, LogLevelDebug, "running query", fields...)
+}
+
+// defaultLogger writes to the standard library logger and drops messages below its level
 type defaultLogger struct {
+	level LogLevel
+}
+
+// NewDefaultLogger returns a StructuredLogger that writes messages at or above level with the standard library logger
+func NewDefaultLogger(level LogLevel) StructuredLogger {
+	return &defaultLogger{level: level}
+}
+
+func (d defaultLogger) Enabled(level LogLevel) bool {
+	return level >= d.level
+}
+
+func (d defaultLogger) Log(_ context.Context, level LogLevel, msg string, fields ...Field) {
+	if !d.Enabled(level) {
+		return
+	}
+
+	if level == LogLevelFatal {
+		log.Fatalln("[FATAL] " + msg + formatFields(fields))
+	}
+
+	log.Println("[" + level.String() + "] " + msg + formatFields(fields))
 }
 
 func (d defaultLogger) Debug(s string) {
-	log.Println("[DEBUG] " + s)
+	if d.Enabled(LogLevelDebug) {
+		log.Println("[DEBUG] " + s)
+	}
 }
 
 func (d defaultLogger) Debugf(s string, vals ...interface{}) {
-	log.Printf("[DEBUG] "+s+"\n", vals...)
+	if d.Enabled(LogLevelDebug) {
+		log.Printf("[DEBUG] "+s+"\n", vals...)
+	}
 }
 
 func (d defaultLogger) Info(s string) {
-	log.Println("[INFO] " + s)
+	if d.Enabled(LogLevelInfo) {
+		log.Println("[INFO] " + s)
+	}
 }
 
 func (d defaultLogger) Infof(s string, vals ...interface{}) {
-	log.Printf("[INFO] "+s+"\n", vals...)
+	if d.Enabled(LogLevelInfo) {
+		log.Printf("[INFO] "+s+"\n", vals...)
+	}
 }
 
 func (d defaultLogger) Warn(s string) {
-	log.Println("[WARN] " + s)
+	if d.Enabled(LogLevelWarn) {
+		log.Println("[WARN] " + s)
+	}
 }
 
 func (d defaultLogger) Warnf(s string, vals ...interface{}) {
-	log.Printf("[WARN] "+s+"\n", vals...)
+	if d.Enabled(LogLevelWarn) {
+		log.Printf("[WARN] "+s+"\n", vals...)
+	}
 }
 
 func (d defaultLogger) Error(s string) {
-	log.Println("[ERROR] " + s)
+	if d.Enabled(LogLevelError) {
+		log.Println("[ERROR] " + s)
+	}
 }
 
 func (d defaultLogger) Errorf(s string, vals ...interface{}) {
-	log.Printf("[ERROR] "+s+"\n", vals...)
+	if d.Enabled(LogLevelError) {
+		log.Printf("[ERROR] "+s+"\n", vals...)
+	}
 }
 
 func (d defaultLogger) Fatal(s string) {
@@ -113,6 +344,7 @@ func (d defaultLogger) Fatalf(s string, vals ...interface{}) {
 	log.Fatalf("[FATAL] "+s+"\n", vals...)
 }
 
+// GetDefaultLogger returns the default logger at debug level
 func GetDefaultLogger() Logger {
-	return &defaultLogger{}
+	return NewDefaultLogger(LogLevelDebug)
 }
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingLogger is a StructuredLogger keeping every message at or above level
+type recordingLogger struct {
+	defaultLogger
+	entries []recordedEntry
+}
+
+type recordedEntry struct {
+	level  LogLevel
+	msg    string
+	fields []Field
+}
+
+func (r *recordingLogger) Log(_ context.Context, level LogLevel, msg string, fields ...Field) {
+	r.entries = append(r.entries, recordedEntry{level: level, msg: msg, fields: fields})
+}
+
+// printfLogger is a Logger without structured support
+type printfLogger struct {
+	Logger
+	lines []string
+}
+
+func (p *printfLogger) Warn(s string) {
+	p.lines = append(p.lines, s)
+}
+
+func TestParseLogLevel(t *testing.T) {
+	req := require.New(t)
+
+	for name, expected := range map[string]LogLevel{
+		"":      LogLevelDebug,
+		"trace": LogLevelDebug,
+		"DEBUG": LogLevelDebug,
+		"info":  LogLevelInfo,
+		"Warn":  LogLevelWarn,
+		"error": LogLevelError,
+		"FATAL": LogLevelFatal,
+	} {
+		level, err := ParseLogLevel(name)
+		req.Nil(err, name)
+		req.Equal(expected, level, name)
+	}
+
+	_, err := ParseLogLevel("loud")
+	req.NotNil(err)
+}
+
+func TestDefaultLogger_Enabled(t *testing.T) {
+	req := require.New(t)
+
+	logger := NewDefaultLogger(LogLevelWarn)
+	req.False(logger.Enabled(LogLevelDebug))
+	req.False(logger.Enabled(LogLevelInfo))
+	req.True(logger.Enabled(LogLevelWarn))
+	req.True(logger.Enabled(LogLevelError))
+
+	conf := &Config{Host: "localhost", Port: 7687, LogLevel: "error"}
+	req.Nil(conf.validate())
+	req.False(conf.Logger.(StructuredLogger).Enabled(LogLevelWarn))
+	req.Equal(map[string]interface{}{"a": RedactedValue}, conf.ParamRedactor.RedactParams(map[string]interface{}{"a": 1}))
+
+	conf = &Config{Host: "localhost", Port: 7687, LogLevel: "loud"}
+	req.NotNil(conf.validate())
+}
+
+func TestSessionV2Impl_Log(t *testing.T) {
+	req := require.New(t)
+
+	logger := &recordingLogger{defaultLogger: defaultLogger{level: LogLevelInfo}}
+	sess := &SessionV2Impl{gogm: &Gogm{config: &Config{ParamRedactor: RedactAll}, logger: logger}, conf: SessionConfig{DatabaseName: "db"}}
+
+	ctx := ContextWithLogFields(context.Background(), LogField("request_id", "abc"))
+	ctx = context.WithValue(ctx, operationContextKey{}, "Load")
+	sess.log(ctx, LogLevelWarn, "something happened", LogField(LogFieldError, errors.New("boom")))
+
+	req.Len(logger.entries, 1)
+	req.Equal(LogLevelWarn, logger.entries[0].level)
+	req.Equal([]Field{
+		LogField("request_id", "abc"),
+		LogField(LogFieldDatabase, "db"),
+		LogField(LogFieldOperation, "Load"),
+		LogField(LogFieldError, errors.New("boom")),
+	}, logger.entries[0].fields)
+
+	// below the configured level
+	sess.logQuery(ctx, "MATCH (n) RETURN n", map[string]interface{}{"password": "secret"})
+	req.Len(logger.entries, 1)
+
+	logger.level = LogLevelDebug
+	sess.logQuery(ctx, "MATCH (n) RETURN n", map[string]interface{}{"password": "secret"})
+	req.Len(logger.entries, 2)
+	req.Contains(logger.entries[1].fields, LogField(LogFieldParams, map[string]interface{}{"password": RedactedValue}))
+
+	// printf loggers get the fields appended
+	printf := &printfLogger{Logger: GetDefaultLogger()}
+	sess.gogm.logger = printf
+	sess.log(context.Background(), LogLevelWarn, "something happened", LogField("attempt", 2))
+	req.Equal([]string{fmt.Sprintf("something happened %s=db attempt=2", LogFieldDatabase)}, printf.lines)
+}
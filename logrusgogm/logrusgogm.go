@@ -0,0 +1,117 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+// Package logrusgogm adapts a logrus logger to the gogm Logger and StructuredLogger interfaces
+package logrusgogm
+
+import (
+	"context"
+
+	"github.com/mindstand/gogm/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Logger implements gogm.StructuredLogger on top of a *logrus.Logger
+type Logger struct {
+	log *logrus.Logger
+}
+
+// New wraps l, if l is nil logrus.StandardLogger() is used
+func New(l *logrus.Logger) *Logger {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+
+	return &Logger{log: l}
+}
+
+func toLevel(level gogm.LogLevel) logrus.Level {
+	switch level {
+	case gogm.LogLevelDebug:
+		return logrus.DebugLevel
+	case gogm.LogLevelInfo:
+		return logrus.InfoLevel
+	case gogm.LogLevelWarn:
+		return logrus.WarnLevel
+	case gogm.LogLevelError:
+		return logrus.ErrorLevel
+	default:
+		return logrus.FatalLevel
+	}
+}
+
+// Enabled implements gogm.StructuredLogger
+func (l *Logger) Enabled(level gogm.LogLevel) bool {
+	return l.log.IsLevelEnabled(toLevel(level))
+}
+
+// Log implements gogm.StructuredLogger
+func (l *Logger) Log(ctx context.Context, level gogm.LogLevel, msg string, fields ...gogm.Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Value
+	}
+
+	entry := l.log.WithContext(ctx).WithFields(logrusFields)
+	if level == gogm.LogLevelFatal {
+		entry.Fatal(msg)
+		return
+	}
+
+	entry.Log(toLevel(level), msg)
+}
+
+func (l *Logger) Debug(s string) {
+	l.log.Debug(s)
+}
+
+func (l *Logger) Debugf(s string, vals ...interface{}) {
+	l.log.Debugf(s, vals...)
+}
+
+func (l *Logger) Info(s string) {
+	l.log.Info(s)
+}
+
+func (l *Logger) Infof(s string, vals ...interface{}) {
+	l.log.Infof(s, vals...)
+}
+
+func (l *Logger) Warn(s string) {
+	l.log.Warn(s)
+}
+
+func (l *Logger) Warnf(s string, vals ...interface{}) {
+	l.log.Warnf(s, vals...)
+}
+
+func (l *Logger) Error(s string) {
+	l.log.Error(s)
+}
+
+func (l *Logger) Errorf(s string, vals ...interface{}) {
+	l.log.Errorf(s, vals...)
+}
+
+func (l *Logger) Fatal(s string) {
+	l.log.Fatal(s)
+}
+
+func (l *Logger) Fatalf(s string, vals ...interface{}) {
+	l.log.Fatalf(s, vals...)
+}
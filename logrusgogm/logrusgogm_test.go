@@ -0,0 +1,52 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package logrusgogm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mindstand/gogm/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	req := require.New(t)
+
+	base, hook := test.NewNullLogger()
+	base.SetLevel(logrus.InfoLevel)
+	logger := New(base)
+
+	var _ gogm.StructuredLogger = logger
+	req.False(logger.Enabled(gogm.LogLevelDebug))
+	req.True(logger.Enabled(gogm.LogLevelWarn))
+
+	logger.Log(context.Background(), gogm.LogLevelDebug, "dropped")
+	logger.Log(context.Background(), gogm.LogLevelWarn, "running query", gogm.LogField(gogm.LogFieldDatabase, "neo4j"))
+	logger.Infof("mapped type %s", "Person")
+
+	entries := hook.AllEntries()
+	req.Len(entries, 2)
+	req.Equal(logrus.WarnLevel, entries[0].Level)
+	req.Equal("running query", entries[0].Message)
+	req.Equal("neo4j", entries[0].Data[gogm.LogFieldDatabase])
+	req.Equal("mapped type Person", entries[1].Message)
+}
@@ -88,6 +88,52 @@ func (_m *SessionV2) DeleteUUID(ctx context.Context, uuid string) error {
 	return r0
 }
 
+// ExplainLoad provides a mock function with given fields: ctx, respObj, id, depth, filter, params, pagination
+func (_m *SessionV2) ExplainLoad(ctx context.Context, respObj interface{}, id interface{}, depth int, filter go_cypherdsl.ConditionOperator, params map[string]interface{}, pagination *gogm.Pagination) (*gogm.QueryExplanation, error) {
+	ret := _m.Called(ctx, respObj, id, depth, filter, params, pagination)
+
+	var r0 *gogm.QueryExplanation
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, interface{}, int, go_cypherdsl.ConditionOperator, map[string]interface{}, *gogm.Pagination) *gogm.QueryExplanation); ok {
+		r0 = rf(ctx, respObj, id, depth, filter, params, pagination)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gogm.QueryExplanation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}, interface{}, int, go_cypherdsl.ConditionOperator, map[string]interface{}, *gogm.Pagination) error); ok {
+		r1 = rf(ctx, respObj, id, depth, filter, params, pagination)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExplainLoadAll provides a mock function with given fields: ctx, respObj, depth, filter, params, pagination
+func (_m *SessionV2) ExplainLoadAll(ctx context.Context, respObj interface{}, depth int, filter go_cypherdsl.ConditionOperator, params map[string]interface{}, pagination *gogm.Pagination) (*gogm.QueryExplanation, error) {
+	ret := _m.Called(ctx, respObj, depth, filter, params, pagination)
+
+	var r0 *gogm.QueryExplanation
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, int, go_cypherdsl.ConditionOperator, map[string]interface{}, *gogm.Pagination) *gogm.QueryExplanation); ok {
+		r0 = rf(ctx, respObj, depth, filter, params, pagination)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*gogm.QueryExplanation)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}, int, go_cypherdsl.ConditionOperator, map[string]interface{}, *gogm.Pagination) error); ok {
+		r1 = rf(ctx, respObj, depth, filter, params, pagination)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Load provides a mock function with given fields: ctx, respObj, id
 func (_m *SessionV2) Load(ctx context.Context, respObj interface{}, id interface{}) error {
 	ret := _m.Called(ctx, respObj, id)
@@ -200,6 +246,41 @@ func (_m *SessionV2) LoadDepthFilterPagination(ctx context.Context, respObj inte
 	return r0
 }
 
+// LoadMany provides a mock function with given fields: ctx, respSlice, ids, depth
+func (_m *SessionV2) LoadMany(ctx context.Context, respSlice interface{}, ids []interface{}, depth int) error {
+	ret := _m.Called(ctx, respSlice, ids, depth)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, []interface{}, int) error); ok {
+		r0 = rf(ctx, respSlice, ids, depth)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LoadRelations provides a mock function with given fields: ctx, obj, fieldNames
+func (_m *SessionV2) LoadRelations(ctx context.Context, obj interface{}, fieldNames ...string) error {
+	_va := make([]interface{}, len(fieldNames))
+	for _i := range fieldNames {
+		_va[_i] = fieldNames[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, obj)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, ...string) error); ok {
+		r0 = rf(ctx, obj, fieldNames...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ManagedTransaction provides a mock function with given fields: ctx, work
 func (_m *SessionV2) ManagedTransaction(ctx context.Context, work gogm.TransactionWork) error {
 	ret := _m.Called(ctx, work)
@@ -214,6 +295,29 @@ func (_m *SessionV2) ManagedTransaction(ctx context.Context, work gogm.Transacti
 	return r0
 }
 
+// PlanSave provides a mock function with given fields: ctx, obj, depth
+func (_m *SessionV2) PlanSave(ctx context.Context, obj interface{}, depth int) ([]gogm.SaveStatement, error) {
+	ret := _m.Called(ctx, obj, depth)
+
+	var r0 []gogm.SaveStatement
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, int) []gogm.SaveStatement); ok {
+		r0 = rf(ctx, obj, depth)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]gogm.SaveStatement)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}, int) error); ok {
+		r1 = rf(ctx, obj, depth)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Query provides a mock function with given fields: ctx, query, properties, respObj
 func (_m *SessionV2) Query(ctx context.Context, query string, properties map[string]interface{}, respObj interface{}) error {
 	ret := _m.Called(ctx, query, properties, respObj)
@@ -260,6 +364,20 @@ func (_m *SessionV2) QueryRaw(ctx context.Context, query string, properties map[
 	return r0, r1, r2
 }
 
+// Refresh provides a mock function with given fields: ctx, obj, depth
+func (_m *SessionV2) Refresh(ctx context.Context, obj interface{}, depth int) error {
+	ret := _m.Called(ctx, obj, depth)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}, int) error); ok {
+		r0 = rf(ctx, obj, depth)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Rollback provides a mock function with given fields: ctx
 func (_m *SessionV2) Rollback(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -0,0 +1,219 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package otelgogm backs the gogm Tracer and MetricsRecorder interfaces with OpenTelemetry.
+// Metrics can be exported to Prometheus with the OpenTelemetry Prometheus exporter
+package otelgogm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mindstand/gogm/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/global"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/mindstand/gogm/v2"
+
+// Tracer implements gogm.Tracer with an OpenTelemetry tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer returns a gogm.Tracer using tp. If tp is nil the global tracer provider is used
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	return &Tracer{
+		tracer: tp.Tracer(instrumentationName),
+	}
+}
+
+// Start implements gogm.Tracer
+func (t *Tracer) Start(ctx context.Context, operation string, attrs ...gogm.Attribute) (context.Context, gogm.Span) {
+	ctx, span := t.tracer.Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(convertAttributes(attrs)...))
+	return ctx, &Span{span: span}
+}
+
+// Span implements gogm.Span with an OpenTelemetry span
+type Span struct {
+	span trace.Span
+}
+
+// SetAttributes implements gogm.Span
+func (s *Span) SetAttributes(attrs ...gogm.Attribute) {
+	s.span.SetAttributes(convertAttributes(attrs)...)
+}
+
+// AddEvent implements gogm.Span
+func (s *Span) AddEvent(name string, attrs ...gogm.Attribute) {
+	s.span.AddEvent(name, trace.WithAttributes(convertAttributes(attrs)...))
+}
+
+// RecordError implements gogm.Span
+func (s *Span) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End implements gogm.Span
+func (s *Span) End() {
+	s.span.End()
+}
+
+// TraceId returns the id of the trace the span belongs to, gogm adds it to its log messages
+func (s *Span) TraceId() string {
+	sc := s.span.SpanContext()
+	if !sc.HasTraceID() {
+		return ""
+	}
+
+	return sc.TraceID().String()
+}
+
+func convertAttributes(attrs []gogm.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case string:
+			kvs = append(kvs, attribute.String(attr.Key, v))
+		case bool:
+			kvs = append(kvs, attribute.Bool(attr.Key, v))
+		case int:
+			kvs = append(kvs, attribute.Int(attr.Key, v))
+		case int64:
+			kvs = append(kvs, attribute.Int64(attr.Key, v))
+		case float64:
+			kvs = append(kvs, attribute.Float64(attr.Key, v))
+		case []string:
+			kvs = append(kvs, attribute.StringSlice(attr.Key, v))
+		case fmt.Stringer:
+			kvs = append(kvs, attribute.Stringer(attr.Key, v))
+		default:
+			kvs = append(kvs, attribute.String(attr.Key, fmt.Sprintf("%v", v)))
+		}
+	}
+
+	return kvs
+}
+
+// Metrics implements gogm.MetricsRecorder with OpenTelemetry instruments
+type Metrics struct {
+	queryDuration instrument.Float64Histogram
+	queryRows     instrument.Int64Histogram
+	queryErrors   instrument.Int64Counter
+	retries       instrument.Int64Counter
+	sessions      instrument.Int64UpDownCounter
+}
+
+// NewMetrics creates the gogm instruments with mp. If mp is nil the global meter provider is used
+func NewMetrics(mp metric.MeterProvider) (*Metrics, error) {
+	if mp == nil {
+		mp = global.MeterProvider()
+	}
+
+	meter := mp.Meter(instrumentationName)
+
+	queryDuration, err := meter.Float64Histogram("gogm.query.duration",
+		instrument.WithUnit("ms"),
+		instrument.WithDescription("duration of statements executed by gogm"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query duration histogram, %w", err)
+	}
+
+	queryRows, err := meter.Int64Histogram("gogm.query.rows",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("number of rows returned by statements executed by gogm"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query rows histogram, %w", err)
+	}
+
+	queryErrors, err := meter.Int64Counter("gogm.query.errors",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("number of statements executed by gogm that failed"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query error counter, %w", err)
+	}
+
+	retries, err := meter.Int64Counter("gogm.transaction.retries",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("number of managed transaction attempts that were retried"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create retry counter, %w", err)
+	}
+
+	sessions, err := meter.Int64UpDownCounter("gogm.sessions.active",
+		instrument.WithUnit("1"),
+		instrument.WithDescription("number of open gogm sessions, each holding a driver connection while in use"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session counter, %w", err)
+	}
+
+	return &Metrics{
+		queryDuration: queryDuration,
+		queryRows:     queryRows,
+		queryErrors:   queryErrors,
+		retries:       retries,
+		sessions:      sessions,
+	}, nil
+}
+
+// RecordQuery implements gogm.MetricsRecorder
+func (m *Metrics) RecordQuery(ctx context.Context, metric gogm.QueryMetric) {
+	attrs := []attribute.KeyValue{
+		attribute.String(gogm.AttributeDbSystem, "neo4j"),
+		attribute.String(gogm.AttributeDbName, metric.Database),
+		attribute.String(gogm.AttributeDbOperation, metric.Operation),
+	}
+
+	m.queryDuration.Record(ctx, float64(metric.Duration.Microseconds())/1000, attrs...)
+	m.queryRows.Record(ctx, int64(metric.Rows), attrs...)
+	if metric.Err != nil {
+		m.queryErrors.Add(ctx, 1, attrs...)
+	}
+}
+
+// RecordRetry implements gogm.MetricsRecorder
+func (m *Metrics) RecordRetry(ctx context.Context, metric gogm.RetryMetric) {
+	m.retries.Add(ctx, 1,
+		attribute.String(gogm.AttributeDbSystem, "neo4j"),
+		attribute.String(gogm.AttributeDbName, metric.Database),
+		attribute.String(gogm.AttributeDbOperation, metric.Operation),
+	)
+}
+
+// RecordSessions implements gogm.MetricsRecorder
+func (m *Metrics) RecordSessions(ctx context.Context, database string, delta int64) {
+	m.sessions.Add(ctx, delta,
+		attribute.String(gogm.AttributeDbSystem, "neo4j"),
+		attribute.String(gogm.AttributeDbName, database),
+	)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package otelgogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mindstand/gogm/v2"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	req := require.New(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracer := NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, span := tracer.Start(context.Background(), "gogm.SessionV2Impl.Load",
+		gogm.Attr(gogm.AttributeDbSystem, "neo4j"),
+		gogm.Attr(gogm.AttributeDbLabel, "Person"))
+	span.AddEvent("retry", gogm.Attr(gogm.AttributeRetryAttempt, 1))
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	ended := recorder.Ended()
+	req.Len(ended, 1)
+	req.Equal("gogm.SessionV2Impl.Load", ended[0].Name())
+	req.Contains(ended[0].Attributes(), attribute.String(gogm.AttributeDbSystem, "neo4j"))
+	req.Contains(ended[0].Attributes(), attribute.String(gogm.AttributeDbLabel, "Person"))
+	req.Equal(codes.Error, ended[0].Status().Code)
+	req.Equal("retry", ended[0].Events()[0].Name)
+}
+
+func TestConvertAttributes(t *testing.T) {
+	req := require.New(t)
+
+	kvs := convertAttributes([]gogm.Attribute{
+		gogm.Attr("string", "a"),
+		gogm.Attr("int", 1),
+		gogm.Attr("bool", true),
+		gogm.Attr("map", map[string]interface{}{"a": 1}),
+	})
+	req.Equal([]attribute.KeyValue{
+		attribute.String("string", "a"),
+		attribute.Int("int", 1),
+		attribute.Bool("bool", true),
+		attribute.String("map", "map[a:1]"),
+	}, kvs)
+}
+
+func TestMetrics(t *testing.T) {
+	req := require.New(t)
+
+	metrics, err := NewMetrics(nil)
+	req.Nil(err)
+
+	var _ gogm.MetricsRecorder = metrics
+	metrics.RecordQuery(context.Background(), gogm.QueryMetric{Operation: "Load", Rows: 2, Err: errors.New("boom")})
+	metrics.RecordRetry(context.Background(), gogm.RetryMetric{Operation: "ManagedTransaction", Attempt: 1})
+	metrics.RecordSessions(context.Background(), "neo4j", 1)
+}
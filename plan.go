@@ -0,0 +1,242 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// SaveStatement is a single cypher statement run by a save
+type SaveStatement struct {
+	Cypher string
+	Params map[string]interface{}
+}
+
+// PlanSave returns the statements SaveDepth would run to save obj, in the order they would run, without sending
+// anything to the database. Nodes that do not exist yet are given negative placeholder graph ids, which is how
+// they are referenced by the statements that relate them. Ids generated by a primary key strategy are kept on obj,
+// the graph ids and load maps are left untouched
+func (s *SessionV2Impl) PlanSave(ctx context.Context, obj interface{}, depth int) ([]SaveStatement, error) {
+	ctx, span := s.startSpan(ctx, "PlanSave", Attr(AttributeDbLabel, labelOf(obj)))
+	defer span.End()
+
+	// the plan fails where the save would, nothing runs so no session is opened to the database of obj
+	if _, err := s.routeDatabase(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	return s.gogm.PlanSave(ctx, obj, depth)
+}
+
+// PlanSave is SessionV2.PlanSave without a session. It does not need a connection to neo4j so it can be used
+// to check saves in unit tests
+func (g *Gogm) PlanSave(ctx context.Context, obj interface{}, depth int) ([]SaveStatement, error) {
+	if g == nil {
+		return nil, errors.New("gogm instance can not be nil")
+	}
+
+	scope, err := g.tenantScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := &planTransaction{}
+	_, err = saveWork(g, scope, obj, depth, nil, true)(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan save, %w", err)
+	}
+
+	return tx.statements, nil
+}
+
+// nodeSnapshot holds the values of the fields a save writes to a node
+type nodeSnapshot struct {
+	id, loadMap         reflect.Value
+	origId, origLoadMap reflect.Value
+}
+
+// snapshotNodes copies the graph id and load map of every node about to be saved
+func snapshotNodes(nodeRef map[uintptr]*reflect.Value) []nodeSnapshot {
+	snapshots := make([]nodeSnapshot, 0, len(nodeRef))
+	for _, val := range nodeRef {
+		node := reflect.Indirect(*val)
+		snapshot := nodeSnapshot{
+			id:      node.FieldByName(DefaultPrimaryKeyStrategy.FieldName),
+			loadMap: node.FieldByName("LoadMap"),
+		}
+
+		snapshot.origId = reflect.New(snapshot.id.Type()).Elem()
+		snapshot.origId.Set(snapshot.id)
+		snapshot.origLoadMap = reflect.New(snapshot.loadMap.Type()).Elem()
+		snapshot.origLoadMap.Set(snapshot.loadMap)
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots
+}
+
+// restoreNodes puts back the values taken by snapshotNodes
+func restoreNodes(snapshots []nodeSnapshot) {
+	for _, snapshot := range snapshots {
+		snapshot.id.Set(snapshot.origId)
+		snapshot.loadMap.Set(snapshot.origLoadMap)
+	}
+}
+
+// planTransaction records the statements run against it instead of sending them to neo4j. Its results are shaped
+// like the ones the save queries expect, node creates get back placeholder ids and relationship deletes report
+// every relationship as deleted
+type planTransaction struct {
+	statements []SaveStatement
+	lastId     int64
+}
+
+func (p *planTransaction) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	p.statements = append(p.statements, SaveStatement{
+		Cypher: cypher,
+		Params: params,
+	})
+
+	res := &planResult{}
+	rows, _ := params["rows"].([]interface{})
+	for _, r := range rows {
+		row, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if i, ok := row["i"]; ok {
+			p.lastId--
+			res.records = append(res.records, &neo4j.Record{
+				Keys:   []string{"i", "id"},
+				Values: []interface{}{i, p.lastId},
+			})
+		}
+
+		if ids, ok := row["endNodeIds"].([]int64); ok {
+			res.relsDeleted += len(ids)
+		}
+	}
+
+	return res, nil
+}
+
+func (p *planTransaction) Commit() error {
+	return nil
+}
+
+func (p *planTransaction) Rollback() error {
+	return nil
+}
+
+func (p *planTransaction) Close() error {
+	return nil
+}
+
+// planResult is the result of a statement run by planTransaction
+type planResult struct {
+	records     []*neo4j.Record
+	current     *neo4j.Record
+	relsDeleted int
+}
+
+func (p *planResult) Keys() ([]string, error) {
+	if len(p.records) == 0 {
+		return []string{}, nil
+	}
+
+	return p.records[0].Keys, nil
+}
+
+func (p *planResult) Next() bool {
+	if len(p.records) == 0 {
+		p.current = nil
+		return false
+	}
+
+	p.current, p.records = p.records[0], p.records[1:]
+	return true
+}
+
+func (p *planResult) NextRecord(record **neo4j.Record) bool {
+	ok := p.Next()
+	*record = p.current
+	return ok
+}
+
+func (p *planResult) PeekRecord(record **neo4j.Record) bool {
+	if len(p.records) == 0 {
+		return false
+	}
+
+	*record = p.records[0]
+	return true
+}
+
+func (p *planResult) Err() error {
+	return nil
+}
+
+func (p *planResult) Record() *neo4j.Record {
+	return p.current
+}
+
+func (p *planResult) Collect() ([]*neo4j.Record, error) {
+	records := p.records
+	p.records = nil
+	return records, nil
+}
+
+func (p *planResult) Single() (*neo4j.Record, error) {
+	if len(p.records) != 1 {
+		return nil, fmt.Errorf("expected a single record, found %d", len(p.records))
+	}
+
+	p.Next()
+	return p.current, nil
+}
+
+func (p *planResult) Consume() (neo4j.ResultSummary, error) {
+	p.records = nil
+	return &planSummary{counters: &planCounters{relsDeleted: p.relsDeleted}}, nil
+}
+
+// planSummary only reports the counters the save queries check
+type planSummary struct {
+	neo4j.ResultSummary
+	counters neo4j.Counters
+}
+
+func (p *planSummary) Counters() neo4j.Counters {
+	return p.counters
+}
+
+type planCounters struct {
+	neo4j.Counters
+	relsDeleted int
+}
+
+func (p *planCounters) RelationshipsDeleted() int {
+	return p.relsDeleted
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanSave(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+
+	aNew := &a{TestField: "new"}
+	bExisting := &b{
+		TestField: "existing",
+		BaseUUIDNode: BaseUUIDNode{
+			UUID: "b1uuid",
+			BaseNode: BaseNode{
+				Id: int64Ptr(5),
+				LoadMap: map[string]*RelationConfig{
+					"Single": {
+						Ids:          []int64{7},
+						RelationType: Single,
+					},
+				},
+			},
+		},
+	}
+	aNew.SingleA = bExisting
+	bExisting.Single = aNew
+
+	plan, err := gogm.PlanSave(context.Background(), bExisting, 1)
+	req.Nil(err)
+	req.Len(plan, 4)
+
+	// nodes are written before the relationships between them are changed
+	req.Contains(plan[0].Cypher, "CREATE(n:`a`)")
+	req.Contains(plan[1].Cypher, "MATCH (n:`b`) WHERE ID(n) = row.id")
+	req.Equal(int64(5), plan[1].Params["rows"].([]interface{})[0].(map[string]interface{})["id"])
+	req.Contains(plan[2].Cypher, "DELETE e")
+	req.Equal([]interface{}{map[string]interface{}{"startNodeId": int64(5), "endNodeIds": []int64{7}}}, plan[2].Params["rows"])
+	req.Contains(plan[3].Cypher, "MERGE (startNode)-[rel:test_rel]->(endNode)")
+
+	// the new node is related through its placeholder id
+	rel := plan[3].Params["rows"].([]interface{})[0].(map[string]interface{})
+	req.Equal(int64(5), rel["startNodeId"])
+	req.Equal(int64(-1), rel["endNodeId"])
+
+	// graph ids and load maps are left as they were
+	req.Nil(aNew.Id)
+	req.Nil(aNew.LoadMap)
+	req.Equal(int64(5), *bExisting.Id)
+	req.Equal([]int64{7}, bExisting.LoadMap["Single"].Ids)
+
+	// planning again gives the same statements
+	again, err := gogm.PlanSave(context.Background(), bExisting, 1)
+	req.Nil(err)
+	req.Len(again, 4)
+	for i := range plan {
+		req.Equal(plan[i].Cypher, again[i].Cypher)
+	}
+
+	_, err = gogm.PlanSave(context.Background(), *bExisting, 1)
+	req.NotNil(err)
+}
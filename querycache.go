@@ -0,0 +1,224 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"unicode"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+)
+
+// defaultLoadQueryCacheSize is the number of load queries kept when Config.LoadQueryCacheSize is not set
+const defaultLoadQueryCacheSize = 1000
+
+// loadQueryKind is what a load query loads
+type loadQueryKind int
+
+const (
+	// loadOne loads the node with one primary key
+	loadOne loadQueryKind = iota
+	// loadAll loads every node of a label
+	loadAll
+	// loadByIds loads the nodes with any of a list of primary keys
+	loadByIds
+)
+
+// tenantLabelPlaceholder stands in for the tenant label in cached queries, so all tenants share one entry.
+// It can not appear in generated cypher
+const tenantLabelPlaceholder = "\x00tenant\x00"
+
+// loadQueryKey is everything the cypher of a load query depends on. Values like ids, filter params and tenants are
+// bound separately, so they are not part of it
+type loadQueryKey struct {
+	label      string
+	kind       loadQueryKind
+	depth      int
+	strategy   LoadStrategy
+	filter     string
+	pagination Pagination
+	scope      string
+	limits     string
+}
+
+// newLoadQueryKey returns the key of a load query, false if the query should not be cached. Filters that can not be
+// built or that inline literals are not cached, every literal would add its own entry
+func newLoadQueryKey(strategy LoadStrategy, scope *tenantScope, limits *depthLimits, label string, kind loadQueryKind, depth int, filter dsl.ConditionOperator, pagination *Pagination) (loadQueryKey, bool) {
+	key := loadQueryKey{
+		label:    label,
+		kind:     kind,
+		depth:    depth,
+		strategy: strategy,
+		scope:    scope.shape(),
+		limits:   limits.shape(),
+	}
+
+	if filter != nil {
+		where, err := filter.Build()
+		if err != nil {
+			return key, false
+		}
+		key.filter = string(where)
+		if hasInlineLiterals(key.filter) || (scope.labels() != "" && strings.Contains(key.filter, scope.label)) {
+			return key, false
+		}
+	}
+
+	if pagination != nil {
+		key.pagination = *pagination
+	}
+
+	return key, true
+}
+
+// cachedLoadQuery returns the cypher of a load query from the cache, using build to generate it on a miss.
+// Label tenancy queries are cached with the tenant label replaced by tenantLabelPlaceholder
+func (g *Gogm) cachedLoadQuery(strategy LoadStrategy, scope *tenantScope, limits *depthLimits, label string, kind loadQueryKind, depth int, filter dsl.ConditionOperator, pagination *Pagination, build func() (string, error)) (string, error) {
+	key, ok := newLoadQueryKey(strategy, scope, limits, label, kind, depth, filter, pagination)
+	if !ok {
+		return build()
+	}
+
+	if scope.labels() == "" {
+		return g.queryCache.get(key, build)
+	}
+
+	cyp, err := g.queryCache.get(key, func() (string, error) {
+		cyp, err := build()
+		if err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(cyp, scope.label, tenantLabelPlaceholder), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(cyp, tenantLabelPlaceholder, scope.label), nil
+}
+
+// hasInlineLiterals reports whether a built where clause contains string, number or boolean literals.
+// Quoted names are skipped, params and names may contain digits
+func hasInlineLiterals(where string) bool {
+	quoted := false
+	word := ""
+	for _, r := range where {
+		if quoted {
+			quoted = r != '`'
+			continue
+		}
+
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '$' {
+			if word == "" && unicode.IsDigit(r) {
+				return true
+			}
+			word += string(r)
+			continue
+		}
+
+		if lower := strings.ToLower(word); lower == "true" || lower == "false" {
+			return true
+		}
+		word = ""
+
+		switch r {
+		case '`':
+			quoted = true
+		case '\'', '"':
+			return true
+		}
+	}
+
+	lower := strings.ToLower(word)
+	return lower == "true" || lower == "false"
+}
+
+// queryCache keeps the most recently used load queries, it is safe to use from multiple goroutines.
+// A nil cache does not cache anything
+type queryCache struct {
+	size    int
+	mu      sync.Mutex
+	order   *list.List
+	entries map[loadQueryKey]*list.Element
+}
+
+type queryCacheEntry struct {
+	key    loadQueryKey
+	cypher string
+}
+
+// newQueryCache returns a cache holding size queries, nil when size is below 1
+func newQueryCache(size int) *queryCache {
+	if size < 1 {
+		return nil
+	}
+
+	return &queryCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[loadQueryKey]*list.Element{},
+	}
+}
+
+// get returns the cached cypher for key, building and caching it on a miss
+func (c *queryCache) get(key loadQueryKey, build func() (string, error)) (string, error) {
+	if c == nil {
+		return build()
+	}
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		c.mu.Unlock()
+		return elem.Value.(*queryCacheEntry).cypher, nil
+	}
+	c.mu.Unlock()
+
+	cyp, err := build()
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		c.entries[key] = c.order.PushFront(&queryCacheEntry{key: key, cypher: cyp})
+		if c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+		}
+	}
+
+	return cyp, nil
+}
+
+// len returns the number of cached queries
+func (c *queryCache) len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
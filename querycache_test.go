@@ -0,0 +1,169 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	req := require.New(t)
+
+	builds := 0
+	build := func(cyp string) func() (string, error) {
+		return func() (string, error) {
+			builds++
+			return cyp, nil
+		}
+	}
+
+	cache := newQueryCache(2)
+	one, two, three := loadQueryKey{label: "one"}, loadQueryKey{label: "two"}, loadQueryKey{label: "three"}
+
+	cyp, err := cache.get(one, build("1"))
+	req.Nil(err)
+	req.Equal("1", cyp)
+	cyp, err = cache.get(one, build("other"))
+	req.Nil(err)
+	req.Equal("1", cyp)
+	req.Equal(1, builds)
+
+	// the least recently used query is evicted
+	_, err = cache.get(two, build("2"))
+	req.Nil(err)
+	_, err = cache.get(one, build("1"))
+	req.Nil(err)
+	_, err = cache.get(three, build("3"))
+	req.Nil(err)
+	req.Equal(2, cache.len())
+	req.Equal(3, builds)
+	_, err = cache.get(one, build("1"))
+	req.Nil(err)
+	req.Equal(3, builds)
+	_, err = cache.get(two, build("2"))
+	req.Nil(err)
+	req.Equal(4, builds)
+
+	// failed builds are not cached
+	_, err = cache.get(loadQueryKey{label: "bad"}, func() (string, error) {
+		return "", errors.New("bad")
+	})
+	req.NotNil(err)
+	req.Equal(2, cache.len())
+
+	// a nil cache always builds
+	var disabled *queryCache
+	req.Nil(newQueryCache(-1))
+	_, err = disabled.get(one, build("1"))
+	req.Nil(err)
+	req.Equal(5, builds)
+	req.Equal(0, disabled.len())
+}
+
+func TestSessionV2Impl_LoadQueryCache(t *testing.T) {
+	req := require.New(t)
+
+	g, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	g.queryCache = newQueryCache(defaultLoadQueryCacheSize)
+	sess := &SessionV2Impl{gogm: g}
+
+	// ids and filter values are params, so they share a query
+	cyp, params, err := sess.loadQuery(nil, nil, &a{}, "uuid1", 1, nil, nil, nil)
+	req.Nil(err)
+	again, params2, err := sess.loadQuery(nil, nil, &a{}, "uuid2", 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal(cyp, again)
+	req.Equal("uuid1", params["idprm"])
+	req.Equal("uuid2", params2["idprm"])
+	req.Equal(1, g.queryCache.len())
+
+	filter := func() dsl.ConditionOperator {
+		return dsl.C(&dsl.ConditionConfig{Name: "n", Field: "test_field", ConditionOperator: dsl.EqualToOperator, Check: dsl.ParamString("$value")})
+	}
+	_, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, filter(), map[string]interface{}{"value": "1"}, nil)
+	req.Nil(err)
+	_, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, filter(), map[string]interface{}{"value": "2"}, nil)
+	req.Nil(err)
+	req.Equal(2, g.queryCache.len())
+
+	// depth, filter shape and pagination change the query
+	_, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 2, filter(), nil, nil)
+	req.Nil(err)
+	cyp, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() RETURN p", cyp)
+	cyp, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, nil, nil, &Pagination{LimitPerPage: 5})
+	req.Nil(err)
+	req.Contains(cyp, "LIMIT 5")
+	req.Equal(5, g.queryCache.len())
+
+	// filters with inline literals are not cached
+	_, _, err = sess.loadAllQuery(nil, nil, &[]a{}, 1, dsl.C(&dsl.ConditionConfig{Name: "n", Field: "test_field", ConditionOperator: dsl.EqualToOperator, Check: "literal"}), nil, nil)
+	req.Nil(err)
+	req.Equal(5, g.queryCache.len())
+
+	// tenants share queries, the tenant is a param or swapped into the label
+	g.config.TenancyMode = TenancyProperty
+	g.config.TenantProperty = defaultTenantProperty
+	g.config.TenantLabelPrefix = defaultTenantLabelPrefix
+	for _, tenant := range []string{"acme", "globex"} {
+		scope, err := g.tenantScope(WithTenant(context.Background(), tenant))
+		req.Nil(err)
+		_, params, err := sess.loadAllQuery(scope, nil, &[]a{}, 1, nil, nil, nil)
+		req.Nil(err)
+		req.Equal(tenant, params[tenantParam])
+	}
+	req.Equal(6, g.queryCache.len())
+
+	g.config.TenancyMode = TenancyLabel
+	for _, tenant := range []string{"acme", "globex"} {
+		scope, err := g.tenantScope(WithTenant(context.Background(), tenant))
+		req.Nil(err)
+		cyp, _, err := sess.loadAllQuery(scope, nil, &[]a{}, 1, nil, nil, nil)
+		req.Nil(err)
+		req.Contains(cyp, "Tenant_"+tenant)
+		req.NotContains(cyp, tenantLabelPlaceholder)
+	}
+	req.Equal(7, g.queryCache.len())
+}
+
+func TestHasInlineLiterals(t *testing.T) {
+	req := require.New(t)
+
+	for where, literal := range map[string]bool{
+		"n.name = $name":                  false,
+		"n.field2 = $value_1 AND n:Label": false,
+		"n.`1 'quoted'` = $v":             false,
+		"n.name = 'a'":                    true,
+		`n.name = "a"`:                    true,
+		"n.count > 10":                    true,
+		"n.count IN [1, 2]":               true,
+		"n.flag = true":                   true,
+		"n.flag = FALSE AND n.x = $x":     true,
+	} {
+		req.Equal(literal, hasInlineLiterals(where), where)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxQueryStats bounds how many distinct statements the query stats keep, statements seen after that are not tracked
+const maxQueryStats = 1000
+
+// QueryObserver receives every statement gogm runs once its result has been read
+type QueryObserver interface {
+	ObserveQuery(ctx context.Context, query QueryMetric)
+}
+
+// QueryObserverFunc adapts a function to QueryObserver
+type QueryObserverFunc func(ctx context.Context, query QueryMetric)
+
+// ObserveQuery implements QueryObserver
+func (f QueryObserverFunc) ObserveQuery(ctx context.Context, query QueryMetric) {
+	f(ctx, query)
+}
+
+// QueryStat aggregates the executions of a normalized statement
+type QueryStat struct {
+	// Statement is the statement with literals replaced by ? and whitespace collapsed
+	Statement     string
+	Operations    []string
+	Count         int64
+	Errors        int64
+	Rows          int64
+	TotalDuration time.Duration
+	MinDuration   time.Duration
+	MaxDuration   time.Duration
+	// TotalServerDuration sums the ResultAvailableAfter and ResultConsumedAfter timings reported by the server
+	TotalServerDuration time.Duration
+}
+
+// MeanDuration returns the average duration of an execution
+func (q QueryStat) MeanDuration() time.Duration {
+	if q.Count == 0 {
+		return 0
+	}
+
+	return q.TotalDuration / time.Duration(q.Count)
+}
+
+var (
+	stringLiteralRegex = regexp.MustCompile(`'(?:[^'\\]|\\.)*'|"(?:[^"\\]|\\.)*"`)
+	numberLiteralRegex = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	whitespaceRegex    = regexp.MustCompile(`\s+`)
+)
+
+// normalizeStatement replaces string and number literals with ? and collapses whitespace so
+// executions of the same query with different literals are grouped together
+func normalizeStatement(statement string) string {
+	normalized := stringLiteralRegex.ReplaceAllString(statement, "?")
+	normalized = numberLiteralRegex.ReplaceAllString(normalized, "?")
+	normalized = whitespaceRegex.ReplaceAllString(normalized, " ")
+	return strings.TrimSpace(normalized)
+}
+
+// queryStats aggregates statements by their normalized form
+type queryStats struct {
+	mu    sync.Mutex
+	stats map[string]*QueryStat
+}
+
+func newQueryStats() *queryStats {
+	return &queryStats{
+		stats: map[string]*QueryStat{},
+	}
+}
+
+func (q *queryStats) add(query QueryMetric) {
+	statement := normalizeStatement(query.Statement)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stat, ok := q.stats[statement]
+	if !ok {
+		if len(q.stats) >= maxQueryStats {
+			return
+		}
+
+		stat = &QueryStat{
+			Statement:   statement,
+			MinDuration: query.Duration,
+		}
+		q.stats[statement] = stat
+	}
+
+	stat.Count++
+	if query.Err != nil {
+		stat.Errors++
+	}
+	stat.Rows += int64(query.Rows)
+	stat.TotalDuration += query.Duration
+	stat.TotalServerDuration += query.ResultAvailableAfter + query.ResultConsumedAfter
+	if query.Duration < stat.MinDuration {
+		stat.MinDuration = query.Duration
+	}
+	if query.Duration > stat.MaxDuration {
+		stat.MaxDuration = query.Duration
+	}
+	if query.Operation != "" && !containsString(stat.Operations, query.Operation) {
+		stat.Operations = append(stat.Operations, query.Operation)
+	}
+}
+
+// snapshot returns copies of the stats sorted by total duration, slowest first
+func (q *queryStats) snapshot() []QueryStat {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := make([]QueryStat, 0, len(q.stats))
+	for _, stat := range q.stats {
+		cp := *stat
+		cp.Operations = append([]string{}, stat.Operations...)
+		stats = append(stats, cp)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TotalDuration > stats[j].TotalDuration
+	})
+
+	return stats
+}
+
+func (q *queryStats) reset() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stats = map[string]*QueryStat{}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// QueryStats returns the statements gogm has run grouped by normalized statement, slowest first.
+// It returns nil unless Config.EnableQueryStats is set
+func (g *Gogm) QueryStats() []QueryStat {
+	if g.queryStats == nil {
+		return nil
+	}
+
+	return g.queryStats.snapshot()
+}
+
+// ResetQueryStats clears the statistics returned by QueryStats
+func (g *Gogm) ResetQueryStats() {
+	if g.queryStats != nil {
+		g.queryStats.reset()
+	}
+}
+
+// observeQuery hands a statement to the query observer and query stats, and logs it if it was slow
+func (s *SessionV2Impl) observeQuery(ctx context.Context, query QueryMetric, params map[string]interface{}) {
+	if observer := s.gogm.config.QueryObserver; observer != nil {
+		observer.ObserveQuery(ctx, query)
+	}
+
+	if s.gogm.queryStats != nil {
+		s.gogm.queryStats.add(query)
+	}
+
+	threshold := s.gogm.config.SlowQueryThreshold
+	if threshold <= 0 || query.Duration < threshold {
+		return
+	}
+
+	fields := []Field{
+		LogField(LogFieldStatement, query.Statement),
+		LogField("duration", query.Duration.String()),
+		LogField("rows", query.Rows),
+		LogField("result_available_after", query.ResultAvailableAfter.String()),
+		LogField("result_consumed_after", query.ResultConsumedAfter.String()),
+	}
+	if redactor := s.gogm.config.ParamRedactor; redactor != nil {
+		fields = append(fields, LogField(LogFieldParams, redactor.RedactParams(params)))
+	}
+	if query.Err != nil {
+		fields = append(fields, LogField(LogFieldError, query.Err))
+	}
+
+	s.log(ctx, LogLevelWarn, "slow query", fields...)
+}
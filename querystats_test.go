@@ -0,0 +1,131 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSummary reports fixed server timings
+type fakeSummary struct {
+	neo4j.ResultSummary
+	availableAfter, consumedAfter time.Duration
+}
+
+func (f *fakeSummary) ResultAvailableAfter() time.Duration {
+	return f.availableAfter
+}
+
+func (f *fakeSummary) ResultConsumedAfter() time.Duration {
+	return f.consumedAfter
+}
+
+func TestNormalizeStatement(t *testing.T) {
+	req := require.New(t)
+
+	req.Equal("MATCH (n:Person) WHERE n.name = ? AND n.age > ? RETURN n LIMIT ?",
+		normalizeStatement("MATCH (n:Person)\n\tWHERE n.name = 'bob' AND n.age > 21.5\n RETURN n LIMIT 10"))
+	req.Equal("MATCH p=(n)-[*?..?]-() WHERE n.uuid = $idprm RETURN p",
+		normalizeStatement("MATCH p=(n)-[*0..2]-() WHERE n.uuid = $idprm RETURN p"))
+	// identifiers keep their digits
+	req.Equal("MATCH (n1)-[r2]->(n3) RETURN n1", normalizeStatement("MATCH (n1)-[r2]->(n3) RETURN n1"))
+}
+
+func TestQueryStats(t *testing.T) {
+	req := require.New(t)
+
+	g := &Gogm{queryStats: newQueryStats()}
+	g.queryStats.add(QueryMetric{Operation: "Load", Statement: "MATCH (n) WHERE n.age = 1 RETURN n", Duration: 10 * time.Millisecond, Rows: 1})
+	g.queryStats.add(QueryMetric{Operation: "Query", Statement: "MATCH (n)  WHERE n.age = 2 RETURN n", Duration: 30 * time.Millisecond, Rows: 2, Err: errors.New("boom")})
+	g.queryStats.add(QueryMetric{Operation: "Save", Statement: "CREATE (n)", Duration: 5 * time.Millisecond})
+
+	stats := g.QueryStats()
+	req.Len(stats, 2)
+	req.Equal("MATCH (n) WHERE n.age = ? RETURN n", stats[0].Statement)
+	req.Equal([]string{"Load", "Query"}, stats[0].Operations)
+	req.EqualValues(2, stats[0].Count)
+	req.EqualValues(1, stats[0].Errors)
+	req.EqualValues(3, stats[0].Rows)
+	req.Equal(10*time.Millisecond, stats[0].MinDuration)
+	req.Equal(30*time.Millisecond, stats[0].MaxDuration)
+	req.Equal(20*time.Millisecond, stats[0].MeanDuration())
+	req.Equal("CREATE (n)", stats[1].Statement)
+
+	g.ResetQueryStats()
+	req.Empty(g.QueryStats())
+
+	// disabled
+	req.Nil((&Gogm{}).QueryStats())
+}
+
+func TestSessionV2Impl_ObserveQuery(t *testing.T) {
+	req := require.New(t)
+
+	var observed []QueryMetric
+	logger := &recordingLogger{defaultLogger: defaultLogger{level: LogLevelInfo}}
+	sess := &SessionV2Impl{
+		gogm: &Gogm{
+			config: &Config{
+				QueryObserver: QueryObserverFunc(func(ctx context.Context, query QueryMetric) {
+					observed = append(observed, query)
+				}),
+				SlowQueryThreshold: time.Hour,
+				ParamRedactor:      RedactAll,
+			},
+			logger:     logger,
+			queryStats: newQueryStats(),
+		},
+		conf: SessionConfig{DatabaseName: "db"},
+	}
+
+	ctx := context.WithValue(context.Background(), operationContextKey{}, "LoadAll")
+	tx := sess.observe(ctx, &fakeResultTx{rows: 2, summary: &fakeSummary{availableAfter: time.Millisecond, consumedAfter: 2 * time.Millisecond}})
+	res, err := tx.Run("MATCH (n) RETURN n", map[string]interface{}{"password": "secret"})
+	req.Nil(err)
+	for res.Next() {
+	}
+
+	req.Len(observed, 1)
+	req.Equal("LoadAll", observed[0].Operation)
+	req.Equal("db", observed[0].Database)
+	req.Equal(2, observed[0].Rows)
+	req.Equal(time.Millisecond, observed[0].ResultAvailableAfter)
+	req.Equal(2*time.Millisecond, observed[0].ResultConsumedAfter)
+	req.Len(sess.gogm.QueryStats(), 1)
+	req.Equal(3*time.Millisecond, sess.gogm.QueryStats()[0].TotalServerDuration)
+	// under the threshold
+	req.Empty(logger.entries)
+
+	sess.gogm.config.SlowQueryThreshold = time.Nanosecond
+	res, err = tx.Run("MATCH (n) RETURN n", map[string]interface{}{"password": "secret"})
+	req.Nil(err)
+	_, err = res.Consume()
+	req.Nil(err)
+
+	req.Len(logger.entries, 1)
+	req.Equal(LogLevelWarn, logger.entries[0].level)
+	req.Equal("slow query", logger.entries[0].msg)
+	req.Contains(logger.entries[0].fields, LogField(LogFieldParams, map[string]interface{}{"password": RedactedValue}))
+}
@@ -0,0 +1,301 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	// UnknownLabelWarning is the notification neo4j attaches to queries that use a label it has never seen
+	UnknownLabelWarning = "Neo.ClientNotification.Statement.UnknownLabelWarning"
+	// UnknownRelationshipTypeWarning is the notification neo4j attaches to queries that use a relationship type it has never seen
+	UnknownRelationshipTypeWarning = "Neo.ClientNotification.Statement.UnknownRelationshipTypeWarning"
+
+	// defaultQueryValidationDepth is used when Config.QueryValidationDepth is not set
+	defaultQueryValidationDepth = 1
+	// statementErrorPrefix prefixes the codes of errors neo4j returns for queries it can not compile
+	statementErrorPrefix = "Neo.ClientError.Statement."
+)
+
+// QueryValidationIssue is a generated load query that failed to compile or compiled with a warning
+type QueryValidationIssue struct {
+	Database string
+	// Label is the mapped type the query loads
+	Label    string
+	Strategy LoadStrategy
+	Depth    int
+	// All is true for the query that loads every node of the label, false for the query that loads one by id
+	All    bool
+	Cypher string
+	// Err is set when the query could not be generated or compiled
+	Err error
+	// Notification is set when the query compiled with an unknown label or relationship type warning
+	Notification *QueryNotification
+}
+
+// String describes the issue on a single line
+func (q QueryValidationIssue) String() string {
+	kind := "load one"
+	if q.All {
+		kind = "load all"
+	}
+
+	var problem string
+	if q.Err != nil {
+		problem = q.Err.Error()
+	} else if q.Notification != nil {
+		problem = fmt.Sprintf("%s: %s", q.Notification.Title, q.Notification.Description)
+	}
+
+	return fmt.Sprintf("%s query for %s (%s strategy, depth %d) on db '%s': %s", kind, q.Label, strategyName(q.Strategy), q.Depth, q.Database, problem)
+}
+
+// QueryValidationReport is the result of running the generated load queries under EXPLAIN
+type QueryValidationReport struct {
+	// Queries is the number of queries that were explained
+	Queries int
+	Issues  []QueryValidationIssue
+}
+
+// Errors returns the issues for queries that failed to compile
+func (q *QueryValidationReport) Errors() []QueryValidationIssue {
+	var issues []QueryValidationIssue
+	for _, issue := range q.Issues {
+		if issue.Err != nil {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// Warnings returns the issues for queries that compiled with a warning
+func (q *QueryValidationReport) Warnings() []QueryValidationIssue {
+	var issues []QueryValidationIssue
+	for _, issue := range q.Issues {
+		if issue.Err == nil {
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues
+}
+
+// HasErrors returns true if any query failed to compile
+func (q *QueryValidationReport) HasErrors() bool {
+	return len(q.Errors()) != 0
+}
+
+// queryExplainer runs cyp under EXPLAIN against db
+type queryExplainer func(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error)
+
+// ValidateQueries generates the load queries of every mapped node type for the path and schema load strategies, and the
+// subquery strategy when it is configured, at depths 0 to maxDepth
+// and runs them under EXPLAIN on every target database. Queries neo4j can not compile, and ones it warns use unknown
+// labels or relationship types, are returned in the report. The queries are planned but never executed
+func (g *Gogm) ValidateQueries(ctx context.Context, maxDepth int) (*QueryValidationReport, error) {
+	if g.isNoOp {
+		return nil, errors.New("gogm instance is no op. Unable to validate queries")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := g.ensureConnected(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateQueries(ctx, g, maxDepth, g.explainQuery)
+}
+
+// QueryValidationReport returns the report of the query validation run on startup when EnableQueryValidation is set,
+// nil otherwise
+func (g *Gogm) QueryValidationReport() *QueryValidationReport {
+	return g.queryValidation
+}
+
+// explainQuery is the queryExplainer used against neo4j
+func (g *Gogm) explainQuery(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+	sess, err := openSessionV2(g, SessionConfig{
+		AccessMode:   neo4j.AccessModeRead,
+		DatabaseName: db,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open session to db %s, %w", db, err)
+	}
+	defer sess.Close()
+
+	_, sum, err := sess.QueryRaw(ctx, "EXPLAIN "+cyp, params)
+	return sum, err
+}
+
+// initQueryValidation runs the startup query validation
+func (g *Gogm) initQueryValidation(ctx context.Context) error {
+	report, err := validateQueries(ctx, g, g.config.QueryValidationDepth, g.explainQuery)
+	if err != nil {
+		return err
+	}
+
+	g.queryValidation = report
+	for _, issue := range report.Issues {
+		g.log(ctx, LogLevelWarn, "query validation: "+issue.String(), LogField(LogFieldDatabase, issue.Database), LogField(LogFieldStatement, issue.Cypher))
+	}
+
+	if g.config.FailOnQueryValidation && report.HasErrors() {
+		return fmt.Errorf("%d of %d generated queries failed to compile, %w", len(report.Errors()), report.Queries, ErrValidation)
+	}
+
+	return nil
+}
+
+func validateQueries(ctx context.Context, g *Gogm, maxDepth int, explain queryExplainer) (*QueryValidationReport, error) {
+	if maxDepth < 0 {
+		return nil, errors.New("max depth can not be less than 0")
+	}
+
+	dbs := g.databases()
+	if g.boltMajorVersion < 4 || len(dbs) == 0 {
+		// neo4j 3 only has the default database
+		dbs = []string{""}
+	}
+
+	// the subquery strategy needs neo4j 4, so it is only checked when it is used
+	strategies := []LoadStrategy{PATH_LOAD_STRATEGY, SCHEMA_LOAD_STRATEGY}
+	if g.config.LoadStrategy == SUBQUERY_LOAD_STRATEGY {
+		strategies = append(strategies, SUBQUERY_LOAD_STRATEGY)
+	}
+
+	report := &QueryValidationReport{}
+	for _, t := range g.ogmTypes {
+		label := reflect.TypeOf(t).Elem().Name()
+		raw, ok := g.mappedTypes.Get(label)
+		if !ok {
+			return nil, fmt.Errorf("struct config not found for type %s, %w", label, ErrInternal)
+		}
+
+		// relationships are loaded with the nodes they connect
+		conf, ok := raw.(structDecoratorConfig)
+		if !ok || !conf.IsVertex {
+			continue
+		}
+
+		for _, strategy := range strategies {
+			for depth := 0; depth <= maxDepth; depth++ {
+				for _, all := range []bool{false, true} {
+					issue := QueryValidationIssue{
+						Label:    label,
+						Strategy: strategy,
+						Depth:    depth,
+						All:      all,
+					}
+
+					cyp, params, err := validationQuery(g, reflect.TypeOf(t).Elem(), strategy, depth, all)
+					if err != nil {
+						issue.Err = fmt.Errorf("failed to generate query, %w", err)
+						report.Issues = append(report.Issues, issue)
+						continue
+					}
+					issue.Cypher = cyp
+
+					for _, db := range dbs {
+						// neo4j 3 has a single database that every type lives in
+						if db != "" && !conf.inDatabase(db, g.config.TargetDbs) {
+							continue
+						}
+
+						issue := issue
+						issue.Database = db
+						report.Queries++
+
+						sum, err := explain(ctx, db, cyp, params)
+						if err != nil {
+							var neoErr *neo4j.Neo4jError
+							if !errors.As(err, &neoErr) || !strings.HasPrefix(neoErr.Code, statementErrorPrefix) {
+								return nil, fmt.Errorf("failed to explain %s query for %s, %w", strategyName(strategy), label, err)
+							}
+
+							issue.Err = err
+							report.Issues = append(report.Issues, issue)
+							continue
+						}
+
+						if sum == nil {
+							continue
+						}
+
+						for _, notification := range sum.Notifications() {
+							if notification.Code() != UnknownLabelWarning && notification.Code() != UnknownRelationshipTypeWarning {
+								continue
+							}
+
+							warning := issue
+							warning.Notification = &QueryNotification{
+								Code:        notification.Code(),
+								Title:       notification.Title(),
+								Description: notification.Description(),
+								Severity:    notification.Severity(),
+							}
+							report.Issues = append(report.Issues, warning)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// validationTenant is the tenant queries are validated for when tenancy is on
+const validationTenant = "gogm_validation"
+
+// validationQuery generates the load query for typ the same way sessions do, scoped to a representative tenant
+// when tenancy is on
+func validationQuery(g *Gogm, typ reflect.Type, strategy LoadStrategy, depth int, all bool) (string, map[string]interface{}, error) {
+	var scope *tenantScope
+	if g.config.TenancyMode != TenancyNone {
+		scope = g.scopeOf(validationTenant)
+	}
+
+	if all {
+		return g.loadAllQuery(strategy, scope, nil, reflect.New(reflect.SliceOf(typ)).Interface(), depth, nil, nil, nil)
+	}
+
+	return g.loadQuery(strategy, scope, nil, reflect.New(typ).Interface(), nil, depth, nil, nil, nil)
+}
+
+func strategyName(strategy LoadStrategy) string {
+	switch strategy {
+	case SCHEMA_LOAD_STRATEGY:
+		return "schema"
+	case SUBQUERY_LOAD_STRATEGY:
+		return "subquery"
+	default:
+		return "path"
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// cleanSummary is the summary of a query that compiled without notifications
+type cleanSummary struct {
+	neo4j.ResultSummary
+}
+
+func (cleanSummary) Notifications() []neo4j.Notification { return nil }
+
+func TestValidateQueries(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogm(&a{}, &b{}, &c{})
+	req.Nil(err)
+	gogm.config.TargetDbs = []string{"neo4j", "other"}
+
+	explained := map[string]int{}
+	explain := func(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+		explained[db]++
+		switch {
+		case strings.HasPrefix(cyp, "MATCH (n:b)"):
+			// schema queries for b do not compile on the other db
+			if db == "other" {
+				return nil, &neo4j.Neo4jError{Code: "Neo.ClientError.Statement.SyntaxError", Msg: "invalid input"}
+			}
+		case strings.HasPrefix(cyp, "MATCH (n:a) RETURN"):
+			// the load all schema query for a uses a label the db does not know
+			return &fakeProfileSummary{}, nil
+		}
+
+		if strings.Contains(cyp, "$idprm") {
+			req.Contains(params, "idprm")
+		}
+
+		return &cleanSummary{}, nil
+	}
+
+	report, err := validateQueries(context.Background(), gogm, 1, explain)
+	req.Nil(err)
+	// 2 node types, 2 strategies, 2 depths, load one and all, on 2 dbs. c is a relationship and is skipped
+	req.Equal(32, report.Queries)
+	req.Equal(16, explained["neo4j"])
+	req.Equal(16, explained["other"])
+	req.True(report.HasErrors())
+	// load one and all at depths 0 and 1
+	req.Len(report.Errors(), 4)
+	req.Len(report.Warnings(), 4)
+
+	for _, issue := range report.Errors() {
+		req.Equal("b", issue.Label)
+		req.Equal("other", issue.Database)
+		req.Equal(SCHEMA_LOAD_STRATEGY, issue.Strategy)
+		req.Nil(issue.Notification)
+		req.NotEmpty(issue.Cypher)
+	}
+
+	for _, warning := range report.Warnings() {
+		req.Equal("a", warning.Label)
+		req.Equal(SCHEMA_LOAD_STRATEGY, warning.Strategy)
+		req.True(warning.All)
+		req.Equal(UnknownLabelWarning, warning.Notification.Code)
+		req.Contains(warning.String(), "load all query for a (schema strategy")
+	}
+
+	// errors that are not about the query stop validation
+	_, err = validateQueries(context.Background(), gogm, 0, func(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+		return nil, errors.New("connection refused")
+	})
+	req.NotNil(err)
+
+	_, err = validateQueries(context.Background(), gogm, -1, explain)
+	req.NotNil(err)
+
+	// with tenancy on the queries are scoped to a tenant like the ones sessions run
+	gogm.config.TenancyMode = TenancyProperty
+	gogm.config.TenantProperty = "tenant"
+	report, err = validateQueries(context.Background(), gogm, 0, func(ctx context.Context, db, cyp string, params map[string]interface{}) (neo4j.ResultSummary, error) {
+		req.Contains(cyp, "$"+tenantParam)
+		req.Equal(validationTenant, params[tenantParam])
+		return &cleanSummary{}, nil
+	})
+	req.Nil(err)
+	req.False(report.HasErrors())
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import "strings"
+
+// RedactedValue replaces param values hidden by a ParamRedactor
+const RedactedValue = "[REDACTED]"
+
+// ParamRedactor decides how query params appear in logs and traces
+type ParamRedactor interface {
+	// RedactParams returns the params to log, it must not modify params
+	RedactParams(params map[string]interface{}) map[string]interface{}
+}
+
+// ParamRedactorFunc adapts a function to ParamRedactor
+type ParamRedactorFunc func(params map[string]interface{}) map[string]interface{}
+
+// RedactParams implements ParamRedactor
+func (f ParamRedactorFunc) RedactParams(params map[string]interface{}) map[string]interface{} {
+	return f(params)
+}
+
+var (
+	// RedactNone logs params as they are
+	// WARNING THIS IS A SECURITY RISK -- ONLY USE THIS FOR DEBUG
+	RedactNone ParamRedactor = ParamRedactorFunc(func(params map[string]interface{}) map[string]interface{} {
+		return params
+	})
+
+	// RedactAll keeps param names but hides every value
+	RedactAll ParamRedactor = ParamRedactorFunc(func(params map[string]interface{}) map[string]interface{} {
+		if params == nil {
+			return nil
+		}
+
+		redacted := make(map[string]interface{}, len(params))
+		for k := range params {
+			redacted[k] = RedactedValue
+		}
+		return redacted
+	})
+)
+
+// RedactKeys hides the values of params and nested properties whose name matches one of keys, ignoring case.
+// Everything else is logged as is
+func RedactKeys(keys ...string) ParamRedactor {
+	lookup := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		lookup[strings.ToLower(key)] = struct{}{}
+	}
+
+	return ParamRedactorFunc(func(params map[string]interface{}) map[string]interface{} {
+		if params == nil {
+			return nil
+		}
+
+		return redactMap(params, lookup)
+	})
+}
+
+func redactMap(m map[string]interface{}, keys map[string]struct{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if _, ok := keys[strings.ToLower(k)]; ok {
+			redacted[k] = RedactedValue
+			continue
+		}
+		redacted[k] = redactValue(v, keys)
+	}
+
+	return redacted
+}
+
+// redactValue descends into the maps and slices save queries use for their rows
+func redactValue(v interface{}, keys map[string]struct{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return redactMap(val, keys)
+	case []map[string]interface{}:
+		redacted := make([]map[string]interface{}, len(val))
+		for i, m := range val {
+			redacted[i] = redactMap(m, keys)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(val))
+		for i, elem := range val {
+			redacted[i] = redactValue(elem, keys)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParamRedactors(t *testing.T) {
+	req := require.New(t)
+
+	params := map[string]interface{}{
+		"uuid":     "abc",
+		"Password": "secret",
+		"rows": []interface{}{
+			map[string]interface{}{
+				"props": map[string]interface{}{
+					"name":  "test",
+					"token": "secret",
+				},
+			},
+		},
+	}
+
+	req.Equal(params, RedactNone.RedactParams(params))
+	req.Nil(RedactAll.RedactParams(nil))
+	req.Equal(map[string]interface{}{
+		"uuid":     RedactedValue,
+		"Password": RedactedValue,
+		"rows":     RedactedValue,
+	}, RedactAll.RedactParams(params))
+
+	req.Equal(map[string]interface{}{
+		"uuid":     "abc",
+		"Password": RedactedValue,
+		"rows": []interface{}{
+			map[string]interface{}{
+				"props": map[string]interface{}{
+					"name":  "test",
+					"token": RedactedValue,
+				},
+			},
+		},
+	}, RedactKeys("password", "TOKEN").RedactParams(params))
+
+	// the original params are left alone
+	req.Equal("secret", params["Password"])
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	// neo4j error codes the default retry classifier treats as retryable
+	deadlockErrorCode    = "Neo.TransientError.Transaction.DeadlockDetected"
+	notALeaderErrorCode  = "Neo.ClientError.Cluster.NotALeader"
+	readOnlyDbErrorCode  = "Neo.ClientError.General.ForbiddenOnReadOnlyDatabase"
+	transientErrorPrefix = "Neo.TransientError."
+	// terminated transactions were classified as transient before neo4j 5 but should never be retried
+	terminatedErrorCode        = "Neo.TransientError.Transaction.Terminated"
+	lockClientStoppedErrorCode = "Neo.TransientError.Transaction.LockClientStopped"
+)
+
+// RetryClassifier decides whether a failed transaction should be attempted again
+type RetryClassifier func(err error) bool
+
+// RetryPolicy defines how gogm retries managed transactions.
+// When no policy is set on the Config or the context, retries are left to the driver defaults
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the transaction is attempted, 0 means no limit
+	MaxAttempts int `yaml:"max_attempts" json:"max_attempts" mapstructure:"max_attempts"`
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration `yaml:"initial_backoff" json:"initial_backoff" mapstructure:"initial_backoff"`
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration `yaml:"max_backoff" json:"max_backoff" mapstructure:"max_backoff"`
+	// Multiplier grows the backoff after every attempt
+	Multiplier float64 `yaml:"multiplier" json:"multiplier" mapstructure:"multiplier"`
+	// Jitter randomizes each backoff by up to this fraction of it, must be between 0 and 1
+	Jitter float64 `yaml:"jitter" json:"jitter" mapstructure:"jitter"`
+	// MaxElapsedTime stops retrying once this much time has passed since the first attempt, 0 means no limit.
+	// Retries also stop when the context is done
+	MaxElapsedTime time.Duration `yaml:"max_elapsed_time" json:"max_elapsed_time" mapstructure:"max_elapsed_time"`
+	// Classifier decides which errors are retried, defaults to DefaultRetryClassifier
+	Classifier RetryClassifier `yaml:"-" json:"-" mapstructure:"-"`
+}
+
+// DefaultRetryPolicy returns a policy with sensible defaults for retrying managed transactions
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxElapsedTime: 30 * time.Second,
+		Classifier:     DefaultRetryClassifier,
+	}
+}
+
+// DefaultRetryClassifier retries deadlocks, transient errors, leader switches and lost connections
+func DefaultRetryClassifier(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var neoErr *neo4j.Neo4jError
+	if errors.As(err, &neoErr) {
+		switch neoErr.Code {
+		case terminatedErrorCode, lockClientStoppedErrorCode:
+			return false
+		case deadlockErrorCode, notALeaderErrorCode, readOnlyDbErrorCode:
+			return true
+		default:
+			return strings.HasPrefix(neoErr.Code, transientErrorPrefix)
+		}
+	}
+
+	var connErr *neo4j.ConnectivityError
+	return errors.As(err, &connErr)
+}
+
+// validate checks the policy and fills in defaults for unset values
+func (r *RetryPolicy) validate() error {
+	if r.MaxAttempts < 0 {
+		return errors.New("retry policy max attempts can not be less than 0")
+	}
+
+	if r.InitialBackoff < 0 || r.MaxBackoff < 0 || r.MaxElapsedTime < 0 {
+		return errors.New("retry policy durations can not be less than 0")
+	}
+
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return fmt.Errorf("retry policy jitter must be between 0 and 1, got %v", r.Jitter)
+	}
+
+	if r.Multiplier <= 0 {
+		r.Multiplier = 1
+	}
+
+	if r.Classifier == nil {
+		r.Classifier = DefaultRetryClassifier
+	}
+
+	return nil
+}
+
+// shouldRetry reports whether another attempt is allowed after attempt failed with err
+func (r *RetryPolicy) shouldRetry(err error, attempt int, elapsed time.Duration) bool {
+	if r.MaxAttempts > 0 && attempt >= r.MaxAttempts {
+		return false
+	}
+
+	if r.MaxElapsedTime > 0 && elapsed >= r.MaxElapsedTime {
+		return false
+	}
+
+	classifier := r.Classifier
+	if classifier == nil {
+		classifier = DefaultRetryClassifier
+	}
+
+	return classifier(err)
+}
+
+// backoff returns how long to wait after the given failed attempt, starting at 1
+func (r *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	wait := float64(r.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if r.MaxBackoff > 0 && wait > float64(r.MaxBackoff) {
+		wait = float64(r.MaxBackoff)
+	}
+
+	if r.Jitter > 0 {
+		wait += wait * r.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+
+	return time.Duration(wait)
+}
+
+type retryPolicyContextKey struct{}
+
+// WithRetryPolicy returns a copy of ctx that overrides the configured retry policy for managed transactions run with it
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, retryPolicyContextKey{}, policy)
+}
+
+// retryPolicy returns the policy from the context, falling back to the configured policy
+func (s *SessionV2Impl) retryPolicy(ctx context.Context) *RetryPolicy {
+	if ctx != nil {
+		if policy, ok := ctx.Value(retryPolicyContextKey{}).(*RetryPolicy); ok && policy != nil {
+			return policy
+		}
+	}
+
+	return s.gogm.config.RetryPolicy
+}
+
+// retryTransaction runs work in explicit transactions until it commits or the policy gives up
+func (s *SessionV2Impl) retryTransaction(ctx context.Context, span Span, policy *RetryPolicy, deadline time.Time, work TransactionWork) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := s.attemptTransaction(ctx, deadline, work)
+		if err == nil {
+			if attempt > 1 {
+				s.log(ctx, LogLevelDebug, "managed transaction succeeded after retrying", LogField("attempt", attempt))
+			}
+			return nil
+		}
+
+		if !policy.shouldRetry(err, attempt, time.Since(start)) {
+			if attempt > 1 {
+				return fmt.Errorf("giving up after %d attempts, %w", attempt, err)
+			}
+			return err
+		}
+
+		// an attempt started after the deadline would run without a timeout
+		wait := policy.backoff(attempt)
+		if time.Until(deadline) <= wait {
+			return fmt.Errorf("giving up after %d attempts, transaction deadline reached, %w", attempt, err)
+		}
+
+		s.log(ctx, LogLevelWarn, "managed transaction attempt failed, retrying",
+			LogField("attempt", attempt), LogField("backoff", wait.String()), LogField(LogFieldError, err))
+		span.AddEvent("retry", Attr(AttributeRetryAttempt, attempt), Attr(AttributeRetryBackoff, wait.String()))
+		span.RecordError(err)
+		if metrics := s.gogm.metrics(); metrics != nil {
+			metrics.RecordRetry(ctx, RetryMetric{
+				Operation: operationFromContext(ctx),
+				Database:  s.conf.DatabaseName,
+				Attempt:   attempt,
+				Backoff:   wait,
+				Err:       err,
+			})
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("context done while waiting to retry (%v), %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// attemptTransaction runs work once in an explicit transaction and commits it. It fails without starting the
+// transaction once the deadline has passed, a timeout of 0 would leave the transaction to the server default
+func (s *SessionV2Impl) attemptTransaction(ctx context.Context, deadline time.Time, work TransactionWork) error {
+	timeout := time.Until(deadline)
+	if timeout <= 0 {
+		return fmt.Errorf("transaction deadline reached, %w", context.DeadlineExceeded)
+	}
+
+	tx, err := s.neoSess.BeginTransaction(neo4j.WithTxTimeout(timeout))
+	if err != nil {
+		return err
+	}
+
+	s.tx = tx
+	s.pendingEvents.reset()
+	defer s.clearTx()
+
+	err = work(s)
+	if err != nil {
+		s.pendingEvents.reset()
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			s.log(ctx, LogLevelWarn, "failed to rollback transaction", LogField(LogFieldError, rollbackErr))
+		}
+		_ = tx.Close()
+		return err
+	}
+
+	err = tx.Commit()
+	_ = tx.Close()
+	return err
+}
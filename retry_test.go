@@ -0,0 +1,204 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNeoSession hands out fakeNeoTx transactions and counts them
+type fakeNeoSession struct {
+	neo4j.Session
+	begun         int
+	txs           []*fakeNeoTx
+	lastBookmarks []string
+}
+
+func (f *fakeNeoSession) BeginTransaction(...func(*neo4j.TransactionConfig)) (neo4j.Transaction, error) {
+	f.begun++
+	tx := &fakeNeoTx{}
+	f.txs = append(f.txs, tx)
+	return tx, nil
+}
+
+func (f *fakeNeoSession) LastBookmark() string {
+	if len(f.lastBookmarks) == 0 {
+		return ""
+	}
+	return f.lastBookmarks[len(f.lastBookmarks)-1]
+}
+
+func (f *fakeNeoSession) LastBookmarks() neo4j.Bookmarks {
+	return f.lastBookmarks
+}
+
+// fakeNeoTx records how the transaction was completed
+type fakeNeoTx struct {
+	neo4j.Transaction
+	committed, rolledBack bool
+}
+
+func (f *fakeNeoTx) Commit() error {
+	f.committed = true
+	return nil
+}
+
+func (f *fakeNeoTx) Rollback() error {
+	f.rolledBack = true
+	return nil
+}
+
+func (f *fakeNeoTx) Close() error {
+	return nil
+}
+
+func TestDefaultRetryClassifier(t *testing.T) {
+	req := require.New(t)
+
+	cases := map[string]bool{
+		deadlockErrorCode:   true,
+		notALeaderErrorCode: true,
+		readOnlyDbErrorCode: true,
+		"Neo.TransientError.General.DatabaseUnavailable": true,
+		terminatedErrorCode:                                 false,
+		lockClientStoppedErrorCode:                          false,
+		"Neo.ClientError.Schema.ConstraintValidationFailed": false,
+		"Neo.ClientError.Statement.SyntaxError":             false,
+	}
+
+	for code, expected := range cases {
+		err := fmt.Errorf("wrapped, %w", &neo4j.Neo4jError{Code: code})
+		req.Equal(expected, DefaultRetryClassifier(err), code)
+	}
+
+	req.False(DefaultRetryClassifier(nil))
+	req.False(DefaultRetryClassifier(errors.New("not a neo4j error")))
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	req := require.New(t)
+
+	policy := &RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	req.Nil(policy.validate())
+
+	req.Equal(100*time.Millisecond, policy.backoff(1))
+	req.Equal(400*time.Millisecond, policy.backoff(3))
+	req.Equal(time.Second, policy.backoff(10))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		wait := policy.backoff(2)
+		req.True(wait >= 100*time.Millisecond && wait <= 300*time.Millisecond, wait.String())
+	}
+
+	req.NotNil((&RetryPolicy{Jitter: 2}).validate())
+	req.NotNil((&RetryPolicy{MaxAttempts: -1}).validate())
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	req := require.New(t)
+
+	deadlock := &neo4j.Neo4jError{Code: deadlockErrorCode}
+	policy := &RetryPolicy{MaxAttempts: 3, MaxElapsedTime: time.Minute}
+	req.Nil(policy.validate())
+
+	req.True(policy.shouldRetry(deadlock, 1, 0))
+	req.False(policy.shouldRetry(deadlock, 3, 0), "max attempts reached")
+	req.False(policy.shouldRetry(deadlock, 1, time.Hour), "max elapsed time reached")
+	req.False(policy.shouldRetry(errors.New("not retryable"), 1, 0))
+}
+
+func TestSessionV2Impl_ManagedTransactionRetry(t *testing.T) {
+	req := require.New(t)
+
+	gogm, err := getTestGogmWithDefaultStructs()
+	req.Nil(err)
+	gogm.config.DefaultTransactionTimeout = time.Second
+
+	neoSess := &fakeNeoSession{}
+	sess := &SessionV2Impl{gogm: gogm, neoSess: neoSess, conf: SessionConfig{AccessMode: AccessModeWrite}}
+
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+	req.Nil(policy.validate())
+	ctx := WithRetryPolicy(context.Background(), policy)
+
+	// fails twice with a deadlock then succeeds
+	attempts := 0
+	req.Nil(sess.ManagedTransaction(ctx, func(tx TransactionV2) error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("failed to save, %w", &neo4j.Neo4jError{Code: deadlockErrorCode})
+		}
+		return nil
+	}))
+	req.Equal(3, attempts)
+	req.Equal(3, neoSess.begun)
+	req.True(neoSess.txs[0].rolledBack)
+	req.True(neoSess.txs[2].committed)
+	req.Nil(sess.tx)
+
+	// errors the classifier rejects are not retried
+	attempts = 0
+	err = sess.ManagedTransaction(ctx, func(tx TransactionV2) error {
+		attempts++
+		return errors.New("bad input")
+	})
+	req.NotNil(err)
+	req.Equal(1, attempts)
+
+	// the policy gives up after max attempts
+	attempts = 0
+	err = sess.ManagedTransaction(ctx, func(tx TransactionV2) error {
+		attempts++
+		return &neo4j.Neo4jError{Code: deadlockErrorCode}
+	})
+	req.NotNil(err)
+	req.Equal(3, attempts)
+	// no attempt is made once the deadline has passed, it would run without a timeout
+	attempts = 0
+	slow := WithRetryPolicy(context.Background(), &RetryPolicy{MaxAttempts: 5, InitialBackoff: 50 * time.Millisecond})
+	deadlineCtx, cancel := context.WithTimeout(slow, 20*time.Millisecond)
+	defer cancel()
+	err = sess.ManagedTransaction(deadlineCtx, func(tx TransactionV2) error {
+		attempts++
+		return &neo4j.Neo4jError{Code: deadlockErrorCode}
+	})
+	req.NotNil(err)
+	req.Contains(err.Error(), "deadline")
+	req.Equal(1, attempts)
+
+	begun := neoSess.begun
+	err = sess.attemptTransaction(context.Background(), time.Now().Add(-time.Second), func(tx TransactionV2) error {
+		return nil
+	})
+	req.True(errors.Is(err, context.DeadlineExceeded))
+	req.Equal(begun, neoSess.begun)
+}
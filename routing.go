@@ -0,0 +1,226 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	routingRoleRead  = "READ"
+	routingRoleWrite = "WRITE"
+	routingRoleRoute = "ROUTE"
+)
+
+type leaderContextKey struct{}
+
+// WithLeader returns a copy of ctx that routes every gogm call made with it to the cluster leader.
+// Use it to read your own writes without waiting for the readers to catch up
+func WithLeader(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, leaderContextKey{}, true)
+}
+
+// leaderForced returns true if ctx was created with WithLeader
+func leaderForced(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+
+	forced, ok := ctx.Value(leaderContextKey{}).(bool)
+	return ok && forced
+}
+
+// NewRoutedSessionV2 returns a SessionV2 that picks the cluster member for each call by itself.
+// AccessMode in conf is ignored. Load and Query calls made outside of a transaction are routed to
+// readers, while Save, Delete and explicit transactions are routed to the leader. Wrap the context
+// with WithLeader to send a read to the leader, or to run cypher that writes with Query or QueryRaw
+func (g *Gogm) NewRoutedSessionV2(conf SessionConfig) (SessionV2, error) {
+	if g.isNoOp {
+		return nil, errors.New("gogm instance is no op. Unable to create a new session. Please set global gogm with SetGlobalGogm() or create a new gogm instance")
+	}
+
+	conf.AccessMode = AccessModeWrite
+	sess, err := newSessionWithConfigV2(g, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.autoRoute = true
+	return sess, nil
+}
+
+// readsFromReplicas returns true if a Query or QueryRaw run outside of a transaction should go to a reader.
+// Raw cypher may write, so it only goes to readers on sessions opened with NewRoutedSessionV2
+func (s *SessionV2Impl) readsFromReplicas(ctx context.Context) bool {
+	return s.autoRoute && !leaderForced(ctx)
+}
+
+// autoTransaction returns the driver function used to run read work outside of a transaction
+func (s *SessionV2Impl) autoTransaction(ctx context.Context) func(neo4j.TransactionWork, ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	if leaderForced(ctx) {
+		return s.neoSess.WriteTransaction
+	}
+
+	return s.neoSess.ReadTransaction
+}
+
+// RoutingTable is the routing table the cluster hands out for a database
+type RoutingTable struct {
+	Database   string
+	TimeToLive time.Duration
+	Routers    []string
+	Readers    []string
+	Writers    []string
+}
+
+// RoutingTable fetches the current routing table for database from the cluster. An empty database
+// returns the routing table of the default database. Only servers speaking the routing protocol
+// (neo4j://) on Neo4j 4+ support it
+func (g *Gogm) RoutingTable(ctx context.Context, database string) (*RoutingTable, error) {
+	if g.isNoOp {
+		return nil, errors.New("gogm instance is no op. Please set global gogm with SetGlobalGogm() or create a new gogm instance")
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := g.ensureConnected(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if g.boltMajorVersion < 4 {
+		return nil, fmt.Errorf("routing table requires neo4j 4+, %w", ErrInvalidParams)
+	}
+
+	sess := g.driver.NewSession(neo4j.SessionConfig{
+		AccessMode:   neo4j.AccessModeRead,
+		DatabaseName: "system",
+	})
+	defer sess.Close()
+
+	var dbParam interface{}
+	if database != "" {
+		dbParam = database
+	}
+
+	res, err := sess.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		result, err := tx.Run("CALL dbms.routing.getRoutingTable($context, $database)", map[string]interface{}{
+			"context":  map[string]interface{}{},
+			"database": dbParam,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		record, err := result.Single()
+		if err != nil {
+			return nil, err
+		}
+
+		return record.Values, nil
+	})
+	if err != nil {
+		return nil, classifyError(g, fmt.Errorf("failed to get routing table, %w", err))
+	}
+
+	values, ok := res.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to cast %T to []interface{}", res)
+	}
+
+	table, err := parseRoutingTable(values)
+	if err != nil {
+		return nil, err
+	}
+
+	table.Database = database
+	return table, nil
+}
+
+// parseRoutingTable converts the ttl and servers columns returned by dbms.routing.getRoutingTable
+func parseRoutingTable(values []interface{}) (*RoutingTable, error) {
+	if len(values) != 2 {
+		return nil, fmt.Errorf("expected 2 routing table columns, got %v", len(values))
+	}
+
+	ttl, ok := values[0].(int64)
+	if !ok {
+		return nil, fmt.Errorf("failed to cast ttl %T to int64", values[0])
+	}
+
+	servers, ok := values[1].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to cast servers %T to []interface{}", values[1])
+	}
+
+	table := &RoutingTable{
+		TimeToLive: time.Duration(ttl) * time.Second,
+	}
+
+	for _, rawServer := range servers {
+		server, ok := rawServer.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to cast server %T to map[string]interface{}", rawServer)
+		}
+
+		role, ok := server["role"].(string)
+		if !ok {
+			return nil, fmt.Errorf("failed to cast role %T to string", server["role"])
+		}
+
+		rawAddresses, ok := server["addresses"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to cast addresses %T to []interface{}", server["addresses"])
+		}
+
+		addresses := make([]string, 0, len(rawAddresses))
+		for _, rawAddress := range rawAddresses {
+			address, ok := rawAddress.(string)
+			if !ok {
+				return nil, fmt.Errorf("failed to cast address %T to string", rawAddress)
+			}
+			addresses = append(addresses, address)
+		}
+
+		switch role {
+		case routingRoleRead:
+			table.Readers = append(table.Readers, addresses...)
+		case routingRoleWrite:
+			table.Writers = append(table.Writers, addresses...)
+		case routingRoleRoute:
+			table.Routers = append(table.Routers, addresses...)
+		default:
+			return nil, fmt.Errorf("unknown routing role %s", role)
+		}
+	}
+
+	return table, nil
+}
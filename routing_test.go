@@ -0,0 +1,124 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package gogm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// routeRecordingSession records the access mode of every auto transaction it is asked to run
+type routeRecordingSession struct {
+	fakeNeoSession
+	modes []neo4j.AccessMode
+}
+
+func (r *routeRecordingSession) ReadTransaction(neo4j.TransactionWork, ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	r.modes = append(r.modes, neo4j.AccessModeRead)
+	return [][]interface{}{}, nil
+}
+
+func (r *routeRecordingSession) WriteTransaction(neo4j.TransactionWork, ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	r.modes = append(r.modes, neo4j.AccessModeWrite)
+	return [][]interface{}{}, nil
+}
+
+func TestWithLeader(t *testing.T) {
+	req := require.New(t)
+
+	req.False(leaderForced(nil))
+	req.False(leaderForced(context.Background()))
+	req.True(leaderForced(WithLeader(context.Background())))
+}
+
+func TestSessionV2Impl_AutoRoute(t *testing.T) {
+	req := require.New(t)
+	gogm := &Gogm{config: &Config{}, logger: GetDefaultLogger()}
+
+	neoSess := &routeRecordingSession{}
+	sess := &SessionV2Impl{gogm: gogm, neoSess: neoSess, conf: SessionConfig{AccessMode: AccessModeWrite}, autoRoute: true}
+
+	ctx := context.Background()
+	var res []interface{}
+
+	// reads go to readers
+	req.Nil(sess.Query(ctx, "MATCH (n) RETURN n", nil, &res))
+	_, _, err := sess.QueryRaw(ctx, "MATCH (n) RETURN n", nil)
+	req.Nil(err)
+	req.Nil(sess.runReadOnly(ctx, "MATCH (n) RETURN n", nil, &res, 0))
+	req.Equal([]neo4j.AccessMode{neo4j.AccessModeRead, neo4j.AccessModeRead, neo4j.AccessModeRead}, neoSess.modes)
+
+	// forcing the leader sends reads to the leader
+	neoSess.modes = nil
+	leaderCtx := WithLeader(ctx)
+	req.Nil(sess.Query(leaderCtx, "MATCH (n) RETURN n", nil, &res))
+	_, _, err = sess.QueryRaw(leaderCtx, "MATCH (n) RETURN n", nil)
+	req.Nil(err)
+	req.Nil(sess.runReadOnly(leaderCtx, "MATCH (n) RETURN n", nil, &res, 0))
+	req.Equal([]neo4j.AccessMode{neo4j.AccessModeWrite, neo4j.AccessModeWrite, neo4j.AccessModeWrite}, neoSess.modes)
+
+	// writes always go to the leader
+	neoSess.modes = nil
+	req.Nil(sess.runWrite(ctx, func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, nil
+	}, nil))
+	req.Equal([]neo4j.AccessMode{neo4j.AccessModeWrite}, neoSess.modes)
+
+	// sessions without auto routing send raw cypher to the leader whatever their access mode, it may write
+	neoSess.modes = nil
+	sess.autoRoute = false
+	req.Nil(sess.Query(ctx, "MATCH (n) RETURN n", nil, &res))
+	sess.conf.AccessMode = AccessModeRead
+	req.Nil(sess.Query(ctx, "CREATE (n) RETURN n", nil, &res))
+	_, _, err = sess.QueryRaw(ctx, "CREATE (n) RETURN n", nil)
+	req.Nil(err)
+	req.Equal([]neo4j.AccessMode{neo4j.AccessModeWrite, neo4j.AccessModeWrite, neo4j.AccessModeWrite}, neoSess.modes)
+}
+
+func TestParseRoutingTable(t *testing.T) {
+	req := require.New(t)
+
+	table, err := parseRoutingTable([]interface{}{
+		int64(300),
+		[]interface{}{
+			map[string]interface{}{"addresses": []interface{}{"core1:7687"}, "role": "WRITE"},
+			map[string]interface{}{"addresses": []interface{}{"core2:7687", "replica1:7687"}, "role": "READ"},
+			map[string]interface{}{"addresses": []interface{}{"core1:7687", "core2:7687"}, "role": "ROUTE"},
+		},
+	})
+	req.Nil(err)
+	req.Equal(300*time.Second, table.TimeToLive)
+	req.Equal([]string{"core1:7687"}, table.Writers)
+	req.Equal([]string{"core2:7687", "replica1:7687"}, table.Readers)
+	req.Equal([]string{"core1:7687", "core2:7687"}, table.Routers)
+
+	_, err = parseRoutingTable([]interface{}{int64(300)})
+	req.NotNil(err)
+
+	_, err = parseRoutingTable([]interface{}{
+		int64(300),
+		[]interface{}{map[string]interface{}{"addresses": []interface{}{"core1:7687"}, "role": "UNKNOWN"}},
+	})
+	req.NotNil(err)
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 
 	dsl "github.com/mindstand/go-cypherdsl"
@@ -43,6 +44,16 @@ type nodeCreate struct {
 	IsNew bool
 }
 
+// removedRelation is a relationship deleted by a save
+type removedRelation struct {
+	// StartId is the graph id of the node whose relationship was removed
+	StartId int64
+	// Type is the type of the relationship
+	Type string
+	// End is the node on the other side of the relationship
+	End neo4j.Node
+}
+
 // relCreate holds configuration for nodes to link together
 type relCreate struct {
 	// start uuid of relationship
@@ -55,7 +66,15 @@ type relCreate struct {
 	Direction dsl.Direction
 }
 
-func saveDepth(gogm *Gogm, obj interface{}, depth int) neo4j.TransactionWork {
+// saveDepth builds the work that saves obj to the given depth
+// events for the changes made are added to events, which may be nil
+func saveDepth(gogm *Gogm, scope *tenantScope, obj interface{}, depth int, events *eventBuffer) neo4j.TransactionWork {
+	return saveWork(gogm, scope, obj, depth, events, false)
+}
+
+// saveWork is saveDepth with the option of a dry run, in which the graph ids and load maps written to the
+// nodes while saving are put back once the work returns. Nodes are saved to the tenant of scope, which may be nil
+func saveWork(gogm *Gogm, scope *tenantScope, obj interface{}, depth int, events *eventBuffer, dryRun bool) neo4j.TransactionWork {
 	return func(tx neo4j.Transaction) (interface{}, error) {
 		if obj == nil {
 			return nil, errors.New("obj can not be nil")
@@ -100,8 +119,13 @@ func saveDepth(gogm *Gogm, obj interface{}, depth int) neo4j.TransactionWork {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse struct, %w", err)
 		}
+
+		if dryRun {
+			defer restoreNodes(snapshotNodes(nodeRef))
+		}
+
 		// save/update nodes
-		err = createNodes(tx, nodes, nodeRef, nodeIdRef)
+		err = createNodes(tx, scope, nodes, nodeRef, nodeIdRef)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create nodes, %w", err)
 		}
@@ -136,31 +160,156 @@ func saveDepth(gogm *Gogm, obj interface{}, depth int) neo4j.TransactionWork {
 			reflect.Indirect(*val).FieldByName("LoadMap").Set(reflect.ValueOf(loadConf))
 		}
 
+		var removed []removedRelation
 		if len(dels) != 0 {
-			err := removeRelations(tx, dels)
+			removed, err = removeRelations(tx, dels)
 			if err != nil {
 				return nil, err
 			}
 		}
 
 		if len(relations) != 0 {
-			err := relateNodes(tx, relations, nodeIdRef)
+			err := relateNodes(tx, scope, relations, nodeIdRef)
 			if err != nil {
 				return nil, err
 			}
 		}
 
+		if events != nil {
+			events.add(generateSaveEvents(gogm, nodes, relations, removed, oldRels, nodeRef, nodeIdRef)...)
+		}
+
 		return obj, nil
 	}
 }
 
+// generateSaveEvents describes what a save changed once all of its queries have run
+func generateSaveEvents(gogm *Gogm, nodes map[string]map[uintptr]*nodeCreate, relations map[string][]*relCreate, removed []removedRelation,
+	oldRels map[uintptr]map[string]*RelationConfig, nodeRef map[uintptr]*reflect.Value, nodeIdRef map[uintptr]int64) []Event {
+	var events []Event
+
+	// ptr -> label so the loaded relationships of a node can be matched by type
+	labels := map[uintptr]string{}
+	for label, labelNodes := range nodes {
+		for ptr, node := range labelNodes {
+			labels[ptr] = label
+
+			eventType := NodeUpdated
+			if node.IsNew {
+				eventType = NodeCreated
+			}
+
+			events = append(events, Event{
+				Type:    eventType,
+				Label:   label,
+				Object:  valueToObject(nodeRef[ptr]),
+				GraphId: nodeIdRef[ptr],
+			})
+		}
+	}
+
+	// graph id -> ptr so removed relationships can be traced back to objects
+	idLookup := make(map[int64]uintptr, len(nodeIdRef))
+	for ptr, id := range nodeIdRef {
+		idLookup[id] = ptr
+	}
+
+	for _, rel := range removed {
+		// the other node may not have been loaded, then it is decoded from the database
+		related := valueToObject(nodeRef[idLookup[rel.End.Id]])
+		if _, ok := idLookup[rel.End.Id]; !ok {
+			_, related = nodeObject(gogm, rel.End)
+		}
+
+		events = append(events, Event{
+			Type:           RelationshipRemoved,
+			Label:          rel.Type,
+			Object:         valueToObject(nodeRef[idLookup[rel.StartId]]),
+			GraphId:        rel.StartId,
+			Related:        related,
+			RelatedGraphId: rel.End.Id,
+		})
+	}
+
+	for label, rels := range relations {
+		for _, rel := range rels {
+			startId, endId := nodeIdRef[rel.StartNodePtr], nodeIdRef[rel.EndNodePtr]
+
+			// relationships that were already loaded are merged, not created
+			if loadedRelation(gogm, labels, oldRels, rel.StartNodePtr, label, endId) ||
+				loadedRelation(gogm, labels, oldRels, rel.EndNodePtr, label, startId) {
+				continue
+			}
+
+			events = append(events, Event{
+				Type:           RelationshipCreated,
+				Label:          label,
+				Object:         valueToObject(nodeRef[rel.StartNodePtr]),
+				GraphId:        startId,
+				Related:        valueToObject(nodeRef[rel.EndNodePtr]),
+				RelatedGraphId: endId,
+			})
+		}
+	}
+
+	return events
+}
+
+// loadedRelation reports whether the node at ptr was loaded with a relationship of relType to the node otherId
+func loadedRelation(gogm *Gogm, labels map[uintptr]string, oldRels map[uintptr]map[string]*RelationConfig, ptr uintptr, relType string, otherId int64) bool {
+	loaded := oldRels[ptr]
+	if len(loaded) == 0 {
+		return false
+	}
+
+	raw, ok := gogm.mappedTypes.Get(labels[ptr])
+	if !ok {
+		return false
+	}
+
+	config, ok := raw.(structDecoratorConfig)
+	if !ok {
+		return false
+	}
+
+	for field, conf := range loaded {
+		if conf != nil && config.Fields[field].Relationship == relType && int64SliceContains(conf.Ids, otherId) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // relateNodes connects nodes together using edge config
-func relateNodes(transaction neo4j.Transaction, relations map[string][]*relCreate, lookup map[uintptr]int64) error {
+func relateNodes(transaction neo4j.Transaction, scope *tenantScope, relations map[string][]*relCreate, lookup map[uintptr]int64) error {
 	if len(relations) == 0 {
 		return errors.New("relations can not be nil or empty")
 	}
 
-	for label, rels := range relations {
+	// nodes of another tenant can not be related to
+	if scope != nil {
+		var ids []int64
+		for _, rels := range relations {
+			for _, rel := range rels {
+				ids = append(ids, lookup[rel.StartNodePtr], lookup[rel.EndNodePtr])
+			}
+		}
+
+		if err := scope.checkNodes(transaction, ids); err != nil {
+			return err
+		}
+	}
+
+	// run the labels in a stable order so saves are repeatable
+	labels := make([]string, 0, len(relations))
+	for label := range relations {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		rels := relations[label]
 		var _params []interface{}
 
 		if len(rels) == 0 {
@@ -258,10 +407,10 @@ func relateNodes(transaction neo4j.Transaction, relations map[string][]*relCreat
 	return nil
 }
 
-// removes relationships between specified nodes
-func removeRelations(transaction neo4j.Transaction, dels map[int64][]int64) error {
+// removes relationships between specified nodes, returning the relationships that were removed
+func removeRelations(transaction neo4j.Transaction, dels map[int64][]int64) ([]removedRelation, error) {
 	if len(dels) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	var params []interface{}
@@ -287,32 +436,49 @@ func removeRelations(transaction neo4j.Transaction, dels map[int64][]int64) erro
 			Name: "end",
 		}).Build()).
 		Cypher("WHERE id(start) = row.startNodeId and id(end) in row.endNodeIds").
+		Cypher("WITH row, e, end, type(e) AS type").
 		Delete(false, "e").
+		Cypher("RETURN row.startNodeId AS startId, type, end").
 		ToCypher()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	res, err := transaction.Run(cyq, map[string]interface{}{
 		"rows": params,
 	})
 	if err != nil {
-		return fmt.Errorf("%s: %w", err.Error(), ErrInternal)
-	} else if err = res.Err(); err != nil {
-		return fmt.Errorf("%s: %w", err.Error(), ErrInternal)
+		return nil, fmt.Errorf("failed to remove relationships, %w", err)
+	}
+
+	var removed []removedRelation
+	for res.Next() {
+		values := res.Record().Values
+		if len(values) != 3 {
+			continue
+		}
+
+		startId, _ := values[0].(int64)
+		relType, _ := values[1].(string)
+		end, _ := values[2].(neo4j.Node)
+		removed = append(removed, removedRelation{StartId: startId, Type: relType, End: end})
+	}
+
+	if err = res.Err(); err != nil {
+		return nil, fmt.Errorf("failed to remove relationships, %w", err)
 	}
 
 	summary, err := res.Consume()
 	if err != nil {
-		return fmt.Errorf("failed to consume result summary, %s: %w", err.Error(), ErrInternal)
+		return nil, fmt.Errorf("failed to consume result summary, %w", err)
 	}
 
 	actualRelsDeleted := summary.Counters().RelationshipsDeleted()
 	if expectedDels != actualRelsDeleted {
-		return fmt.Errorf("expected relationship deletions not equal to actual. Expected=%v|Actual=%v", expectedDels, actualRelsDeleted)
+		return nil, fmt.Errorf("expected relationship deletions not equal to actual. Expected=%v|Actual=%v", expectedDels, actualRelsDeleted)
 	}
 
-	return nil
+	return removed, nil
 }
 
 // calculates which relationships to delete
@@ -509,14 +675,24 @@ func generateCurRels(gogm *Gogm, parentPtr uintptr, current *reflect.Value, curr
 }
 
 // createNodes updates existing nodes and creates new nodes while also making a lookup table for ptr -> neoid
-func createNodes(transaction neo4j.Transaction, crNodes map[string]map[uintptr]*nodeCreate, nodeRef map[uintptr]*reflect.Value, nodeIdRef map[uintptr]int64) error {
-	for label, nodes := range crNodes {
+func createNodes(transaction neo4j.Transaction, scope *tenantScope, crNodes map[string]map[uintptr]*nodeCreate, nodeRef map[uintptr]*reflect.Value, nodeIdRef map[uintptr]int64) error {
+	// run the labels in a stable order so saves are repeatable
+	labels := make([]string, 0, len(crNodes))
+	for label := range crNodes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		nodes := crNodes[label]
 		// used when the id of the node hasn't been set yet
 		var i uint64 = 0
 		var nodesArr []*nodeCreate
 
 		var updateRows, newRows []interface{}
+		var updateIds []int64
 		for ptr, config := range nodes {
+			scope.stamp(config.Params)
 			row := map[string]interface{}{
 				"obj": config.Params,
 			}
@@ -524,6 +700,7 @@ func createNodes(transaction neo4j.Transaction, crNodes map[string]map[uintptr]*
 			if id, ok := nodeIdRef[ptr]; ok {
 				row["id"] = id
 				updateRows = append(updateRows, row)
+				updateIds = append(updateIds, id)
 			} else {
 				row["i"] = fmt.Sprintf("%d", i)
 				newRows = append(newRows, row)
@@ -537,7 +714,7 @@ func createNodes(transaction neo4j.Transaction, crNodes map[string]map[uintptr]*
 		if len(newRows) != 0 {
 			cyp, err := dsl.QB().
 				Cypher("UNWIND $rows as row").
-				Cypher(fmt.Sprintf("CREATE(n:`%s`)", label)).
+				Cypher(fmt.Sprintf("CREATE(n:`%s`%s)", label, scope.labels())).
 				Cypher("SET n += row.obj").
 				Return(false, dsl.ReturnPart{
 					Name:  "row.i",
@@ -607,6 +784,11 @@ func createNodes(transaction neo4j.Transaction, crNodes map[string]map[uintptr]*
 		// process stuff that we're updating
 		// dont need any data back from this other than did it work
 		if len(updateRows) != 0 {
+			// nodes of another tenant can not be updated
+			if err := scope.checkNodes(transaction, updateIds); err != nil {
+				return err
+			}
+
 			path, err := dsl.Path().V(dsl.V{
 				Name: "n",
 				Type: "`" + label + "`",
@@ -20,6 +20,7 @@
 package gogm
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
@@ -59,8 +60,12 @@ func newSession(gogm *Gogm, readonly bool) (*Session, error) {
 		return nil, errors.New("please set global gogm instance with SetGlobalGogm()")
 	}
 
-	if gogm.driver == nil {
-		return nil, errors.New("gogm driver not initialized")
+	if err := gogm.checkTenancyV1(); err != nil {
+		return nil, err
+	}
+
+	if err := gogm.ensureConnected(context.Background()); err != nil {
+		return nil, err
 	}
 
 	session := &Session{
@@ -99,8 +104,12 @@ func newSessionWithConfig(gogm *Gogm, conf SessionConfig) (*Session, error) {
 		return nil, errors.New("please set global gogm instance with SetGlobalGogm()")
 	}
 
-	if gogm.driver == nil {
-		return nil, errors.New("gogm driver not initialized")
+	if err := gogm.checkTenancyV1(); err != nil {
+		return nil, err
+	}
+
+	if err := gogm.ensureConnected(context.Background()); err != nil {
+		return nil, err
 	}
 	neoSess := gogm.driver.NewSession(neo4j.SessionConfig{
 		AccessMode:   conf.AccessMode,
@@ -194,6 +203,10 @@ func (s *Session) LoadDepthFilter(respObj interface{}, id string, depth int, fil
 }
 
 func (s *Session) LoadDepthFilterPagination(respObj interface{}, id string, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) error {
+	if depth == DepthInfinite {
+		return fmt.Errorf("DepthInfinite requires SessionV2, %w", ErrInvalidParams)
+	}
+
 	respType := reflect.TypeOf(respObj)
 
 	//validate type is ptr
@@ -225,12 +238,17 @@ func (s *Session) LoadDepthFilterPagination(respObj interface{}, id string, dept
 		if err != nil {
 			return err
 		}
+	case SUBQUERY_LOAD_STRATEGY:
+		query, err = subqueryLoadStrategyByKey(s.gogm, nil, varName, respObjName, "uuid", "uuid", false, false, depth, filter, pagination)
+		if err != nil {
+			return err
+		}
 	default:
 		return errors.New("unknown load strategy")
 	}
 
-	//if the query requires pagination, set that up
-	if pagination != nil {
+	//if the query requires pagination, set that up, subqueries paginate the root themselves
+	if pagination != nil && s.gogm.config.LoadStrategy != SUBQUERY_LOAD_STRATEGY {
 		if err = pagination.Paginate(query); err != nil {
 			return err
 		}
@@ -266,6 +284,10 @@ func (s *Session) LoadAllDepthFilter(respObj interface{}, depth int, filter dsl.
 }
 
 func (s *Session) LoadAllDepthFilterPagination(respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) error {
+	if depth == DepthInfinite {
+		return fmt.Errorf("DepthInfinite requires SessionV2, %w", ErrInvalidParams)
+	}
+
 	rawRespType := reflect.TypeOf(respObj)
 
 	if rawRespType.Kind() != reflect.Ptr {
@@ -309,12 +331,17 @@ func (s *Session) LoadAllDepthFilterPagination(respObj interface{}, depth int, f
 		if err != nil {
 			return err
 		}
+	case SUBQUERY_LOAD_STRATEGY:
+		query, err = SubqueryLoadStrategyMany(s.gogm, varName, respObjName, depth, filter, pagination)
+		if err != nil {
+			return err
+		}
 	default:
 		return errors.New("unknown load strategy")
 	}
 
-	//if the query requires pagination, set that up
-	if pagination != nil {
+	//if the query requires pagination, set that up, subqueries paginate the root themselves
+	if pagination != nil && s.gogm.config.LoadStrategy != SUBQUERY_LOAD_STRATEGY {
 		if err = pagination.Paginate(query); err != nil {
 			return err
 		}
@@ -414,7 +441,7 @@ func (s *Session) SaveDepth(saveObj interface{}, depth int) error {
 	}
 
 	// handle if in transaction
-	return s.runWrite(saveDepth(s.gogm, saveObj, depth))
+	return s.runWrite(saveDepth(s.gogm, nil, saveObj, depth, nil))
 }
 
 func (s *Session) Delete(deleteObj interface{}) error {
@@ -427,7 +454,7 @@ func (s *Session) Delete(deleteObj interface{}) error {
 	}
 
 	// handle if in transaction
-	workFunc, err := deleteNode(deleteObj)
+	workFunc, err := deleteNode(nil, deleteObj, nil)
 	if err != nil {
 		return fmt.Errorf("failed to generate work func for delete, %w", err)
 	}
@@ -441,7 +468,7 @@ func (s *Session) DeleteUUID(uuid string) error {
 	}
 
 	// handle if in transaction
-	return s.runWrite(deleteByUuids(uuid))
+	return s.runWrite(deleteByUuids(s.gogm, nil, nil, uuid))
 }
 
 func (s *Session) runWrite(work neo4j.TransactionWork) error {
@@ -26,8 +26,6 @@ import (
 	"reflect"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
-
 	dsl "github.com/mindstand/go-cypherdsl"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
@@ -39,6 +37,14 @@ type SessionV2Impl struct {
 	DefaultDepth int
 	conf         SessionConfig
 	lastBookmark string
+	// bookmarks are the bookmarks the session is causally up to date with
+	bookmarks []string
+	// pendingEvents holds events for the open transaction until it is committed
+	pendingEvents eventBuffer
+	// autoRoute sends reads outside of a transaction to readers, see NewRoutedSessionV2
+	autoRoute bool
+	// databaseSessions are opened for types that live in another database, see DatabaseNamer
+	databaseSessions map[string]*SessionV2Impl
 }
 
 func newSessionWithConfigV2(gogm *Gogm, conf SessionConfig) (*SessionV2Impl, error) {
@@ -50,32 +56,49 @@ func newSessionWithConfigV2(gogm *Gogm, conf SessionConfig) (*SessionV2Impl, err
 		return nil, errors.New("please set global gogm instance with SetGlobalGogm()")
 	}
 
+	// lazy instances connect when their first session is opened
+	err := gogm.ensureConnected(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	return openSessionV2(gogm, conf)
+}
+
+// openSessionV2 opens a session on the driver of gogm without connecting it first, startup opens its sessions
+// with it while it connects
+func openSessionV2(gogm *Gogm, conf SessionConfig) (*SessionV2Impl, error) {
 	if gogm.driver == nil {
 		return nil, errors.New("gogm driver not initialized")
 	}
 
+	bookmarks := conf.Bookmarks
+	if manager := gogm.BookmarkManager(); manager != nil {
+		bookmarks = combineBookmarks(conf.Bookmarks, manager.GetBookmarks(gogm.sessionDatabase(conf.DatabaseName)))
+	}
+
 	neoSess := gogm.driver.NewSession(neo4j.SessionConfig{
 		AccessMode:   conf.AccessMode,
-		Bookmarks:    conf.Bookmarks,
+		Bookmarks:    bookmarks,
 		DatabaseName: conf.DatabaseName,
 		FetchSize:    neo4j.FetchDefault,
 	})
 
-	return &SessionV2Impl{
+	sess := &SessionV2Impl{
 		neoSess:      neoSess,
 		DefaultDepth: defaultDepth,
 		conf:         conf,
 		gogm:         gogm,
-	}, nil
+		bookmarks:    bookmarks,
+	}
+	sess.recordSessions(1)
+
+	return sess, nil
 }
+
 func (s *SessionV2Impl) Begin(ctx context.Context) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Begin")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Begin")
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
@@ -88,20 +111,16 @@ func (s *SessionV2Impl) Begin(ctx context.Context) error {
 	var err error
 	s.tx, err = s.neoSess.BeginTransaction()
 	if err != nil {
-		return err
+		return classifyError(s.gogm, err)
 	}
 
+	s.gogm.stats.addTransactions(1)
 	return nil
 }
 
 func (s *SessionV2Impl) Rollback(ctx context.Context) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Rollback")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Rollback")
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
@@ -111,9 +130,12 @@ func (s *SessionV2Impl) Rollback(ctx context.Context) error {
 		return fmt.Errorf("cannot rollback nil transaction: %w", ErrTransaction)
 	}
 
+	// events are only published for committed work
+	s.pendingEvents.reset()
+
 	err := s.tx.Rollback()
 	if err != nil {
-		return err
+		return classifyError(s.gogm, err)
 	}
 
 	err = s.tx.Close()
@@ -122,17 +144,13 @@ func (s *SessionV2Impl) Rollback(ctx context.Context) error {
 	}
 
 	s.tx = nil
+	s.gogm.stats.addTransactions(-1)
 	return nil
 }
 
 func (s *SessionV2Impl) RollbackWithError(ctx context.Context, originalError error) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.RollbackWithError")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "RollbackWithError")
+	defer span.End()
 
 	err := s.Rollback(ctx)
 	if err != nil {
@@ -143,13 +161,8 @@ func (s *SessionV2Impl) RollbackWithError(ctx context.Context, originalError err
 }
 
 func (s *SessionV2Impl) Commit(ctx context.Context) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Commit")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Commit")
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
@@ -161,7 +174,8 @@ func (s *SessionV2Impl) Commit(ctx context.Context) error {
 
 	err := s.tx.Commit()
 	if err != nil {
-		return err
+		s.pendingEvents.reset()
+		return classifyError(s.gogm, err)
 	}
 
 	err = s.tx.Close()
@@ -170,59 +184,73 @@ func (s *SessionV2Impl) Commit(ctx context.Context) error {
 	}
 
 	s.tx = nil
+	s.gogm.stats.addTransactions(-1)
+	s.updateBookmarks()
+	s.publishPendingEvents(ctx)
 	return nil
 }
 
 func (s *SessionV2Impl) Load(ctx context.Context, respObj, id interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Load")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Load", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 
 	return s.LoadDepthFilterPagination(ctx, respObj, id, s.DefaultDepth, nil, nil, nil)
 }
 
 func (s *SessionV2Impl) LoadDepth(ctx context.Context, respObj, id interface{}, depth int) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadDepth")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "LoadDepth", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 
 	return s.LoadDepthFilterPagination(ctx, respObj, id, depth, nil, nil, nil)
 }
 
 func (s *SessionV2Impl) LoadDepthFilter(ctx context.Context, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadDepthFilter")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "LoadDepthFilter", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 
 	return s.LoadDepthFilterPagination(ctx, respObj, id, depth, filter, params, nil)
 }
 
 func (s *SessionV2Impl) LoadDepthFilterPagination(ctx context.Context, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadDepthFilterPagination")
-		defer span.Finish()
-	} else {
-		span = nil
+	ctx, span := s.startSpan(ctx, "LoadDepthFilterPagination", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, respObj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
 	}
 
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return err
+	}
+
+	cyp, params, err := s.loadQuery(scope, limits, respObj, id, depth, filter, params, pagination)
+	if err != nil {
+		return err
+	}
+
+	return sess.runReadOnly(ctx, cyp, params, respObj, limits.nodeLimit())
+}
+
+// loadQuery generates the cypher and params LoadDepthFilterPagination runs
+// The query is limited to the nodes in scope, which may be nil. Limits is only set for DepthInfinite
+func (s *SessionV2Impl) loadQuery(scope *tenantScope, limits *depthLimits, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (string, map[string]interface{}, error) {
+	return s.gogm.loadQuery(s.gogm.config.LoadStrategy, scope, limits, respObj, id, depth, filter, params, pagination)
+}
+
+// loadQuery generates the cypher and params of loading the node with id using strategy
+func (g *Gogm) loadQuery(strategy LoadStrategy, scope *tenantScope, limits *depthLimits, respObj, id interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (string, map[string]interface{}, error) {
 	respType := reflect.TypeOf(respObj)
 
 	//validate type is ptr
-	if respType.Kind() != reflect.Ptr {
-		return errors.New("respObj must be type ptr")
+	if respType == nil || respType.Kind() != reflect.Ptr {
+		return "", nil, errors.New("respObj must be type ptr")
 	}
 
 	//"deref" reflect interface type
@@ -234,33 +262,58 @@ func (s *SessionV2Impl) LoadDepthFilterPagination(ctx context.Context, respObj,
 	//will need to keep track of these variables
 	varName := "n"
 
-	var query dsl.Cypher
-	var err error
-
 	paramName := "idprm"
-	isGraphId := s.gogm.pkStrategy.StrategyName == DefaultPrimaryKeyStrategy.StrategyName
-	field := s.gogm.pkStrategy.DBName
-	//make the query based off of the load strategy
-	switch s.gogm.config.LoadStrategy {
-	case PATH_LOAD_STRATEGY:
-		query, err = PathLoadStrategyOne(varName, respObjName, field, paramName, isGraphId, depth, filter)
-		if err != nil {
-			return err
+	build := func() (string, error) {
+		var query dsl.Cypher
+		var err error
+
+		isGraphId := g.pkStrategy.StrategyName == DefaultPrimaryKeyStrategy.StrategyName
+		field := g.pkStrategy.DBName
+		if limits != nil {
+			// unlimited depth can not be expressed by the load strategies
+			query, err = infiniteDepthLoadByKey(scope, limits, varName, respObjName, field, paramName, isGraphId, false, scope.filter(varName, filter, false), pagination)
+			if err != nil {
+				return "", err
+			}
+			return query.ToCypher()
 		}
-	case SCHEMA_LOAD_STRATEGY:
-		query, err = SchemaLoadStrategyOne(s.gogm, varName, respObjName, field, paramName, isGraphId, depth, filter)
-		if err != nil {
-			return err
+
+		//make the query based off of the load strategy
+		switch strategy {
+		case PATH_LOAD_STRATEGY:
+			query, err = PathLoadStrategyOne(varName, respObjName, field, paramName, isGraphId, depth, scope.filter(varName, filter, true))
+			if err != nil {
+				return "", err
+			}
+		case SCHEMA_LOAD_STRATEGY:
+			query, err = schemaLoadStrategyByKey(g, scope, varName, respObjName, field, paramName, isGraphId, false, depth, scope.filter(varName, filter, false))
+			if err != nil {
+				return "", err
+			}
+		case SUBQUERY_LOAD_STRATEGY:
+			// the root is paginated before it is expanded
+			query, err = subqueryLoadStrategyByKey(g, scope, varName, respObjName, field, paramName, isGraphId, false, depth, scope.filter(varName, filter, false), pagination)
+			if err != nil {
+				return "", err
+			}
+			return query.ToCypher()
+		default:
+			return "", errors.New("unknown load strategy")
 		}
-	default:
-		return errors.New("unknown load strategy")
-	}
 
-	//if the query requires pagination, set that up
-	if pagination != nil {
-		if err = pagination.Paginate(query); err != nil {
-			return err
+		//if the query requires pagination, set that up
+		if pagination != nil {
+			if err = pagination.Paginate(query); err != nil {
+				return "", err
+			}
 		}
+
+		return query.ToCypher()
+	}
+
+	cyp, err := g.cachedLoadQuery(strategy, scope, limits, respObjName, loadOne, depth, filter, pagination, build)
+	if err != nil {
+		return "", nil, err
 	}
 
 	if params == nil {
@@ -270,61 +323,68 @@ func (s *SessionV2Impl) LoadDepthFilterPagination(ctx context.Context, respObj,
 	} else {
 		params[paramName] = id
 	}
+	params = limits.params(scope.params(params))
 
-	cyp, err := query.ToCypher()
-	if err != nil {
-		return err
-	}
-
-	return s.runReadOnly(ctx, cyp, params, respObj)
+	return cyp, params, nil
 }
 
 func (s *SessionV2Impl) LoadAll(ctx context.Context, respObj interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadAll")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "LoadAll", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 	return s.LoadAllDepthFilterPagination(ctx, respObj, s.DefaultDepth, nil, nil, nil)
 }
 
 func (s *SessionV2Impl) LoadAllDepth(ctx context.Context, respObj interface{}, depth int) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadAllDepth")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "LoadAllDepth", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 	return s.LoadAllDepthFilterPagination(ctx, respObj, depth, nil, nil, nil)
 }
 
 func (s *SessionV2Impl) LoadAllDepthFilter(ctx context.Context, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadAllDepthFilter")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "LoadAllDepthFilter", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
 	return s.LoadAllDepthFilterPagination(ctx, respObj, depth, filter, params, nil)
 }
 
 func (s *SessionV2Impl) LoadAllDepthFilterPagination(ctx context.Context, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.LoadAllDepthFilterPagination")
-		defer span.Finish()
-	} else {
-		span = nil
+	ctx, span := s.startSpan(ctx, "LoadAllDepthFilterPagination", Attr(AttributeDbLabel, labelOf(respObj)))
+	defer span.End()
+
+	sess, err := s.routedSession(ctx, respObj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	limits, err := s.gogm.depthLimits(ctx, depth)
+	if err != nil {
+		return err
+	}
+
+	cyp, params, err := s.loadAllQuery(scope, limits, respObj, depth, filter, params, pagination)
+	if err != nil {
+		return err
 	}
 
+	return sess.runReadOnly(ctx, cyp, params, respObj, limits.nodeLimit())
+}
+
+// loadAllQuery generates the cypher and params LoadAllDepthFilterPagination runs.
+// The query is limited to the nodes in scope, which may be nil. Limits is only set for DepthInfinite
+func (s *SessionV2Impl) loadAllQuery(scope *tenantScope, limits *depthLimits, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (string, map[string]interface{}, error) {
+	return s.gogm.loadAllQuery(s.gogm.config.LoadStrategy, scope, limits, respObj, depth, filter, params, pagination)
+}
+
+// loadAllQuery generates the cypher and params of loading every node of a label using strategy
+func (g *Gogm) loadAllQuery(strategy LoadStrategy, scope *tenantScope, limits *depthLimits, respObj interface{}, depth int, filter dsl.ConditionOperator, params map[string]interface{}, pagination *Pagination) (string, map[string]interface{}, error) {
 	rawRespType := reflect.TypeOf(respObj)
 
-	if rawRespType.Kind() != reflect.Ptr {
-		return fmt.Errorf("respObj must be a pointer to a slice, instead it is %T", respObj)
+	if rawRespType == nil || rawRespType.Kind() != reflect.Ptr {
+		return "", nil, fmt.Errorf("respObj must be a pointer to a slice, instead it is %T", respObj)
 	}
 
 	//deref to a slice
@@ -332,7 +392,7 @@ func (s *SessionV2Impl) LoadAllDepthFilterPagination(ctx context.Context, respOb
 
 	//validate type is ptr
 	if respType.Kind() != reflect.Slice {
-		return fmt.Errorf("respObj must be type slice, instead it is %T", respObj)
+		return "", nil, fmt.Errorf("respObj must be type slice, instead it is %T", respObj)
 	}
 
 	//"deref" reflect interface type
@@ -349,121 +409,163 @@ func (s *SessionV2Impl) LoadAllDepthFilterPagination(ctx context.Context, respOb
 	//will need to keep track of these variables
 	varName := "n"
 
-	var query dsl.Cypher
-	var err error
+	build := func() (string, error) {
+		var query dsl.Cypher
+		var err error
 
-	//make the query based off of the load strategy
-	switch s.gogm.config.LoadStrategy {
-	case PATH_LOAD_STRATEGY:
-		query, err = PathLoadStrategyMany(varName, respObjName, depth, filter)
-		if err != nil {
-			return err
+		if limits != nil {
+			// unlimited depth can not be expressed by the load strategies
+			query, err = infiniteDepthLoadMany(scope, limits, varName, respObjName, scope.filter(varName, filter, false), pagination)
+			if err != nil {
+				return "", err
+			}
+			return query.ToCypher()
 		}
-	case SCHEMA_LOAD_STRATEGY:
-		query, err = SchemaLoadStrategyMany(s.gogm, varName, respObjName, depth, filter)
-		if err != nil {
-			return err
+
+		//make the query based off of the load strategy
+		switch strategy {
+		case PATH_LOAD_STRATEGY:
+			query, err = PathLoadStrategyMany(varName, respObjName, depth, scope.filter(varName, filter, true))
+			if err != nil {
+				return "", err
+			}
+		case SCHEMA_LOAD_STRATEGY:
+			query, err = schemaLoadStrategyMany(g, scope, varName, respObjName, depth, scope.filter(varName, filter, false))
+			if err != nil {
+				return "", err
+			}
+		case SUBQUERY_LOAD_STRATEGY:
+			// the root is paginated before it is expanded
+			query, err = subqueryLoadStrategyMany(g, scope, varName, respObjName, depth, scope.filter(varName, filter, false), pagination)
+			if err != nil {
+				return "", err
+			}
+			return query.ToCypher()
+		default:
+			return "", errors.New("unknown load strategy")
 		}
-	default:
-		return errors.New("unknown load strategy")
-	}
 
-	//if the query requires pagination, set that up
-	if pagination != nil {
-		if err = pagination.Paginate(query); err != nil {
-			return err
+		//if the query requires pagination, set that up
+		if pagination != nil {
+			if err = pagination.Paginate(query); err != nil {
+				return "", err
+			}
 		}
+
+		return query.ToCypher()
 	}
 
-	cyp, err := query.ToCypher()
+	cyp, err := g.cachedLoadQuery(strategy, scope, limits, respObjName, loadAll, depth, filter, pagination, build)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
-	return s.runReadOnly(ctx, cyp, params, respObj)
+	return cyp, limits.params(scope.params(params)), nil
 }
 
-func (s *SessionV2Impl) runReadOnly(ctx context.Context, cyp string, params map[string]interface{}, respObj interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.runReadOnly")
-		defer span.Finish()
-		span.LogKV("query", cyp)
-		if s.gogm.config.EnableLogParams {
-			s.gogm.logger.Warn("logging params")
-			span.LogKV("params", params)
-		}
-	} else {
-		span = nil
+// runReadOnly runs cyp in a read transaction and decodes the result into respObj. The load fails when the result has
+// more than maxNodes nodes, unless maxNodes is 0
+func (s *SessionV2Impl) runReadOnly(ctx context.Context, cyp string, params map[string]interface{}, respObj interface{}, maxNodes int) (err error) {
+	ctx, span := s.startSpan(ctx, "runReadOnly", Attr(AttributeDbStatement, cyp))
+	defer span.End()
+	defer func() {
+		span.RecordError(err)
+	}()
+	s.traceParams(span, params)
+	s.logQuery(ctx, cyp, params)
+
+	txSess, err := s.transactionSession(ctx)
+	if err != nil {
+		return err
 	}
 
 	// if in tx, run normally else run in managed tx
-	if s.tx != nil {
-		if span != nil {
-			span.LogKV("info", "running in existing transaction")
+	if txSess != nil {
+		span.AddEvent("running in existing transaction")
+		tx := s.observe(ctx, txSess.tx)
+		defer tx.flush()
+		result, err := tx.Run(profileStatement(ctx, cyp), params)
+		if err != nil {
+			return classifyError(s.gogm, err)
 		}
-		result, err := s.tx.Run(cyp, params)
+
+		err = decodeLimited(s.gogm, result, respObj, maxNodes)
 		if err != nil {
 			return err
 		}
 
-		return decode(s.gogm, result, respObj)
+		if profilerFromContext(ctx) != nil {
+			sum, err := result.Consume()
+			if err != nil {
+				return classifyError(s.gogm, err)
+			}
+			recordProfile(ctx, cyp, params, sum)
+		}
+
+		return nil
 	}
 	// run inside managed transaction if not already in a transaction
-	if span != nil {
-		span.LogKV("info", "running in driver managed transaction")
-	}
-	_, err := s.neoSess.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		res, err := tx.Run(cyp, params)
+	span.AddEvent("running in driver managed transaction")
+	defer s.trackTransaction()()
+	var sum neo4j.ResultSummary
+	_, err = s.autoTransaction(ctx)(func(neoTx neo4j.Transaction) (interface{}, error) {
+		tx := s.observe(ctx, neoTx)
+		defer tx.flush()
+		res, err := tx.Run(profileStatement(ctx, cyp), params)
 		if err != nil {
 			return nil, err
 		}
 
-		return nil, decode(s.gogm, res, respObj)
+		err = decodeLimited(s.gogm, res, respObj, maxNodes)
+		if err != nil {
+			return nil, err
+		}
+
+		if profilerFromContext(ctx) != nil {
+			sum, err = res.Consume()
+		}
+		return nil, err
 	}, neo4j.WithTxTimeout(time.Until(s.getDeadline(ctx))))
 	if err != nil {
-		return fmt.Errorf("failed auto read tx, %w", err)
+		return classifyError(s.gogm, fmt.Errorf("failed auto read tx, %w", err))
 	}
 
+	recordProfile(ctx, cyp, params, sum)
 	return nil
 }
 
 func (s *SessionV2Impl) Save(ctx context.Context, saveObj interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Save")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Save", Attr(AttributeDbLabel, labelOf(saveObj)))
+	defer span.End()
 
 	return s.SaveDepth(ctx, saveObj, s.DefaultDepth)
 }
 
 func (s *SessionV2Impl) SaveDepth(ctx context.Context, saveObj interface{}, depth int) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.SaveDepth")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "SaveDepth", Attr(AttributeDbLabel, labelOf(saveObj)))
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
 	}
 
-	return s.runWrite(ctx, saveDepth(s.gogm, saveObj, depth))
+	sess, err := s.routedSession(ctx, saveObj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	events := &eventBuffer{}
+	return sess.runWrite(ctx, saveDepth(s.gogm, scope, saveObj, depth, events), events)
 }
 
 func (s *SessionV2Impl) Delete(ctx context.Context, deleteObj interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Delete")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Delete", Attr(AttributeDbLabel, labelOf(deleteObj)))
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
@@ -473,83 +575,116 @@ func (s *SessionV2Impl) Delete(ctx context.Context, deleteObj interface{}) error
 		return errors.New("deleteObj can not be nil")
 	}
 
+	sess, err := s.routedSession(ctx, deleteObj)
+	if err != nil {
+		return err
+	}
+
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
 	// handle if in transaction
-	workFunc, err := deleteNode(deleteObj)
+	events := &eventBuffer{}
+	workFunc, err := deleteNode(scope, deleteObj, events)
 	if err != nil {
 		return fmt.Errorf("failed to generate work func for delete, %w", err)
 	}
 
-	return s.runWrite(ctx, workFunc)
+	return sess.runWrite(ctx, workFunc, events)
 }
 
 func (s *SessionV2Impl) DeleteUUID(ctx context.Context, uuid string) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.DeleteUUID")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "DeleteUUID")
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
 	}
 
+	scope, err := s.gogm.tenantScope(ctx)
+	if err != nil {
+		return err
+	}
+
 	// handle if in transaction
-	return s.runWrite(ctx, deleteByUuids(uuid))
+	events := &eventBuffer{}
+	return s.runWrite(ctx, deleteByUuids(s.gogm, scope, events, uuid), events)
 }
 
-func (s *SessionV2Impl) runWrite(ctx context.Context, work neo4j.TransactionWork) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.runWrite")
-		defer span.Finish()
-	} else {
-		span = nil
+// runWrite runs work in the open transaction or a managed write transaction
+// events recorded by work are published once the transaction commits, events may be nil
+func (s *SessionV2Impl) runWrite(ctx context.Context, work neo4j.TransactionWork, events *eventBuffer) (err error) {
+	ctx, span := s.startSpan(ctx, "runWrite")
+	defer span.End()
+	defer func() {
+		span.RecordError(err)
+	}()
+
+	txSess, err := s.transactionSession(ctx)
+	if err != nil {
+		return err
 	}
 
 	// if already in a transaction
-	if s.tx != nil {
-		_, err := work(s.tx)
+	if txSess != nil {
+		tx := s.observe(ctx, txSess.tx)
+		_, err := work(tx)
+		tx.flush()
 		if err != nil {
-			return fmt.Errorf("failed to save in manual tx, %w", err)
+			return classifyError(s.gogm, fmt.Errorf("failed to save in manual tx, %w", err))
+		}
+
+		if events != nil {
+			txSess.pendingEvents.add(events.events...)
 		}
 
 		return nil
 	}
 
-	s.gogm.logger.Debug("running in managed write transaction")
+	s.log(ctx, LogLevelDebug, "running in managed write transaction")
+	defer s.trackTransaction()()
 	duration := time.Until(s.getDeadline(ctx))
 	if duration < 0 {
 		duration = 0
 	}
-	_, err := s.neoSess.WriteTransaction(work, neo4j.WithTxTimeout(duration))
+	_, err = s.neoSess.WriteTransaction(func(neoTx neo4j.Transaction) (interface{}, error) {
+		// the driver may retry work, only keep events from the last attempt
+		events.reset()
+		tx := s.observe(ctx, neoTx)
+		defer tx.flush()
+		return work(tx)
+	}, neo4j.WithTxTimeout(duration))
 	if err != nil {
-		return fmt.Errorf("failed to save in auto transaction, %w", err)
+		return classifyError(s.gogm, fmt.Errorf("failed to save in auto transaction, %w", err))
+	}
+
+	s.updateBookmarks()
+	if events != nil {
+		s.gogm.publishEvents(ctx, events.events)
 	}
 
 	return nil
 }
 
+// publishPendingEvents hands events from a committed transaction to the gogm listeners
+func (s *SessionV2Impl) publishPendingEvents(ctx context.Context) {
+	events := s.pendingEvents.events
+	s.pendingEvents.reset()
+	s.gogm.publishEvents(ctx, events)
+}
+
 func (s *SessionV2Impl) Query(ctx context.Context, query string, properties map[string]interface{}, respObj interface{}) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.Query")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "Query", Attr(AttributeDbStatement, query))
+	defer span.End()
 
 	if s.neoSess == nil {
 		return errors.New("neo4j connection not initialized")
 	}
 
-	if s.conf.AccessMode == neo4j.AccessModeRead {
-		return s.runReadOnly(ctx, query, properties, respObj)
-	}
-
-	if s.conf.AccessMode == AccessModeRead {
-		return s.runReadOnly(ctx, query, properties, respObj)
+	if s.readsFromReplicas(ctx) {
+		return s.runReadOnly(ctx, query, properties, respObj, 0)
 	}
 
 	return s.runWrite(ctx, func(tx neo4j.Transaction) (interface{}, error) {
@@ -559,33 +694,51 @@ func (s *SessionV2Impl) Query(ctx context.Context, query string, properties map[
 		}
 
 		return nil, decode(s.gogm, res, respObj)
-	})
+	}, nil)
 }
 
-func (s *SessionV2Impl) QueryRaw(ctx context.Context, query string, properties map[string]interface{}) ([][]interface{}, neo4j.ResultSummary, error) {
+func (s *SessionV2Impl) QueryRaw(ctx context.Context, query string, properties map[string]interface{}) (result [][]interface{}, summary neo4j.ResultSummary, err error) {
+	ctx, span := s.startSpan(ctx, "QueryRaw", Attr(AttributeDbStatement, query))
+	defer span.End()
+	s.traceParams(span, properties)
+	s.logQuery(ctx, query, properties)
+
+	defer func() {
+		span.RecordError(err)
+	}()
+
 	if s.neoSess == nil {
 		return nil, nil, errors.New("neo4j connection not initialized")
 	}
-	var err error
-	if s.tx != nil {
-		res, err := s.tx.Run(query, properties)
+	txSess, err := s.transactionSession(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if txSess != nil {
+		tx := s.observe(ctx, txSess.tx)
+		defer tx.flush()
+		res, err := tx.Run(query, properties)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to execute query, %w", err)
+			return nil, nil, classifyError(s.gogm, fmt.Errorf("failed to execute query, %w", err))
 		}
 
 		parsedResult := s.parseResult(res)
 
 		sum, err := res.Consume()
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, classifyError(s.gogm, err)
 		}
 
 		return parsedResult, sum, nil
 	} else {
+		defer s.trackTransaction()()
 		var ires interface{}
 		var sum neo4j.ResultSummary
-		if s.conf.AccessMode == AccessModeRead {
-			ires, err = s.neoSess.ReadTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+		if s.readsFromReplicas(ctx) {
+			ires, err = s.neoSess.ReadTransaction(func(neoTx neo4j.Transaction) (interface{}, error) {
+				tx := s.observe(ctx, neoTx)
+				defer tx.flush()
 				res, err := tx.Run(query, properties)
 				if err != nil {
 					return nil, err
@@ -601,7 +754,9 @@ func (s *SessionV2Impl) QueryRaw(ctx context.Context, query string, properties m
 				return pres, nil
 			})
 		} else {
-			ires, err = s.neoSess.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
+			ires, err = s.neoSess.WriteTransaction(func(neoTx neo4j.Transaction) (interface{}, error) {
+				tx := s.observe(ctx, neoTx)
+				defer tx.flush()
 				res, err := tx.Run(query, properties)
 				if err != nil {
 					return nil, err
@@ -618,7 +773,7 @@ func (s *SessionV2Impl) QueryRaw(ctx context.Context, query string, properties m
 			})
 		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to run auto transaction, %w", err)
+			return nil, nil, classifyError(s.gogm, fmt.Errorf("failed to run auto transaction, %w", err))
 		}
 
 		result, ok := ires.([][]interface{})
@@ -676,13 +831,8 @@ func (s *SessionV2Impl) getDeadline(ctx context.Context) time.Time {
 }
 
 func (s *SessionV2Impl) ManagedTransaction(ctx context.Context, work TransactionWork) error {
-	var span opentracing.Span
-	if ctx != nil && s.gogm.config.OpentracingEnabled {
-		span, ctx = opentracing.StartSpanFromContext(ctx, "gogm.SessionV2Impl.ManagedTransaction")
-		defer span.Finish()
-	} else {
-		span = nil
-	}
+	ctx, span := s.startSpan(ctx, "ManagedTransaction")
+	defer span.End()
 
 	if work == nil {
 		return errors.New("transaction work can not be nil")
@@ -694,30 +844,51 @@ func (s *SessionV2Impl) ManagedTransaction(ctx context.Context, work Transaction
 
 	txWork := func(tx neo4j.Transaction) (interface{}, error) {
 		s.tx = tx
+		// the driver may retry work, only keep events from the last attempt
+		s.pendingEvents.reset()
 		return nil, work(s)
 	}
 
 	defer s.clearTx()
+	defer s.trackTransaction()()
 	// handle timeout info
 	deadline := s.getDeadline(ctx)
 
+	// a retry policy replaces the driver's own retries
+	if policy := s.retryPolicy(ctx); policy != nil {
+		err := s.retryTransaction(ctx, span, policy, deadline, work)
+		if err != nil {
+			s.pendingEvents.reset()
+			return classifyError(s.gogm, fmt.Errorf("failed managed tx, %w", err))
+		}
+
+		s.updateBookmarks()
+		s.publishPendingEvents(ctx)
+
+		return nil
+	}
+
 	if s.conf.AccessMode == AccessModeWrite {
 		_, err := s.neoSess.WriteTransaction(txWork, neo4j.WithTxTimeout(time.Until(deadline)))
 		if err != nil {
-			return fmt.Errorf("failed managed write tx, %w", err)
+			s.pendingEvents.reset()
+			return classifyError(s.gogm, fmt.Errorf("failed managed write tx, %w", err))
 		}
 
-		s.lastBookmark = s.neoSess.LastBookmark()
+		s.updateBookmarks()
+		s.publishPendingEvents(ctx)
 
 		return nil
 	}
 
 	_, err := s.neoSess.ReadTransaction(txWork)
 	if err != nil {
-		return fmt.Errorf("failed managed write tx, %w", err)
+		s.pendingEvents.reset()
+		return classifyError(s.gogm, fmt.Errorf("failed managed read tx, %w", err))
 	}
 
-	s.lastBookmark = s.neoSess.LastBookmark()
+	s.updateBookmarks()
+	s.publishPendingEvents(ctx)
 
 	return nil
 }
@@ -733,13 +904,27 @@ func (s *SessionV2Impl) Close() error {
 
 	// handle tx
 	if s.tx != nil {
-		s.gogm.logger.Warn("attempting to close a session with a pending transaction. Tx is being rolled back")
+		s.log(context.Background(), LogLevelWarn, "attempting to close a session with a pending transaction. Tx is being rolled back")
+		s.pendingEvents.reset()
 		err := s.tx.Rollback()
 		if err != nil {
 			return err
 		}
 		s.tx = nil
+		s.gogm.stats.addTransactions(-1)
+	}
+
+	for db, sess := range s.databaseSessions {
+		if err := sess.Close(); err != nil {
+			return fmt.Errorf("failed to close session to database %s, %w", db, err)
+		}
+		delete(s.databaseSessions, db)
+	}
+
+	err := s.neoSess.Close()
+	if err == nil {
+		s.recordSessions(-1)
 	}
 
-	return s.neoSess.Close()
+	return err
 }
@@ -0,0 +1,118 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+//go:build go1.21
+
+// Package sloggogm adapts a log/slog logger to the gogm Logger and StructuredLogger interfaces
+package sloggogm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/mindstand/gogm/v2"
+)
+
+// Logger implements gogm.StructuredLogger on top of a *slog.Logger
+type Logger struct {
+	log *slog.Logger
+}
+
+// New wraps l, if l is nil slog.Default() is used
+func New(l *slog.Logger) *Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+
+	return &Logger{log: l}
+}
+
+func toLevel(level gogm.LogLevel) slog.Level {
+	switch level {
+	case gogm.LogLevelDebug:
+		return slog.LevelDebug
+	case gogm.LogLevelInfo:
+		return slog.LevelInfo
+	case gogm.LogLevelWarn:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+// Enabled implements gogm.StructuredLogger
+func (l *Logger) Enabled(level gogm.LogLevel) bool {
+	return l.log.Enabled(context.Background(), toLevel(level))
+}
+
+// Log implements gogm.StructuredLogger
+func (l *Logger) Log(ctx context.Context, level gogm.LogLevel, msg string, fields ...gogm.Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+
+	l.log.LogAttrs(ctx, toLevel(level), msg, attrs...)
+	if level == gogm.LogLevelFatal {
+		os.Exit(1)
+	}
+}
+
+func (l *Logger) Debug(s string) {
+	l.log.Debug(s)
+}
+
+func (l *Logger) Debugf(s string, vals ...interface{}) {
+	l.log.Debug(fmt.Sprintf(s, vals...))
+}
+
+func (l *Logger) Info(s string) {
+	l.log.Info(s)
+}
+
+func (l *Logger) Infof(s string, vals ...interface{}) {
+	l.log.Info(fmt.Sprintf(s, vals...))
+}
+
+func (l *Logger) Warn(s string) {
+	l.log.Warn(s)
+}
+
+func (l *Logger) Warnf(s string, vals ...interface{}) {
+	l.log.Warn(fmt.Sprintf(s, vals...))
+}
+
+func (l *Logger) Error(s string) {
+	l.log.Error(s)
+}
+
+func (l *Logger) Errorf(s string, vals ...interface{}) {
+	l.log.Error(fmt.Sprintf(s, vals...))
+}
+
+func (l *Logger) Fatal(s string) {
+	l.log.Error(s)
+	os.Exit(1)
+}
+
+func (l *Logger) Fatalf(s string, vals ...interface{}) {
+	l.log.Error(fmt.Sprintf(s, vals...))
+	os.Exit(1)
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultStartupRetryPolicy returns a policy that keeps retrying startup for up to two minutes, long enough for
+// neo4j to come up next to the application
+func DefaultStartupRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxElapsedTime: 2 * time.Minute,
+		Classifier:     DefaultStartupRetryClassifier,
+	}
+}
+
+// DefaultStartupRetryClassifier retries the errors DefaultRetryClassifier does as well as neo4j being unreachable
+// or its databases not being available yet
+func DefaultStartupRetryClassifier(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return DefaultRetryClassifier(err) || errors.Is(err, ErrConnection) || errors.Is(err, ErrDatabaseUnavailable)
+}
+
+// connect creates the driver, detects the server version and prepares the database. With a startup retry policy
+// each step is retried until it succeeds, the policy gives up or ctx is done
+func (g *Gogm) connect(ctx context.Context) (err error) {
+	defer func() {
+		// a later attempt starts with a new driver
+		if err != nil && g.driver != nil {
+			if closeErr := g.driver.Close(); closeErr != nil {
+				g.logger.Warnf("failed to close driver after failed startup, %v", closeErr)
+			}
+			g.driver = nil
+		}
+	}()
+
+	g.logger.Debug("establishing neo connection")
+	err = g.retryStartup(ctx, "driver", g.initDriver)
+	if err != nil {
+		return fmt.Errorf("failed to initialize driver, %w", err)
+	}
+
+	g.logger.Debug("initializing indices")
+	err = g.retryStartup(ctx, "indices", g.initIndex)
+	if err != nil {
+		return fmt.Errorf("failed to init indices, %w", err)
+	}
+
+	if g.config.EnableQueryValidation {
+		g.logger.Debug("validating generated queries")
+		err = g.initQueryValidation(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to validate queries, %w", err)
+		}
+	}
+
+	g.connected = true
+	return nil
+}
+
+// ensureConnected connects a LazyConnect instance the first time it is used. A failed attempt is tried again on the next use
+func (g *Gogm) ensureConnected(ctx context.Context) error {
+	if g.config == nil || !g.config.LazyConnect {
+		if g.driver == nil {
+			return errors.New("gogm driver not initialized")
+		}
+		return nil
+	}
+
+	g.connectMu.Lock()
+	defer g.connectMu.Unlock()
+
+	if g.connected {
+		return nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if g.origin != nil {
+		err := g.origin.ensureConnected(ctx)
+		if err != nil {
+			return err
+		}
+
+		g.origin.connectMu.Lock()
+		defer g.origin.connectMu.Unlock()
+		g.driver = g.origin.driver
+		g.boltMajorVersion = g.origin.boltMajorVersion
+		g.defaultDatabase = g.origin.defaultDatabase
+		g.queryValidation = g.origin.queryValidation
+		g.connected = true
+		return nil
+	}
+
+	g.logger.Debug("connecting lazily on first use")
+	err := g.connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect on first use, %w", err)
+	}
+
+	return nil
+}
+
+// retryStartup runs step until it succeeds or Config.StartupRetry gives up. Waits never go past the deadline of ctx
+func (g *Gogm) retryStartup(ctx context.Context, step string, fn func(ctx context.Context) error) error {
+	policy := g.config.StartupRetry
+	if policy == nil {
+		return fn(ctx)
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			if attempt > 1 {
+				g.log(ctx, LogLevelInfo, "startup step succeeded after retrying", LogField("step", step), LogField("attempt", attempt),
+					LogField("elapsed", time.Since(start).String()))
+			}
+			return nil
+		}
+
+		if !policy.shouldRetry(err, attempt, time.Since(start)) {
+			if attempt > 1 {
+				return fmt.Errorf("giving up on %s after %d attempts, %w", step, attempt, err)
+			}
+			return err
+		}
+
+		wait := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return fmt.Errorf("giving up on %s after %d attempts, next attempt would be after the context deadline, %w", step, attempt, err)
+		}
+
+		g.log(ctx, LogLevelWarn, "startup step failed, retrying", LogField("step", step), LogField("attempt", attempt),
+			LogField("backoff", wait.String()), LogField(LogFieldError, err))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("context done while waiting to retry %s (%v), %w", step, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/db"
+	"github.com/stretchr/testify/require"
+)
+
+// connectivityError returns the error the driver gives when nothing listens where it connects to
+func connectivityError(t *testing.T) error {
+	driver, err := neo4j.NewDriver("bolt://127.0.0.1:1", neo4j.NoAuth())
+	require.Nil(t, err)
+	defer driver.Close()
+
+	var connErr *neo4j.ConnectivityError
+	require.True(t, errors.As(driver.VerifyConnectivity(), &connErr))
+	return connErr
+}
+
+func TestGogm_RetryStartup(t *testing.T) {
+	req := require.New(t)
+
+	g := &Gogm{
+		config: &Config{
+			Logger: GetDefaultLogger(),
+			StartupRetry: &RetryPolicy{
+				MaxAttempts:    5,
+				InitialBackoff: time.Millisecond,
+				Classifier:     DefaultStartupRetryClassifier,
+			},
+		},
+		logger: GetDefaultLogger(),
+	}
+	ctx := context.Background()
+	unavailable := &DatabaseError{Kind: ErrConnection, Err: connectivityError(t)}
+
+	// retryable errors are retried until the step succeeds
+	attempts := 0
+	err := g.retryStartup(ctx, "driver", func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return unavailable
+		}
+		return nil
+	})
+	req.Nil(err)
+	req.Equal(3, attempts)
+
+	// others fail right away
+	attempts = 0
+	err = g.retryStartup(ctx, "driver", func(ctx context.Context) error {
+		attempts++
+		return errors.New("invalid uri")
+	})
+	req.NotNil(err)
+	req.Equal(1, attempts)
+
+	// until the policy gives up
+	attempts = 0
+	err = g.retryStartup(ctx, "indices", func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	})
+	req.True(errors.Is(err, ErrConnection))
+	req.Equal(5, attempts)
+
+	// waits never go past the context deadline
+	g.config.StartupRetry.InitialBackoff = time.Hour
+	deadlineCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	attempts = 0
+	start := time.Now()
+	err = g.retryStartup(deadlineCtx, "driver", func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	})
+	req.NotNil(err)
+	req.Equal(1, attempts)
+	req.Less(time.Since(start), time.Second)
+
+	// without a policy the step runs once
+	g.config.StartupRetry = nil
+	attempts = 0
+	err = g.retryStartup(ctx, "driver", func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	})
+	req.NotNil(err)
+	req.Equal(1, attempts)
+}
+
+func TestDefaultStartupRetryClassifier(t *testing.T) {
+	req := require.New(t)
+
+	req.False(DefaultStartupRetryClassifier(nil))
+	req.False(DefaultStartupRetryClassifier(errors.New("invalid uri")))
+	req.True(DefaultStartupRetryClassifier(connectivityError(t)))
+	req.True(DefaultStartupRetryClassifier(&DatabaseError{Kind: ErrDatabaseUnavailable, Err: errors.New("starting")}))
+	req.True(DefaultStartupRetryClassifier(&neo4j.Neo4jError{Code: "Neo.TransientError.General.DatabaseUnavailable"}))
+	req.False(DefaultStartupRetryClassifier(&neo4j.Neo4jError{Code: "Neo.ClientError.Security.Unauthorized"}))
+}
+
+// versionDriver answers the test query of probeDriver and counts the sessions opened and closed through it
+type versionDriver struct {
+	neo4j.Driver
+	connErr        error
+	runErr         error
+	opened, closed int
+}
+
+func (v *versionDriver) VerifyConnectivity() error {
+	return v.connErr
+}
+
+func (v *versionDriver) NewSession(neo4j.SessionConfig) neo4j.Session {
+	v.opened++
+	return &versionSession{driver: v}
+}
+
+type versionSession struct {
+	neo4j.Session
+	driver *versionDriver
+}
+
+func (v *versionDriver) Close() error {
+	return nil
+}
+
+func (v *versionSession) Run(string, map[string]interface{}, ...func(*neo4j.TransactionConfig)) (neo4j.Result, error) {
+	if v.driver.runErr != nil {
+		return nil, v.driver.runErr
+	}
+	return &versionResult{}, nil
+}
+
+func (v *versionSession) Close() error {
+	v.driver.closed++
+	return nil
+}
+
+// versionResult reports a neo4j 4 server with the default database neo4j
+type versionResult struct {
+	neo4j.Result
+	neo4j.ResultSummary
+	neo4j.ServerInfo
+}
+
+func (v *versionResult) Err() error                            { return nil }
+func (v *versionResult) Consume() (neo4j.ResultSummary, error) { return v, nil }
+func (v *versionResult) Server() neo4j.ServerInfo              { return v }
+func (v *versionResult) Database() neo4j.DatabaseInfo          { return v }
+func (v *versionResult) Name() string                          { return "neo4j" }
+
+func (v *versionResult) ProtocolVersion() db.ProtocolVersion {
+	return db.ProtocolVersion{Major: 4, Minor: 4}
+}
+
+func TestProbeDriver(t *testing.T) {
+	req := require.New(t)
+
+	g := &Gogm{config: &Config{}, logger: GetDefaultLogger()}
+
+	driver := &versionDriver{}
+	version, defaultDatabase, err := probeDriver(g, driver)
+	req.Nil(err)
+	req.Equal(4, version)
+	req.Equal("neo4j", defaultDatabase)
+	req.Equal(1, driver.closed)
+
+	// the session is closed when the test query fails
+	driver = &versionDriver{runErr: &neo4j.Neo4jError{Code: "Neo.ClientError.Database.DatabaseNotFound", Msg: "database not found"}}
+	_, _, err = probeDriver(g, driver)
+	req.NotNil(err)
+	req.Equal(1, driver.closed)
+
+	driver = &versionDriver{connErr: connectivityError(t)}
+	_, _, err = probeDriver(g, driver)
+	req.True(errors.Is(err, ErrConnection))
+	req.Zero(driver.opened)
+}
+
+func TestLazyConnect(t *testing.T) {
+	req := require.New(t)
+
+	// nothing listens on port 1, so connecting fails on first use
+	g, err := New(&Config{
+		Host:          "127.0.0.1",
+		Port:          1,
+		Protocol:      "bolt",
+		IndexStrategy: IGNORE_INDEX,
+		LazyConnect:   true,
+	}, UUIDPrimaryKeyStrategy, &a{}, &b{}, &c{})
+	req.Nil(err)
+	req.NotNil(g)
+	req.Nil(g.driver)
+
+	_, err = g.NewSessionV2(SessionConfig{AccessMode: AccessModeRead})
+	req.NotNil(err)
+	req.Nil(g.driver)
+	req.False(g.connected)
+
+	// it is tried again on the next use
+	req.NotNil(g.Ping(context.Background()))
+
+	// sessions opened internally connect as well
+	_, err = newSessionWithConfigV2(g, SessionConfig{AccessMode: AccessModeRead})
+	req.ErrorContains(err, "failed to connect on first use")
+
+	// copies connect through the instance they were copied from and share its driver
+	copied := g.Copy()
+	req.Same(g, copied.origin)
+	req.Same(g, copied.Copy().origin)
+
+	driver := &versionDriver{}
+	g.driver = driver
+	g.boltMajorVersion = 4
+	g.connected = true
+	sess, err := newSessionWithConfigV2(copied, SessionConfig{AccessMode: AccessModeRead})
+	req.Nil(err)
+	req.Same(driver, copied.driver)
+	req.True(copied.connected)
+	req.Equal(1, driver.opened)
+	req.Nil(sess.Close())
+
+	// a connected instance is copied with its driver
+	req.Nil(g.Copy().origin)
+	req.Nil(g.Close())
+}
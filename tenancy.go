@@ -0,0 +1,284 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	dsl "github.com/mindstand/go-cypherdsl"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const (
+	// defaultTenantProperty is the property tenants are stored in when Config.TenantProperty is not set
+	defaultTenantProperty = "tenant_id"
+	// defaultTenantLabelPrefix is put in front of the tenant id to make the tenant label when Config.TenantLabelPrefix is not set
+	defaultTenantLabelPrefix = "Tenant_"
+	// tenantParam is the query parameter holding the tenant id
+	tenantParam = "gogm_tenant"
+	// tenantPathVariable is the variable used to check every node of a path
+	tenantPathVariable = "gogm_tenant_node"
+)
+
+// TenancyMode defines how gogm keeps the data of tenants apart
+type TenancyMode int
+
+const (
+	// TenancyNone does not scope queries to a tenant
+	TenancyNone TenancyMode = iota
+	// TenancyProperty stores the tenant id in a property on every node, see Config.TenantProperty
+	TenancyProperty
+	// TenancyLabel adds a label made from the tenant id to every node, see Config.TenantLabelPrefix
+	TenancyLabel
+)
+
+// tenancyModeNames is indexed by TenancyMode
+var tenancyModeNames = []string{"NONE", "PROPERTY", "LABEL"}
+
+func (t TenancyMode) validate() error {
+	switch t {
+	case TenancyNone, TenancyProperty, TenancyLabel:
+		return nil
+	default:
+		return fmt.Errorf("invalid tenancy mode %d", t)
+	}
+}
+
+// String returns the name of the mode, such as PROPERTY
+func (t TenancyMode) String() string {
+	if t.validate() == nil {
+		return tenancyModeNames[t]
+	}
+	return fmt.Sprintf("TenancyMode(%d)", int(t))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t TenancyMode) MarshalText() ([]byte, error) {
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts the name of the mode or its number
+func (t *TenancyMode) UnmarshalText(text []byte) error {
+	value, err := parseStrategy(string(text), "tenancy", tenancyModeNames)
+	if err != nil {
+		return err
+	}
+	*t = TenancyMode(value)
+	return nil
+}
+
+// UnmarshalJSON accepts the name of the mode or its number
+func (t *TenancyMode) UnmarshalJSON(data []byte) error {
+	return unmarshalStrategyJSON(data, t.UnmarshalText)
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx that scopes every gogm call made with it to tenant when Config.TenancyMode is set
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant
+func TenantFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// tenantScope limits the nodes a query can see and write to those of one tenant. A nil scope does not limit anything
+type tenantScope struct {
+	mode     TenancyMode
+	tenant   string
+	property string
+	label    string
+}
+
+// tenantScope returns the scope for the tenant in ctx, or nil when tenancy is off
+func (g *Gogm) tenantScope(ctx context.Context) (*tenantScope, error) {
+	if g.config == nil || g.config.TenancyMode == TenancyNone {
+		return nil, nil
+	}
+
+	tenant, ok := TenantFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("tenancy is enabled but the context has no tenant, use WithTenant: %w", ErrTenant)
+	}
+
+	return g.scopeOf(tenant), nil
+}
+
+// scopeOf returns the scope of tenant. Tenancy has to be on
+func (g *Gogm) scopeOf(tenant string) *tenantScope {
+	return &tenantScope{
+		mode:     g.config.TenancyMode,
+		tenant:   tenant,
+		property: g.config.TenantProperty,
+		label:    quoteName(g.config.TenantLabelPrefix + tenant),
+	}
+}
+
+// checkTenancyV1 rejects the deprecated session, which has no context to carry the tenant
+func (g *Gogm) checkTenancyV1() error {
+	if g.config != nil && g.config.TenancyMode != TenancyNone {
+		return fmt.Errorf("tenancy mode %s requires SessionV2: %w", g.config.TenancyMode, ErrTenant)
+	}
+
+	return nil
+}
+
+// quoteName quotes a label or property name so it can hold any character
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// predicate is the cypher condition that variable belongs to the tenant
+func (t *tenantScope) predicate(variable string) string {
+	if t.mode == TenancyLabel {
+		return fmt.Sprintf("%s:%s", variable, t.label)
+	}
+
+	return fmt.Sprintf("%s.%s = $%s", variable, quoteName(t.property), tenantParam)
+}
+
+// filter scopes variable to the tenant on top of filter. When path is set every node of the path p has to
+// belong to the tenant as well
+func (t *tenantScope) filter(variable string, filter dsl.ConditionOperator, path bool) dsl.ConditionOperator {
+	if t == nil {
+		return filter
+	}
+
+	var scoped dsl.ConditionOperator
+	if t.mode == TenancyLabel {
+		scoped = dsl.C(&dsl.ConditionConfig{Name: variable, Label: t.label})
+	} else {
+		scoped = dsl.C(&dsl.ConditionConfig{
+			Name:              variable,
+			Field:             quoteName(t.property),
+			ConditionOperator: dsl.EqualToOperator,
+			Check:             dsl.ParamString("$" + tenantParam),
+		})
+	}
+
+	if path {
+		scoped = scoped.AndNested(dsl.WhereQuery(fmt.Sprintf("all(%s IN nodes(p) WHERE %s)", tenantPathVariable, t.predicate(tenantPathVariable))), nil)
+	}
+
+	if filter != nil {
+		scoped = scoped.AndNested(filter.Build())
+	}
+
+	return scoped
+}
+
+// comprehensionFilter is the WHERE clause of a list comprehension that only matches variable when it belongs to the tenant
+func (t *tenantScope) comprehensionFilter(variable string) string {
+	if t == nil {
+		return ""
+	}
+
+	return " WHERE " + t.predicate(variable)
+}
+
+// shape identifies the queries scope generates. The tenant is left out, it is a param in property mode and the
+// query cache swaps the label in label mode
+func (t *tenantScope) shape() string {
+	if t == nil {
+		return ""
+	}
+
+	if t.mode == TenancyLabel {
+		return t.mode.String()
+	}
+
+	return t.mode.String() + ":" + t.property
+}
+
+// params adds the tenant to params, creating the map when needed
+func (t *tenantScope) params(params map[string]interface{}) map[string]interface{} {
+	if t == nil || t.mode != TenancyProperty {
+		return params
+	}
+
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params[tenantParam] = t.tenant
+	return params
+}
+
+// labels is appended to the label of nodes that are created or updated
+func (t *tenantScope) labels() string {
+	if t == nil || t.mode != TenancyLabel {
+		return ""
+	}
+
+	return ":" + t.label
+}
+
+// stamp writes the tenant into the properties of a node being saved
+func (t *tenantScope) stamp(props map[string]interface{}) {
+	if t == nil || t.mode != TenancyProperty {
+		return
+	}
+
+	props[t.property] = t.tenant
+}
+
+// checkNodes returns an error if any of the nodes belongs to another tenant
+func (t *tenantScope) checkNodes(tx neo4j.Transaction, ids []int64) error {
+	if t == nil || len(ids) == 0 {
+		return nil
+	}
+
+	cyp := fmt.Sprintf("MATCH (n) WHERE ID(n) IN $ids AND NOT coalesce(%s, false) RETURN ID(n)", t.predicate("n"))
+	res, err := tx.Run(cyp, t.params(map[string]interface{}{"ids": ids}))
+	if err != nil {
+		return fmt.Errorf("failed to check tenant of nodes, %w", err)
+	}
+
+	var foreign []int64
+	for res.Next() {
+		if id, ok := res.Record().Values[0].(int64); ok {
+			foreign = append(foreign, id)
+		}
+	}
+
+	if err = res.Err(); err != nil {
+		return fmt.Errorf("failed to check tenant of nodes, %w", err)
+	}
+
+	if len(foreign) != 0 {
+		return fmt.Errorf("nodes %v do not belong to tenant %s: %w", foreign, t.tenant, ErrTenant)
+	}
+
+	return nil
+}
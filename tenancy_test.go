@@ -0,0 +1,176 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package gogm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/stretchr/testify/require"
+)
+
+// tenantTx answers every query with the ids in foreign
+type tenantTx struct {
+	neo4j.Transaction
+	statements []string
+	foreign    []int64
+}
+
+func (t *tenantTx) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	t.statements = append(t.statements, cypher)
+	var records []*neo4j.Record
+	for _, id := range t.foreign {
+		records = append(records, &neo4j.Record{Keys: []string{"ID(n)"}, Values: []interface{}{id}})
+	}
+	return &recordsResult{records: records}, nil
+}
+
+func getTenantGogm(mode TenancyMode, strategy LoadStrategy) (*Gogm, error) {
+	g, err := getTestGogmWithDefaultStructs()
+	if err != nil {
+		return nil, err
+	}
+
+	g.config.TenancyMode = mode
+	g.config.TenantProperty = defaultTenantProperty
+	g.config.TenantLabelPrefix = defaultTenantLabelPrefix
+	g.config.LoadStrategy = strategy
+	return g, nil
+}
+
+func TestTenantScope_Load(t *testing.T) {
+	req := require.New(t)
+	ctx := WithTenant(context.Background(), "acme")
+
+	g, err := getTenantGogm(TenancyProperty, PATH_LOAD_STRATEGY)
+	req.Nil(err)
+	sess := &SessionV2Impl{gogm: g}
+	scope, err := g.tenantScope(ctx)
+	req.Nil(err)
+
+	cyp, params, err := sess.loadQuery(scope, nil, &a{}, "uuid", 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() WHERE (n.`tenant_id` = $gogm_tenant AND (all(gogm_tenant_node IN nodes(p) WHERE gogm_tenant_node.`tenant_id` = $gogm_tenant))) AND n.uuid = $idprm RETURN p", cyp)
+	req.Equal(map[string]interface{}{"idprm": "uuid", tenantParam: "acme"}, params)
+
+	cyp, params, err = sess.loadAllQuery(scope, nil, &[]a{}, 1, nil, nil, nil)
+	req.Nil(err)
+	req.Equal("MATCH p=(n)-[*0..1]-() WHERE n.`tenant_id` = $gogm_tenant AND (all(gogm_tenant_node IN nodes(p) WHERE gogm_tenant_node.`tenant_id` = $gogm_tenant)) RETURN p", cyp)
+	req.Equal(map[string]interface{}{tenantParam: "acme"}, params)
+
+	g, err = getTenantGogm(TenancyLabel, SCHEMA_LOAD_STRATEGY)
+	req.Nil(err)
+	sess = &SessionV2Impl{gogm: g}
+	scope, err = g.tenantScope(ctx)
+	req.Nil(err)
+
+	cyp, params, err = sess.loadQuery(scope, nil, &a{}, "uuid", 1, nil, nil, nil)
+	req.Nil(err)
+	req.Contains(cyp, "MATCH (n:a) WHERE (n:`Tenant_acme`) AND n.uuid = $idprm RETURN n")
+	req.Contains(cyp, "[(n)<-[r_t_1:test_rel]-(n_b_1:b) WHERE n_b_1:`Tenant_acme` | [r_t_1, n_b_1]]")
+	req.Equal(map[string]interface{}{"idprm": "uuid"}, params)
+
+	// no tenant in the context
+	_, err = g.tenantScope(context.Background())
+	req.True(errors.Is(err, ErrTenant))
+	err = (&SessionV2Impl{gogm: g, neoSess: &fakeNeoSession{}}).LoadDepth(context.Background(), &a{}, "uuid", 1)
+	req.True(errors.Is(err, ErrTenant))
+
+	// tenancy off
+	g.config.TenancyMode = TenancyNone
+	scope, err = g.tenantScope(context.Background())
+	req.Nil(err)
+	req.Nil(scope)
+}
+
+func TestTenantScope_Save(t *testing.T) {
+	req := require.New(t)
+	ctx := WithTenant(context.Background(), "acme")
+
+	g, err := getTenantGogm(TenancyProperty, PATH_LOAD_STRATEGY)
+	req.Nil(err)
+
+	plan, err := g.PlanSave(ctx, &a{TestField: "new"}, 0)
+	req.Nil(err)
+	req.Len(plan, 1)
+	row := plan[0].Params["rows"].([]interface{})[0].(map[string]interface{})
+	req.Equal("acme", row["obj"].(map[string]interface{})[defaultTenantProperty])
+
+	_, err = g.PlanSave(context.Background(), &a{TestField: "new"}, 0)
+	req.True(errors.Is(err, ErrTenant))
+
+	g, err = getTenantGogm(TenancyLabel, PATH_LOAD_STRATEGY)
+	req.Nil(err)
+
+	plan, err = g.PlanSave(ctx, &a{TestField: "new"}, 0)
+	req.Nil(err)
+	req.Len(plan, 1)
+	req.Contains(plan[0].Cypher, "CREATE(n:`a`:`Tenant_acme`)")
+	row = plan[0].Params["rows"].([]interface{})[0].(map[string]interface{})
+	req.NotContains(row["obj"], defaultTenantProperty)
+}
+
+func TestTenantScope_CheckNodes(t *testing.T) {
+	req := require.New(t)
+
+	scope := &tenantScope{mode: TenancyProperty, tenant: "acme", property: defaultTenantProperty}
+
+	tx := &tenantTx{}
+	req.Nil(scope.checkNodes(tx, []int64{1, 2}))
+	req.Equal([]string{"MATCH (n) WHERE ID(n) IN $ids AND NOT coalesce(n.`tenant_id` = $gogm_tenant, false) RETURN ID(n)"}, tx.statements)
+
+	tx = &tenantTx{foreign: []int64{2}}
+	err := scope.checkNodes(tx, []int64{1, 2})
+	req.True(errors.Is(err, ErrTenant))
+
+	// nothing to check
+	tx = &tenantTx{foreign: []int64{2}}
+	req.Nil(scope.checkNodes(tx, nil))
+	req.Nil((*tenantScope)(nil).checkNodes(tx, []int64{2}))
+	req.Empty(tx.statements)
+
+	// deleting a node of another tenant
+	id := int64(2)
+	work, err := deleteNode(scope, &a{BaseUUIDNode: BaseUUIDNode{BaseNode: BaseNode{Id: &id}}}, nil)
+	req.Nil(err)
+	tx = &tenantTx{foreign: []int64{2}}
+	_, err = work(tx)
+	req.True(errors.Is(err, ErrTenant))
+	req.Len(tx.statements, 1)
+}
+
+func TestTenancyMode_Text(t *testing.T) {
+	req := require.New(t)
+
+	var mode TenancyMode
+	req.Nil(mode.UnmarshalText([]byte("label")))
+	req.Equal(TenancyLabel, mode)
+	req.Nil(mode.UnmarshalJSON([]byte("1")))
+	req.Equal(TenancyProperty, mode)
+	req.NotNil(mode.UnmarshalText([]byte("tenant")))
+
+	text, err := TenancyProperty.MarshalText()
+	req.Nil(err)
+	req.Equal("PROPERTY", string(text))
+
+	_, err = TenancyMode(7).MarshalText()
+	req.NotNil(err)
+}
@@ -0,0 +1,180 @@
+// Code generated by GoGM 2.1.1. DO NOT EDIT
+package testing_
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GogmEncode returns the properties of CodecObject without reflection, see gogm.NodeEncoder
+func (n *CodecObject) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{
+		"name":    n.Name,
+		"count":   n.Count,
+		"score":   n.Score,
+		"active":  n.Active,
+		"created": n.Created,
+		"tags":    n.Tags,
+		"sizes":   n.Sizes,
+	}
+
+	for key, v := range n.Attrs {
+		props["attrs."+key] = v
+	}
+
+	for key, v := range n.Extra {
+		props["extra."+key] = v
+	}
+
+	return props
+}
+
+// GogmDecode reads the properties of CodecObject without reflection, see gogm.NodeDecoder
+func (n *CodecObject) GogmDecode(props map[string]interface{}) error {
+	if raw, ok := props["name"]; ok && raw != nil {
+		v, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("property name of CodecObject holds %T, not string", raw)
+		}
+		n.Name = v
+	}
+
+	if raw, ok := props["count"]; ok && raw != nil {
+		v, ok := raw.(int64)
+		if !ok {
+			return fmt.Errorf("property count of CodecObject holds %T, not int64", raw)
+		}
+		n.Count = int(v)
+	}
+
+	if raw, ok := props["score"]; ok && raw != nil {
+		v, ok := raw.(float64)
+		if !ok {
+			return fmt.Errorf("property score of CodecObject holds %T, not float64", raw)
+		}
+		n.Score = float32(v)
+	}
+
+	if raw, ok := props["active"]; ok && raw != nil {
+		v, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("property active of CodecObject holds %T, not bool", raw)
+		}
+		n.Active = v
+	}
+
+	if raw, ok := props["created"]; ok && raw != nil {
+		v, ok := raw.(time.Time)
+		if !ok {
+			return fmt.Errorf("property created of CodecObject holds %T, not time.Time", raw)
+		}
+		n.Created = v
+	}
+
+	if raw, ok := props["tags"]; ok && raw != nil {
+		switch list := raw.(type) {
+		case []string:
+			n.Tags = list
+		case []interface{}:
+			n.Tags = make([]string, len(list))
+			for i, elem := range list {
+				v, ok := elem.(string)
+				if !ok {
+					return fmt.Errorf("property tags of CodecObject holds %T, not string", elem)
+				}
+				n.Tags[i] = v
+			}
+		default:
+			return fmt.Errorf("property tags of CodecObject holds %T, not a list", raw)
+		}
+	}
+
+	if raw, ok := props["sizes"]; ok && raw != nil {
+		switch list := raw.(type) {
+		case []int:
+			n.Sizes = list
+		case []interface{}:
+			n.Sizes = make([]int, len(list))
+			for i, elem := range list {
+				v, ok := elem.(int64)
+				if !ok {
+					return fmt.Errorf("property sizes of CodecObject holds %T, not int64", elem)
+				}
+				n.Sizes[i] = int(v)
+			}
+		default:
+			return fmt.Errorf("property sizes of CodecObject holds %T, not a list", raw)
+		}
+	}
+
+	n.Attrs = map[string]string{}
+	for key, raw := range props {
+		if !strings.HasPrefix(key, "attrs.") {
+			continue
+		}
+
+		v, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("property %s of CodecObject holds %T, not string", key, raw)
+		}
+		n.Attrs[strings.TrimPrefix(key, "attrs.")] = v
+	}
+
+	n.Extra = map[string]interface{}{}
+	for key, raw := range props {
+		if !strings.HasPrefix(key, "extra.") {
+			continue
+		}
+
+		n.Extra[strings.TrimPrefix(key, "extra.")] = raw
+	}
+
+	return nil
+}
+
+// GogmEncode returns the properties of ExampleObject without reflection, see gogm.NodeEncoder
+func (n *ExampleObject) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{}
+
+	return props
+}
+
+// GogmDecode reads the properties of ExampleObject without reflection, see gogm.NodeDecoder
+func (n *ExampleObject) GogmDecode(props map[string]interface{}) error {
+	return nil
+}
+
+// GogmEncode returns the properties of ExampleObject2 without reflection, see gogm.NodeEncoder
+func (n *ExampleObject2) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{}
+
+	return props
+}
+
+// GogmDecode reads the properties of ExampleObject2 without reflection, see gogm.NodeDecoder
+func (n *ExampleObject2) GogmDecode(props map[string]interface{}) error {
+	return nil
+}
+
+// GogmEncode returns the properties of SpecialEdge without reflection, see gogm.NodeEncoder
+func (n *SpecialEdge) GogmEncode() map[string]interface{} {
+	props := map[string]interface{}{
+		"some_field": n.SomeField,
+	}
+
+	return props
+}
+
+// GogmDecode reads the properties of SpecialEdge without reflection, see gogm.NodeDecoder
+func (n *SpecialEdge) GogmDecode(props map[string]interface{}) error {
+	if raw, ok := props["some_field"]; ok && raw != nil {
+		v, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("property some_field of SpecialEdge holds %T, not string", raw)
+		}
+		n.SomeField = v
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package testing_
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mindstand/gogm/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// the generated codecs satisfy the interfaces gogm looks for
+var (
+	_ gogm.NodeEncoder = &CodecObject{}
+	_ gogm.NodeDecoder = &CodecObject{}
+	_ gogm.NodeDecoder = &SpecialEdge{}
+)
+
+func TestCodecs(t *testing.T) {
+	req := require.New(t)
+
+	created := time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	obj := &CodecObject{
+		BaseUUIDNode: gogm.BaseUUIDNode{UUID: "uuid"},
+		Name:         "name",
+		Count:        3,
+		Score:        1.5,
+		Active:       true,
+		Created:      created,
+		Tags:         []string{"a", "b"},
+		Sizes:        []int{1, 2},
+		Attrs:        map[string]string{"k": "v"},
+		Extra:        map[string]interface{}{"n": int64(1)},
+		Skipped:      "skipped",
+	}
+
+	props := obj.GogmEncode()
+	req.Equal(map[string]interface{}{
+		"name":    "name",
+		"count":   3,
+		"score":   float32(1.5),
+		"active":  true,
+		"created": created,
+		"tags":    []string{"a", "b"},
+		"sizes":   []int{1, 2},
+		"attrs.k": "v",
+		"extra.n": int64(1),
+	}, props)
+
+	// the driver returns lists as []interface{} and numbers as int64 and float64
+	var decoded CodecObject
+	req.Nil(decoded.GogmDecode(map[string]interface{}{
+		"name":    "name",
+		"count":   int64(3),
+		"score":   float64(1.5),
+		"active":  true,
+		"created": created,
+		"tags":    []interface{}{"a", "b"},
+		"sizes":   []interface{}{int64(1), int64(2)},
+		"attrs.k": "v",
+		"extra.n": int64(1),
+		"uuid":    "uuid",
+	}))
+	obj.UUID = ""
+	obj.Skipped = ""
+	req.Equal(*obj, decoded)
+
+	req.NotNil(decoded.GogmDecode(map[string]interface{}{"count": "3"}))
+	req.NotNil(decoded.GogmDecode(map[string]interface{}{"tags": []interface{}{1}}))
+	req.NotNil(decoded.GogmDecode(map[string]interface{}{"attrs.k": 1}))
+}
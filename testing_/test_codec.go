@@ -0,0 +1,41 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package testing_
+
+import (
+	"time"
+
+	"github.com/mindstand/gogm/v2"
+)
+
+type CodecObject struct {
+	gogm.BaseUUIDNode
+
+	Name    string                 `gogm:"name=name"`
+	Count   int                    `gogm:"name=count"`
+	Score   float32                `gogm:"name=score"`
+	Active  bool                   `gogm:"name=active"`
+	Created time.Time              `gogm:"name=created"`
+	Tags    []string               `gogm:"name=tags;properties"`
+	Sizes   []int                  `gogm:"name=sizes;properties"`
+	Attrs   map[string]string      `gogm:"name=attrs;properties"`
+	Extra   map[string]interface{} `gogm:"name=extra;properties"`
+	Skipped string                 `gogm:"-"`
+}
@@ -138,11 +138,26 @@ func toCypherParamsMap(gogm *Gogm, val reflect.Value, config structDecoratorConf
 
 	ret := map[string]interface{}{}
 
+	// generated encoders write everything but the primary key
+	encoded := false
+	if val.CanAddr() {
+		if encoder, ok := val.Addr().Interface().(NodeEncoder); ok {
+			if props := encoder.GogmEncode(); props != nil {
+				ret = props
+			}
+			encoded = true
+		}
+	}
+
 	for _, conf := range config.Fields {
 		if conf.Relationship != "" || conf.Name == "id" || conf.Ignore {
 			continue
 		}
 
+		if encoded && conf.PrimaryKey == "" {
+			continue
+		}
+
 		field := val.FieldByName(conf.FieldName)
 
 		if conf.Properties {
@@ -0,0 +1,28 @@
+module github.com/mindstand/gogm/v2/zapgogm
+
+go 1.18
+
+require (
+	github.com/mindstand/gogm/v2 v2.0.0
+	github.com/stretchr/testify v1.8.2
+	go.uber.org/zap v1.23.0
+)
+
+require (
+	github.com/adam-hanna/arrayOperations v0.2.6 // indirect
+	github.com/cornelk/hashmap v1.0.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dchest/siphash v1.2.1 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/mindstand/go-cypherdsl v0.2.0 // indirect
+	github.com/neo4j/neo4j-go-driver/v5 v5.0.0 // indirect
+	github.com/opentracing/opentracing-go v1.2.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/sirupsen/logrus v1.9.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/multierr v1.6.0 // indirect
+	golang.org/x/sys v0.5.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/mindstand/gogm/v2 => ../
@@ -0,0 +1,121 @@
+// Copyright (c) 2022 MindStand Technologies, Inc
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+// Package zapgogm adapts a zap logger to the gogm Logger and StructuredLogger interfaces
+package zapgogm
+
+import (
+	"context"
+
+	"github.com/mindstand/gogm/v2"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Logger implements gogm.StructuredLogger on top of a *zap.Logger
+type Logger struct {
+	log   *zap.Logger
+	sugar *zap.SugaredLogger
+}
+
+// New wraps l, if l is nil zap.L() is used
+func New(l *zap.Logger) *Logger {
+	if l == nil {
+		l = zap.L()
+	}
+
+	return &Logger{
+		log:   l,
+		sugar: l.Sugar(),
+	}
+}
+
+func toLevel(level gogm.LogLevel) zapcore.Level {
+	switch level {
+	case gogm.LogLevelDebug:
+		return zapcore.DebugLevel
+	case gogm.LogLevelInfo:
+		return zapcore.InfoLevel
+	case gogm.LogLevelWarn:
+		return zapcore.WarnLevel
+	case gogm.LogLevelError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
+
+// Enabled implements gogm.StructuredLogger
+func (l *Logger) Enabled(level gogm.LogLevel) bool {
+	return l.log.Core().Enabled(toLevel(level))
+}
+
+// Log implements gogm.StructuredLogger
+func (l *Logger) Log(_ context.Context, level gogm.LogLevel, msg string, fields ...gogm.Field) {
+	ce := l.log.Check(toLevel(level), msg)
+	if ce == nil {
+		return
+	}
+
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		zapFields = append(zapFields, zap.Any(field.Key, field.Value))
+	}
+
+	ce.Write(zapFields...)
+}
+
+func (l *Logger) Debug(s string) {
+	l.log.Debug(s)
+}
+
+func (l *Logger) Debugf(s string, vals ...interface{}) {
+	l.sugar.Debugf(s, vals...)
+}
+
+func (l *Logger) Info(s string) {
+	l.log.Info(s)
+}
+
+func (l *Logger) Infof(s string, vals ...interface{}) {
+	l.sugar.Infof(s, vals...)
+}
+
+func (l *Logger) Warn(s string) {
+	l.log.Warn(s)
+}
+
+func (l *Logger) Warnf(s string, vals ...interface{}) {
+	l.sugar.Warnf(s, vals...)
+}
+
+func (l *Logger) Error(s string) {
+	l.log.Error(s)
+}
+
+func (l *Logger) Errorf(s string, vals ...interface{}) {
+	l.sugar.Errorf(s, vals...)
+}
+
+func (l *Logger) Fatal(s string) {
+	l.log.Fatal(s)
+}
+
+func (l *Logger) Fatalf(s string, vals ...interface{}) {
+	l.sugar.Fatalf(s, vals...)
+}